@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+)
+
+// junitTestSuites is the root of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the test cases of one rule.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a violation, or a passing case for a rule without violations.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitFailure marks a violation failing the quality gate.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped marks a violation below the failing severity, or a disabled rule.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnit writes report as JUnit XML with a test suite per rule and a test case per violation.
+// Violations below failOn are reported as skipped so they are visible without failing the build.
+func writeJUnit(out io.Writer, report model.ViolationReport, failOn string) error {
+	suites := junitTestSuites{Name: "codevis"}
+
+	for _, rule := range report.Rules {
+		suite := junitTestSuite{Name: rule.ID}
+
+		for _, violation := range report.Violations {
+			if violation.Rule != rule.ID {
+				continue
+			}
+
+			testCase := junitTestCase{
+				Name:      violation.QualifiedName,
+				ClassName: violation.FileName + ":" + strconv.Itoa(violation.StartLine),
+			}
+
+			if failOn != failNever && model.SeverityAtLeast(violation.Severity, failOn) {
+				testCase.Failure = &junitFailure{Message: violation.Message, Type: violation.Severity, Text: rule.Description}
+				suite.Failures++
+			} else {
+				testCase.Skipped = &junitSkipped{Message: violation.Severity + ": " + violation.Message}
+				suite.Skipped++
+			}
+
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		if len(suite.Cases) == 0 {
+			testCase := junitTestCase{Name: rule.Description, ClassName: rule.ID}
+			if rule.Severity == model.SeverityOff {
+				testCase.Skipped = &junitSkipped{Message: "Rule is off"}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(out, "\n")
+	return err
+}
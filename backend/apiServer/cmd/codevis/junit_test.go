@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	report := model.ViolationReport{
+		Rules: []model.RuleModel{
+			{ID: model.RuleFunctionLines, Description: "Function is too long", Severity: model.SeverityWarning},
+			{ID: model.RulePublicMembers, Description: "Class has too many public members", Severity: model.SeverityInfo},
+			{ID: model.RuleParameters, Description: "Function has too many parameters", Severity: model.SeverityWarning},
+			{ID: model.RuleNamespaceDepth, Description: "Namespace is nested too deep", Severity: model.SeverityOff},
+		},
+		Violations: []model.ViolationModel{
+			{Rule: model.RuleFunctionLines, Severity: model.SeverityWarning, Message: "100 lines", QualifiedName: "main", FileName: "Main.java", StartLine: 1},
+			{Rule: model.RulePublicMembers, Severity: model.SeverityInfo, Message: "30 public members", QualifiedName: "Main", FileName: "Main.java", StartLine: 1},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		failOn       string
+		wantFailures int
+		wantSkipped  map[string]int
+	}{
+		{
+			name:         "Fail on warning",
+			failOn:       model.SeverityWarning,
+			wantFailures: 1,
+			wantSkipped:  map[string]int{model.RulePublicMembers: 1, model.RuleNamespaceDepth: 1},
+		},
+		{
+			name:         "Fail on none",
+			failOn:       failNever,
+			wantFailures: 0,
+			wantSkipped:  map[string]int{model.RuleFunctionLines: 1, model.RulePublicMembers: 1, model.RuleNamespaceDepth: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeJUnit(&out, report, tt.failOn); err != nil {
+				t.Fatalf("writeJUnit() error = %v", err)
+			}
+
+			var suites junitTestSuites
+			if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+				t.Fatalf("Failed to decode junit: %v\n%s", err, out.String())
+			}
+
+			if suites.Tests != 4 || suites.Failures != tt.wantFailures {
+				t.Errorf("tests = %d, failures = %d, want 4 and %d", suites.Tests, suites.Failures, tt.wantFailures)
+			}
+			if len(suites.Suites) != len(report.Rules) {
+				t.Fatalf("suites = %d, want one per rule", len(suites.Suites))
+			}
+			for _, suite := range suites.Suites {
+				if suite.Skipped != tt.wantSkipped[suite.Name] {
+					t.Errorf("suite %s skipped = %d, want %d", suite.Name, suite.Skipped, tt.wantSkipped[suite.Name])
+				}
+			}
+		})
+	}
+}
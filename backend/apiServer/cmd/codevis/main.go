@@ -0,0 +1,237 @@
+//codevis parses a local checkout and checks it against the quality rules, for use in CI pipelines.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+var packageName = "codevis"
+
+// Exit codes of the command.
+const (
+	exitPassed = 0 // No violation at or above the failing severity
+	exitFailed = 1 // Violations at or above the failing severity
+	exitError  = 2 // Invalid usage or the checkout could not be analysed
+)
+
+// Output formats of the report.
+const (
+	formatJSON  = "json"
+	formatJUnit = "junit"
+	formatSarif = "sarif"
+)
+
+// failNever disables failing on violations.
+const failNever = "none"
+
+// options holds the command line flags.
+type options struct {
+	root    string
+	format  string
+	output  string
+	failOn  string
+	parser  string
+	include string
+	exclude string
+}
+
+// result is the json report of a run.
+type result struct {
+	Root    string                `json:"root"`
+	Passed  bool                  `json:"passed"`
+	FailOn  string                `json:"fail_on"`
+	Metrics model.ProjectMetrics  `json:"metrics"`
+	Report  model.ViolationReport `json:"report"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command with args, writing the report to stdout unless an output file is given.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	util.SetLogOutput(stderr)
+
+	opts, err := parseOptions(args, stderr)
+	if err != nil {
+		return exitError
+	}
+
+	if logLevel := os.Getenv("LOG_LEVEL"); len(logLevel) > 0 && !util.SetLogLevel(logLevel) {
+		util.TypeLogger.Warn("%s: Unknown $LOG_LEVEL: %s", packageName, logLevel)
+	}
+
+	config, err := model.LoadRepoConfig(opts.root)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid %s: %s\n", model.RepoConfigFile, err.Error())
+		return exitError
+	}
+	config = config.WithDefaults()
+
+	files, err := model.CollectFiles(opts.root,
+		model.FileFilter{Include: config.Include, Exclude: config.Exclude},
+		model.FileFilter{Include: splitList(opts.include), Exclude: splitList(opts.exclude)},
+	)
+	if err != nil {
+		fmt.Fprintf(stderr, "Could not collect files: %s\n", err.Error())
+		return exitError
+	}
+
+	project, response := model.ParseFiles(opts.parser, files, config, func(response model.ParseResponse) {
+		util.TypeLogger.Info("%s: Parsed %d of %d files", packageName, response.ParsedFileCount+response.SkippedFileCount, response.FileCount)
+	})
+	model.ResolveProjectIncludes(opts.root, project, config)
+	model.RelativeFileNames(project, opts.root)
+
+	if response.ParsedFileCount > 0 && countParsed(project) == 0 {
+		fmt.Fprintf(stderr, "No file could be parsed, check the java parser in %s\n", opts.parser)
+		return exitError
+	}
+
+	report := model.CheckRules(project, config)
+
+	passed := true
+	if opts.failOn != failNever {
+		for _, violation := range report.Violations {
+			if model.SeverityAtLeast(violation.Severity, opts.failOn) {
+				passed = false
+				break
+			}
+		}
+	}
+
+	out := stdout
+	if len(opts.output) > 0 {
+		file, err := os.Create(opts.output)
+		if err != nil {
+			fmt.Fprintf(stderr, "Could not create output file: %s\n", err.Error())
+			return exitError
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writeReport(out, opts, result{
+		Root:    opts.root,
+		Passed:  passed,
+		FailOn:  opts.failOn,
+		Metrics: model.ComputeMetrics(project),
+		Report:  report,
+	}); err != nil {
+		fmt.Fprintf(stderr, "Could not write report: %s\n", err.Error())
+		return exitError
+	}
+
+	if !passed {
+		fmt.Fprintf(stderr, "Quality gate failed: violations at severity %s or above\n", opts.failOn)
+		return exitFailed
+	}
+
+	return exitPassed
+}
+
+// parseOptions reads the flags and the checkout path from args.
+func parseOptions(args []string, stderr io.Writer) (opts options, err error) {
+	flags := flag.NewFlagSet("codevis", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: codevis [flags] [path]\n\nParses the checkout at path, the working directory by default,\nand checks it against the rules of its %s.\n\nFlags:\n", model.RepoConfigFile)
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&opts.format, "format", formatJSON, "Report format: json, junit or sarif")
+	flags.StringVar(&opts.output, "output", "", "Write the report to this file instead of stdout")
+	flags.StringVar(&opts.failOn, "fail-on", model.SeverityWarning, "Exit with 1 on violations of this severity or above: info, warning, error or none")
+	flags.StringVar(&opts.parser, "parser", os.Getenv("JAVA_PARSER"), "Folder of the java parser, $JAVA_PARSER by default")
+	flags.StringVar(&opts.include, "include", "", "Comma separated globs files must match, in addition to the configuration")
+	flags.StringVar(&opts.exclude, "exclude", "", "Comma separated globs of files to skip, in addition to the configuration")
+
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return opts, fmt.Errorf("too many arguments")
+	}
+
+	opts.root = "."
+	if flags.NArg() == 1 {
+		opts.root = flags.Arg(0)
+	}
+	if opts.root, err = filepath.Abs(opts.root); err != nil {
+		fmt.Fprintf(stderr, "Invalid path: %s\n", err.Error())
+		return opts, err
+	}
+	if info, err := os.Stat(opts.root); err != nil || !info.IsDir() {
+		fmt.Fprintf(stderr, "Not a directory: %s\n", opts.root)
+		return opts, fmt.Errorf("not a directory")
+	}
+
+	switch {
+	case opts.format != formatJSON && opts.format != formatJUnit && opts.format != formatSarif:
+		fmt.Fprintf(stderr, "Unknown format: %s\n", opts.format)
+		return opts, fmt.Errorf("unknown format")
+
+	case opts.failOn != failNever && !model.IsSeverity(opts.failOn):
+		fmt.Fprintf(stderr, "Unknown severity: %s\n", opts.failOn)
+		return opts, fmt.Errorf("unknown severity")
+
+	case len(opts.parser) == 0:
+		fmt.Fprintf(stderr, "The java parser is not set, use -parser or $JAVA_PARSER\n")
+		return opts, fmt.Errorf("parser not set")
+	}
+
+	return opts, nil
+}
+
+// writeReport writes res to out in the format of opts.
+func writeReport(out io.Writer, opts options, res result) error {
+	switch opts.format {
+	case formatJUnit:
+		return writeJUnit(out, res.Report, opts.failOn)
+
+	case formatSarif:
+		builder := model.NewSarifBuilder("")
+		builder.AddViolations(res.Report)
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(builder.Log())
+
+	default:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(res)
+	}
+}
+
+// countParsed counts the files of project the parser succeeded on.
+func countParsed(project model.ProjectModel) (parsed int) {
+	for _, file := range project.Files {
+		if file.Parsed {
+			parsed++
+		}
+	}
+
+	return parsed
+}
+
+// splitList splits a comma separated flag value, ignoring empty elements.
+func splitList(value string) (list []string) {
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); len(element) > 0 {
+			list = append(list, element)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// fakeParser is a java command reporting one function of the file it parses, from line 1 to $END_LINE.
+const fakeParser = `#!/bin/sh
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-f" ]; then file="$2"; fi
+	shift
+done
+printf '{"file": {"file_name": "%s", "functions": [{"name": "main(String[]args)", "start_line": 1, "end_line": %s}]}}' "$file" "$END_LINE"
+`
+
+// checkout creates a checkout with one java file, parsed into a function of functionLines lines by a fake java parser.
+func checkout(t *testing.T, functionLines int) (root string, parser string) {
+	bin := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(bin, "java"), []byte(fakeParser), 0755); err != nil {
+		t.Fatalf("Failed to write fake parser: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("END_LINE", strconv.Itoa(functionLines))
+
+	root = t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(root, "Main.java"), []byte("class Main {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write checkout: %v", err)
+	}
+
+	return root, bin
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		functionLines int
+		flags         []string
+		wantCode      int
+	}{
+		{name: "Defaults breached", functionLines: 100, wantCode: exitFailed},
+		{name: "Defaults kept", functionLines: 10, wantCode: exitPassed},
+		{name: "Fail on none", functionLines: 100, flags: []string{"-fail-on", "none"}, wantCode: exitPassed},
+		{name: "Fail on error", functionLines: 100, flags: []string{"-fail-on", "error"}, wantCode: exitPassed},
+		{name: "Junit breached", functionLines: 100, flags: []string{"-format", "junit"}, wantCode: exitFailed},
+		{name: "Bad format", functionLines: 100, flags: []string{"-format", "xml"}, wantCode: exitError},
+		{name: "Bad severity", functionLines: 100, flags: []string{"-fail-on", "fatal"}, wantCode: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, parser := checkout(t, tt.functionLines)
+			args := append(append([]string{"-parser", parser}, tt.flags...), root)
+			var stdout, stderr bytes.Buffer
+
+			code := run(args, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\n%s", code, tt.wantCode, stderr.String())
+			}
+			if code == exitError || len(tt.flags) > 0 && tt.flags[0] == "-format" {
+				return
+			}
+
+			var res result
+			if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+				t.Fatalf("Failed to decode report: %v", err)
+			}
+			if res.Passed != (tt.wantCode == exitPassed) {
+				t.Errorf("passed = %t, want %t", res.Passed, tt.wantCode == exitPassed)
+			}
+			if breached := tt.functionLines > 60; breached != (len(res.Report.Violations) > 0) {
+				t.Errorf("violations = %+v, want breached %t", res.Report.Violations, breached)
+			}
+		})
+	}
+}
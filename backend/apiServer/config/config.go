@@ -0,0 +1,240 @@
+//Package config holds the configuration of the api server.
+//It is read from a YAML file, the environment and flags, and validated at startup.
+package config
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Config is the configuration of the api server.
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Storage  Storage  `yaml:"storage"`
+	Database Database `yaml:"database"`
+	Log      Log      `yaml:"log"`
+	Auth     Auth     `yaml:"auth"`
+	Limits   Limits   `yaml:"limits"`
+}
+
+// Server configures how the api is served.
+type Server struct {
+	Port            int           `yaml:"port"`             // Port to listen on
+	TLSCertFile     string        `yaml:"tls_cert_file"`    // Certificate to serve HTTPS with, set together with TLSKeyFile
+	TLSKeyFile      string        `yaml:"tls_key_file"`     // Private key of the certificate
+	ReadTimeout     time.Duration `yaml:"read_timeout"`     // Time to read a request
+	WriteTimeout    time.Duration `yaml:"write_timeout"`    // Time to write a response
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`     // Time keep-alive connections wait for the next request
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // Time running requests and parses get to finish on shutdown
+	CORSOrigins     []string      `yaml:"cors_origins"`     // Origins of websites allowed to use the api, "*" for every origin
+}
+
+// Storage configures where files are kept.
+type Storage struct {
+	RepoPath       string `yaml:"repository_path"` // Folder git repositories are cloned into
+	JavaParserPath string `yaml:"java_parser"`     // Folder of the compiled java parser
+}
+
+// Database configures the database connection.
+type Database struct {
+	Location string `yaml:"location"` // Url of the MongoDB server
+}
+
+// Log configures logging.
+type Log struct {
+	Level string `yaml:"level"` // One of util.LogDebug, util.LogInfo, util.LogWarning and util.LogError
+	File  string `yaml:"file"`  // File to log to, stdout if empty
+}
+
+// Auth configures who may log in and administrate the server.
+type Auth struct {
+	AdminUsers []string `yaml:"admin_users"` // Ids of the users allowed to read the audit log, as told by /auth/me
+	OIDC       OIDC     `yaml:"oidc"`        // Login with an OpenID Connect provider
+}
+
+// OIDC configures logins with an OpenID Connect provider, disabled if Issuer is empty.
+type OIDC struct {
+	Issuer        string   `yaml:"issuer"`          // Url of the provider
+	ClientID      string   `yaml:"client_id"`       // Id of the api server at the provider
+	ClientSecret  string   `yaml:"client_secret"`   // Secret of the api server at the provider, empty for public clients
+	RedirectURL   string   `yaml:"redirect_url"`    // Url of /auth/oidc/callback as registered at the provider
+	Scopes        []string `yaml:"scopes"`          // Scopes besides "openid" to request
+	AfterLoginURL string   `yaml:"after_login_url"` // Where users are sent after logging in
+}
+
+// Limits configures how much work clients may cause.
+type Limits struct {
+	RateLimit int `yaml:"rate_limit"` // Expensive requests per minute and client
+	RateBurst int `yaml:"rate_burst"` // Expensive requests a client may make at once
+	MaxClones int `yaml:"max_clones"` // Clones running at once
+	MaxParses int `yaml:"max_parses"` // Parses running at once
+	MaxQueue  int `yaml:"max_queue"`  // Clones or parses waiting for their turn
+}
+
+// Default returns the configuration used for anything not set otherwise.
+func Default() Config {
+	return Config{
+		Server: Server{
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    2 * time.Minute,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Auth: Auth{
+			OIDC: OIDC{
+				Scopes:        []string{"email", "profile"},
+				AfterLoginURL: "/",
+			},
+		},
+		Limits: Limits{
+			RateLimit: 30,
+			RateBurst: 10,
+			MaxClones: 2,
+			MaxParses: 2,
+			MaxQueue:  20,
+		},
+	}
+}
+
+// TLS tells if the api is served with HTTPS.
+func (config Config) TLS() bool {
+	return len(config.Server.TLSCertFile) > 0
+}
+
+// Validate checks the whole configuration, returning every problem found in one error.
+func (config Config) Validate() error {
+	var problems []string
+	problem := func(message string) {
+		problems = append(problems, message)
+	}
+
+	if config.Server.Port < 1 || config.Server.Port > 65535 {
+		problem("server.port must be set to a port between 1 and 65535")
+	}
+	if (len(config.Server.TLSCertFile) == 0) != (len(config.Server.TLSKeyFile) == 0) {
+		problem("server.tls_cert_file and server.tls_key_file must be set together")
+	}
+	for _, file := range []string{config.Server.TLSCertFile, config.Server.TLSKeyFile} {
+		if _, err := os.Stat(file); len(file) > 0 && err != nil {
+			problem("cannot read " + file)
+		}
+	}
+	timeouts := []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{"server.read_timeout", config.Server.ReadTimeout},
+		{"server.write_timeout", config.Server.WriteTimeout},
+		{"server.idle_timeout", config.Server.IdleTimeout},
+		{"server.shutdown_timeout", config.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.timeout < 0 {
+			problem(timeout.name + " must not be negative")
+		}
+	}
+	for _, origin := range config.Server.CORSOrigins {
+		if !validOrigin(origin) {
+			problem("server.cors_origins has invalid origin " + origin)
+		}
+	}
+
+	if !isDir(config.Storage.RepoPath) {
+		problem("storage.repository_path must be set to an existing folder")
+	}
+	if !isDir(config.Storage.JavaParserPath) {
+		problem("storage.java_parser must be set to an existing folder")
+	}
+
+	if location, err := url.Parse(config.Database.Location); len(config.Database.Location) == 0 || err != nil || location.Scheme != "mongodb" {
+		problem("database.location must be set to a mongodb:// url")
+	}
+
+	switch config.Log.Level {
+	case "", util.LogDebug, util.LogInfo, util.LogWarning, util.LogError:
+	default:
+		problem("log.level must be one of " + strings.Join([]string{util.LogDebug, util.LogInfo, util.LogWarning, util.LogError}, ", "))
+	}
+
+	if oidc := config.Auth.OIDC; len(oidc.Issuer) > 0 {
+		if issuer, err := url.Parse(oidc.Issuer); err != nil || len(issuer.Host) == 0 {
+			problem("auth.oidc.issuer must be an url")
+		}
+		if len(oidc.ClientID) == 0 {
+			problem("auth.oidc.client_id must be set with auth.oidc.issuer")
+		}
+		if redirect, err := url.Parse(oidc.RedirectURL); err != nil || len(redirect.Host) == 0 {
+			problem("auth.oidc.redirect_url must be set to an url with auth.oidc.issuer")
+		}
+	}
+
+	for _, id := range config.Auth.AdminUsers {
+		if !bson.IsObjectIdHex(id) {
+			problem("auth.admin_users has " + id + ", which is not a user id")
+		}
+	}
+
+	limits := []struct {
+		name  string
+		limit int
+	}{
+		{"limits.rate_limit", config.Limits.RateLimit},
+		{"limits.rate_burst", config.Limits.RateBurst},
+		{"limits.max_clones", config.Limits.MaxClones},
+		{"limits.max_parses", config.Limits.MaxParses},
+		{"limits.max_queue", config.Limits.MaxQueue},
+	}
+	for _, limit := range limits {
+		if limit.limit < 1 {
+			problem(limit.name + " must be a positive number")
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("Invalid configuration: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// validOrigin tells if origin is "*" or a scheme with a host, like "https://codevis.example.com".
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	return err == nil && len(parsed.Scheme) > 0 && len(parsed.Host) > 0 && strings.TrimSuffix(parsed.Path, "/") == ""
+}
+
+// isDir tells if path is an existing folder.
+func isDir(path string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// splitList splits comma or space separated values.
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// parsePositive parses a positive number.
+func parsePositive(value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, errors.New("not a positive number")
+	}
+
+	return number, nil
+}
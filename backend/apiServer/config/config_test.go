@@ -0,0 +1,245 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupConfigDir creates a folder with the repository and parser folders, and the configuration file content.
+func setupConfigDir(t *testing.T, content string) (string, func()) {
+	root, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %s", err.Error())
+	}
+
+	for _, dir := range []string{"repos", "parser"} {
+		if err := os.Mkdir(filepath.Join(root, dir), os.ModePerm); err != nil {
+			t.Fatalf("Could not create directory: %s", err.Error())
+		}
+	}
+
+	content = strings.Replace(content, "$ROOT", root, -1)
+	if err := ioutil.WriteFile(filepath.Join(root, "codevis.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Could not write file: %s", err.Error())
+	}
+
+	return root, func() { os.RemoveAll(root) }
+}
+
+// env returns a getenv function reading from values.
+func env(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+const testFile = `
+server:
+  port: 5016
+  write_timeout: 5m
+  cors_origins: ["https://codevis.example.com"]
+storage:
+  repository_path: $ROOT/repos
+  java_parser: $ROOT/parser
+database:
+  location: mongodb://localhost:27017
+auth:
+  admin_users: [5c62d1904122c760dafe9341]
+limits:
+  max_parses: 4
+`
+
+func TestLoad(t *testing.T) {
+	root, cleanup := setupConfigDir(t, testFile)
+	defer cleanup()
+	file := filepath.Join(root, "codevis.yml")
+
+	t.Run("File", func(t *testing.T) {
+		config, err := Load("codevis", []string{"-config", file}, env(nil))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		if config.Server.Port != 5016 || config.Server.WriteTimeout != 5*time.Minute || config.Limits.MaxParses != 4 {
+			t.Errorf("Load() = %+v, want values of file", config)
+		}
+		if config.Server.ReadTimeout != 30*time.Second || config.Limits.MaxClones != 2 || config.Auth.OIDC.AfterLoginURL != "/" {
+			t.Errorf("Load() = %+v, want defaults for values not in file", config)
+		}
+		if !reflect.DeepEqual(config.Auth.AdminUsers, []string{"5c62d1904122c760dafe9341"}) {
+			t.Errorf("Load() admin users = %v, want [5c62d1904122c760dafe9341]", config.Auth.AdminUsers)
+		}
+	})
+
+	t.Run("File from environment", func(t *testing.T) {
+		config, err := Load("codevis", nil, env(map[string]string{FileEnv: file}))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if config.Server.Port != 5016 {
+			t.Errorf("Load() port = %d, want 5016", config.Server.Port)
+		}
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		config, err := Load("codevis", []string{"-config", file, "-port", "8080", "-max-parses", "6"}, env(map[string]string{
+			"PORT":         "7070",
+			"MAX_CLONES":   "3",
+			"ADMIN_USERS":  "5c62d1904122c760dafe9342, 5c62d1904122c760dafe9343",
+			"CORS_ORIGINS": "http://localhost",
+		}))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		if config.Server.Port != 8080 {
+			t.Errorf("Load() port = %d, want flag 8080 over environment and file", config.Server.Port)
+		}
+		if config.Limits.MaxParses != 6 || config.Limits.MaxClones != 3 {
+			t.Errorf("Load() limits = %+v, want max_parses 6 from flag and max_clones 3 from environment", config.Limits)
+		}
+		if !reflect.DeepEqual(config.Auth.AdminUsers, []string{"5c62d1904122c760dafe9342", "5c62d1904122c760dafe9343"}) {
+			t.Errorf("Load() admin users = %v, want both ids from environment", config.Auth.AdminUsers)
+		}
+		if !reflect.DeepEqual(config.Server.CORSOrigins, []string{"http://localhost"}) {
+			t.Errorf("Load() cors origins = %v, want [http://localhost] from environment", config.Server.CORSOrigins)
+		}
+	})
+
+	t.Run("Environment only", func(t *testing.T) {
+		_, err := Load("codevis", nil, env(map[string]string{
+			"PORT":            "5016",
+			"REPOSITORY_PATH": filepath.Join(root, "repos"),
+			"JAVA_PARSER":     filepath.Join(root, "parser"),
+			"DB_LOCATION":     "mongodb://mongo_db:27017",
+		}))
+		if err != nil {
+			t.Errorf("Load() error = %v", err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "Missing file", args: []string{"-config", filepath.Join(root, "missing.yml")}, wantErr: "missing.yml"},
+		{name: "Invalid number", args: []string{"-config", file}, env: map[string]string{"MAX_QUEUE": "none"}, wantErr: "$MAX_QUEUE"},
+		{name: "Invalid duration", args: []string{"-config", file, "-read-timeout", "soon"}, wantErr: "-read-timeout"},
+		{name: "Unknown flag", args: []string{"-config", file, "-colour"}, wantErr: "colour"},
+		{name: "Argument", args: []string{"-config", file, "serve"}, wantErr: "serve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load("codevis", tt.args, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_unknownKey(t *testing.T) {
+	root, cleanup := setupConfigDir(t, testFile+"  max_clone: 3\n")
+	defer cleanup()
+
+	_, err := Load("codevis", []string{"-config", filepath.Join(root, "codevis.yml")}, env(nil))
+	if err == nil || !strings.Contains(err.Error(), "max_clone") {
+		t.Errorf("Load() error = %v, want unknown key max_clone", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	root, cleanup := setupConfigDir(t, "")
+	defer cleanup()
+
+	valid := Default()
+	valid.Server.Port = 5016
+	valid.Storage = Storage{RepoPath: filepath.Join(root, "repos"), JavaParserPath: filepath.Join(root, "parser")}
+	valid.Database.Location = "mongodb://localhost:27017"
+
+	tests := []struct {
+		name    string
+		change  func(config *Config)
+		wantErr []string
+	}{
+		{name: "Valid", change: func(config *Config) {}},
+		{
+			name: "Missing settings",
+			change: func(config *Config) {
+				config.Server.Port = 0
+				config.Storage = Storage{}
+				config.Database.Location = ""
+			},
+			wantErr: []string{"server.port", "storage.repository_path", "storage.java_parser", "database.location"},
+		},
+		{
+			name:    "Half of TLS",
+			change:  func(config *Config) { config.Server.TLSCertFile = filepath.Join(root, "codevis.yml") },
+			wantErr: []string{"server.tls_cert_file and server.tls_key_file"},
+		},
+		{
+			name: "Invalid origin",
+			change: func(config *Config) {
+				config.Server.CORSOrigins = []string{"https://codevis.example.com", "codevis.example.com"}
+			},
+			wantErr: []string{"invalid origin codevis.example.com"},
+		},
+		{
+			name:    "Invalid log level",
+			change:  func(config *Config) { config.Log.Level = "LOG_TRACE" },
+			wantErr: []string{"log.level"},
+		},
+		{
+			name:    "OIDC without client",
+			change:  func(config *Config) { config.Auth.OIDC.Issuer = "https://accounts.example.com" },
+			wantErr: []string{"auth.oidc.client_id", "auth.oidc.redirect_url"},
+		},
+		{
+			name:    "Admin by name",
+			change:  func(config *Config) { config.Auth.AdminUsers = []string{"5c62d1904122c760dafe9341", "alice"} },
+			wantErr: []string{"auth.admin_users has alice"},
+		},
+		{
+			name:    "Negative timeout",
+			change:  func(config *Config) { config.Server.IdleTimeout = -time.Second },
+			wantErr: []string{"server.idle_timeout"},
+		},
+		{
+			name:    "Zero limit",
+			change:  func(config *Config) { config.Limits.MaxQueue = 0 },
+			wantErr: []string{"limits.max_queue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.change(&config)
+
+			err := config.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want containing %q", err, want)
+				}
+			}
+		})
+	}
+}
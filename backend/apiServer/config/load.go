@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileEnv is the environment variable naming the configuration file, unless given with -config.
+const FileEnv = "CONFIG_FILE"
+
+// setting is a configuration value that can be given by environment variable and flag.
+type setting struct {
+	env   string                                   // Name of the environment variable
+	flag  string                                   // Name of the flag
+	usage string                                   // Help text of the flag
+	set   func(config *Config, value string) error // Parses value into config
+}
+
+// settings are the values that can be given by environment variable and flag, in the order of the usage text.
+var settings = []setting{
+	{env: "PORT", flag: "port", usage: "Port to listen on", set: func(config *Config, value string) (err error) {
+		config.Server.Port, err = strconv.Atoi(value)
+		return err
+	}},
+	{env: "TLS_CERT_FILE", flag: "tls-cert", usage: "Certificate to serve HTTPS with", set: func(config *Config, value string) error {
+		config.Server.TLSCertFile = value
+		return nil
+	}},
+	{env: "TLS_KEY_FILE", flag: "tls-key", usage: "Private key of the certificate", set: func(config *Config, value string) error {
+		config.Server.TLSKeyFile = value
+		return nil
+	}},
+	{env: "READ_TIMEOUT", flag: "read-timeout", usage: "Time to read a request, like 30s", set: func(config *Config, value string) (err error) {
+		config.Server.ReadTimeout, err = time.ParseDuration(value)
+		return err
+	}},
+	{env: "WRITE_TIMEOUT", flag: "write-timeout", usage: "Time to write a response, like 2m", set: func(config *Config, value string) (err error) {
+		config.Server.WriteTimeout, err = time.ParseDuration(value)
+		return err
+	}},
+	{env: "IDLE_TIMEOUT", flag: "idle-timeout", usage: "Time keep-alive connections wait for the next request", set: func(config *Config, value string) (err error) {
+		config.Server.IdleTimeout, err = time.ParseDuration(value)
+		return err
+	}},
+	{env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "Time running requests and parses get to finish on shutdown", set: func(config *Config, value string) (err error) {
+		config.Server.ShutdownTimeout, err = time.ParseDuration(value)
+		return err
+	}},
+	{env: "CORS_ORIGINS", flag: "cors-origins", usage: "Comma separated origins of websites allowed to use the api", set: func(config *Config, value string) error {
+		config.Server.CORSOrigins = splitList(value)
+		return nil
+	}},
+	{env: "REPOSITORY_PATH", flag: "repository-path", usage: "Folder git repositories are cloned into", set: func(config *Config, value string) error {
+		config.Storage.RepoPath = value
+		return nil
+	}},
+	{env: "JAVA_PARSER", flag: "java-parser", usage: "Folder of the compiled java parser", set: func(config *Config, value string) error {
+		config.Storage.JavaParserPath = value
+		return nil
+	}},
+	{env: "DB_LOCATION", flag: "db", usage: "Url of the MongoDB server", set: func(config *Config, value string) error {
+		config.Database.Location = value
+		return nil
+	}},
+	{env: "LOG_LEVEL", flag: "log-level", usage: "LOG_DEBUG, LOG_INFO, LOG_WARNING or LOG_ERROR", set: func(config *Config, value string) error {
+		config.Log.Level = value
+		return nil
+	}},
+	{env: "LOG_FILE", flag: "log-file", usage: "File to log to instead of stdout", set: func(config *Config, value string) error {
+		config.Log.File = value
+		return nil
+	}},
+	{env: "ADMIN_USERS", flag: "admin-users", usage: "Comma separated ids of the users allowed to read the audit log", set: func(config *Config, value string) error {
+		config.Auth.AdminUsers = splitList(value)
+		return nil
+	}},
+	{env: "OIDC_ISSUER", flag: "oidc-issuer", usage: "Url of the OpenID Connect provider", set: func(config *Config, value string) error {
+		config.Auth.OIDC.Issuer = value
+		return nil
+	}},
+	{env: "OIDC_CLIENT_ID", flag: "oidc-client-id", usage: "Id of the api server at the provider", set: func(config *Config, value string) error {
+		config.Auth.OIDC.ClientID = value
+		return nil
+	}},
+	{env: "OIDC_CLIENT_SECRET", flag: "oidc-client-secret", usage: "Secret of the api server at the provider", set: func(config *Config, value string) error {
+		config.Auth.OIDC.ClientSecret = value
+		return nil
+	}},
+	{env: "OIDC_REDIRECT_URL", flag: "oidc-redirect-url", usage: "Url of /auth/oidc/callback as registered at the provider", set: func(config *Config, value string) error {
+		config.Auth.OIDC.RedirectURL = value
+		return nil
+	}},
+	{env: "OIDC_SCOPES", flag: "oidc-scopes", usage: "Space separated scopes besides openid to request", set: func(config *Config, value string) error {
+		config.Auth.OIDC.Scopes = strings.Fields(value)
+		return nil
+	}},
+	{env: "OIDC_AFTER_LOGIN_URL", flag: "oidc-after-login-url", usage: "Where users are sent after logging in", set: func(config *Config, value string) error {
+		config.Auth.OIDC.AfterLoginURL = value
+		return nil
+	}},
+	{env: "RATE_LIMIT", flag: "rate-limit", usage: "Expensive requests per minute and client", set: func(config *Config, value string) (err error) {
+		config.Limits.RateLimit, err = parsePositive(value)
+		return err
+	}},
+	{env: "RATE_BURST", flag: "rate-burst", usage: "Expensive requests a client may make at once", set: func(config *Config, value string) (err error) {
+		config.Limits.RateBurst, err = parsePositive(value)
+		return err
+	}},
+	{env: "MAX_CLONES", flag: "max-clones", usage: "Clones running at once", set: func(config *Config, value string) (err error) {
+		config.Limits.MaxClones, err = parsePositive(value)
+		return err
+	}},
+	{env: "MAX_PARSES", flag: "max-parses", usage: "Parses running at once", set: func(config *Config, value string) (err error) {
+		config.Limits.MaxParses, err = parsePositive(value)
+		return err
+	}},
+	{env: "MAX_QUEUE", flag: "max-queue", usage: "Clones or parses waiting for their turn", set: func(config *Config, value string) (err error) {
+		config.Limits.MaxQueue, err = parsePositive(value)
+		return err
+	}},
+}
+
+// Load reads the configuration in order of precedence from the flags in args, the environment read with getenv,
+// the YAML file given with -config or FileEnv and the defaults. The result is validated.
+func Load(name string, args []string, getenv func(string) string) (Config, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := flags.String("config", getenv(FileEnv), "YAML configuration file, $"+FileEnv+" by default")
+	for _, s := range settings {
+		flags.String(s.flag, "", s.usage+", $"+s.env+" by default")
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, errors.New("Unexpected argument " + flags.Arg(0))
+	}
+
+	config := Default()
+
+	if len(*file) > 0 {
+		if err := config.readFile(*file); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var problems []string
+	for _, s := range settings {
+		if value := getenv(s.env); len(value) > 0 {
+			if err := s.set(&config, value); err != nil {
+				problems = append(problems, "$"+s.env+": "+err.Error())
+			}
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := s.set(&config, f.Value.String()); err != nil {
+					problems = append(problems, "-"+s.flag+": "+err.Error())
+				}
+			}
+		}
+	})
+
+	if len(problems) > 0 {
+		return Config{}, errors.New("Invalid configuration: " + strings.Join(problems, "; "))
+	}
+
+	return config, config.Validate()
+}
+
+// readFile reads the YAML file into config, keeping the values the file does not set.
+func (config *Config) readFile(file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.UnmarshalStrict(content, config); err != nil {
+		return errors.New("Invalid configuration file " + file + ": " + err.Error())
+	}
+
+	return nil
+}
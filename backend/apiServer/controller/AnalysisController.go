@@ -0,0 +1,460 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// AnalysisController represents reports computed from a parsed repository.
+type AnalysisController struct {
+	Storage config.Storage // Where repositories are cloned to
+}
+
+/**
+* @api {GET} /repo/:repoId/deadcode?overlay=:overlay Report code not reachable from the entry points.
+* @apiName Get Dead Code.
+* @apiGroup Analysis
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {Boolean} [overlay=false] Also return the parsed repository with "dead" and "dead_confidence" set on every function.
+*
+* @apiDescription Resolves the parsed calls between functions and lists the functions and classes
+* never reached from the entry points in the repository configuration, like "main" or public api classes.
+* Each entry has a confidence: "high" when nothing refers to it, "medium" when it may be reached
+* through a virtual call and "low" when it is referred to without a call, as function pointer or by reflection.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c62d1904122c760dafe9341",
+*		"report": {
+*			"entry_points": ["main"],
+*			"reachable_functions": 42,
+*			"dead": [
+*				{
+*					"kind": "function",
+*					"name": "printDebug",
+*					"qualified_name": "ecs::Entity::printDebug",
+*					"file_name": "5c62d1904122c760dafe9341/src/Entity.cpp",
+*					"start_line": 88,
+*					"end_line": 97,
+*					"confidence": "high",
+*					"reason": "Not reachable from any entry point"
+*				}
+*			]
+*		}
+*	}
+*
+* @apiErrorExample {text/plain} Unknown repository.
+*	HTTP/1.1 404 Not Found
+*	{
+*		Not Found
+*	}
+ */
+
+// GetDeadCode reports unreachable functions and classes of a repository.
+func (analysis AnalysisController) GetDeadCode(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for dead code", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for dead code", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+
+		exstRepo, ok := findRepo(w, analysis.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		config, ok := analysisConfig(w, analysis.Storage, exstRepo)
+		if !ok {
+			return
+		}
+
+		report := model.FindDeadCode(exstRepo.ParsedRepo, config, analysis.Storage.RepoPath)
+
+		response := map[string]interface{}{
+			"id":     vars["repoId"],
+			"report": report,
+		}
+		if r.URL.Query().Get("overlay") == "true" {
+			response["parsedrepo"] = exstRepo.ParsedRepo
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /repo/:repoId/clones?min_tokens=:minTokens&threshold=:threshold Find duplicated functions.
+* @apiName Get Clones.
+* @apiGroup Analysis
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {Int} [minTokens=50] Functions with fewer tokens, not counting whitespace and comments, are ignored.
+* @apiParam {Number} [threshold=0.8] Minimum similarity between 0 and 1 for functions to be near clones.
+*
+* @apiDescription Compares the bodies of all parsed functions, within and across files, by their tokens.
+* Identifiers and literals are normalized, and hashes of token sequences are compared to find near clones.
+* Groups are "exact" for identical bodies, "renamed" for bodies only differing in names and literals
+* and "near" for bodies sharing most of their code. Similarity is the lowest similarity linking the group.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c62d1904122c760dafe9341",
+*		"report": {
+*			"functions": 120,
+*			"groups": [
+*				{
+*					"kind": "renamed",
+*					"similarity": 1,
+*					"members": [
+*						{
+*							"name": "updatePosition",
+*							"qualified_name": "ecs::Movement::updatePosition",
+*							"file_name": "5c62d1904122c760dafe9341/src/Movement.cpp",
+*							"start_line": 12,
+*							"end_line": 30,
+*							"tokens": 148
+*						},
+*						{
+*							"name": "updateVelocity",
+*							"qualified_name": "ecs::Movement::updateVelocity",
+*							"file_name": "5c62d1904122c760dafe9341/src/Movement.cpp",
+*							"start_line": 32,
+*							"end_line": 50,
+*							"tokens": 148
+*						}
+*					]
+*				}
+*			]
+*		}
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'min_tokens'|'threshold'
+*	}
+ */
+
+// GetClones reports groups of duplicated functions in a repository.
+func (analysis AnalysisController) GetClones(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for clones", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for clones", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+
+		options := model.CloneOptions{}
+		var err error
+
+		if minTokens := r.URL.Query().Get("min_tokens"); len(minTokens) > 0 {
+			if options.MinTokens, err = strconv.Atoi(minTokens); err != nil || options.MinTokens < 1 {
+				http.Error(w, "Invalid url parameter 'min_tokens'", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if threshold := r.URL.Query().Get("threshold"); len(threshold) > 0 {
+			if options.Similarity, err = strconv.ParseFloat(threshold, 64); err != nil || options.Validate() != nil || options.Similarity == 0 {
+				http.Error(w, "Invalid url parameter 'threshold'", http.StatusBadRequest)
+				return
+			}
+		}
+
+		exstRepo, ok := findRepo(w, analysis.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		config, ok := analysisConfig(w, analysis.Storage, exstRepo)
+		if !ok {
+			return
+		}
+
+		report, err := model.FindClones(exstRepo.ParsedRepo, config, analysis.Storage.RepoPath, options)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     vars["repoId"],
+			"report": report,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /repo/:repoId/violations?severity=:severity Evaluate quality rules.
+* @apiName Get Violations.
+* @apiGroup Analysis
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String="info","warning","error"} [severity=info] Only return violations at least this severe.
+*
+* @apiDescription Evaluates the rules of the repository configuration over the parsed repository.
+* Thresholds are set under "thresholds" and the severity of each rule under "rules", where "off"
+* disables the rule. The rules are "function-lines", "parameters", "public-members" and "namespace-depth".
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c62d1904122c760dafe9341",
+*		"report": {
+*			"rules": [
+*				{"id": "function-lines", "description": "Function is longer than the threshold in lines", "severity": "warning", "threshold": 60},
+*				{"id": "parameters", "description": "Function has more parameters than the threshold", "severity": "warning", "threshold": 5},
+*				{"id": "public-members", "description": "Class has more public members than the threshold", "severity": "info", "threshold": 20},
+*				{"id": "namespace-depth", "description": "Namespaces are nested deeper than the threshold", "severity": "warning", "threshold": 4}
+*			],
+*			"counts": {"warning": 1},
+*			"violations": [
+*				{
+*					"rule": "function-lines",
+*					"severity": "warning",
+*					"message": "ecs::World::update has 112 lines, more than 60",
+*					"kind": "function",
+*					"qualified_name": "ecs::World::update",
+*					"file_name": "5c62d1904122c760dafe9341/src/World.cpp",
+*					"start_line": 40,
+*					"end_line": 151,
+*					"value": 112,
+*					"threshold": 60
+*				}
+*			]
+*		}
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'severity'
+*	}
+ */
+
+// GetViolations reports the rule violations of a repository.
+func (analysis AnalysisController) GetViolations(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for violations", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for violations", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+
+		minimum := r.URL.Query().Get("severity")
+		if len(minimum) == 0 {
+			minimum = model.SeverityInfo
+		}
+		if !model.IsSeverity(minimum) {
+			http.Error(w, "Invalid url parameter 'severity'", http.StatusBadRequest)
+			return
+		}
+
+		exstRepo, ok := findRepo(w, analysis.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		config, ok := analysisConfig(w, analysis.Storage, exstRepo)
+		if !ok {
+			return
+		}
+
+		report := model.CheckRules(exstRepo.ParsedRepo, config)
+
+		violations := []model.ViolationModel{}
+		for _, violation := range report.Violations {
+			if model.SeverityAtLeast(violation.Severity, minimum) {
+				violations = append(violations, violation)
+			}
+		}
+		report.Violations = violations
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     vars["repoId"],
+			"report": report,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// Findings that can be exported as SARIF.
+const (
+	findingViolations = "violations"
+	findingDeadCode   = "deadcode"
+	findingClones     = "clones"
+	findingCycles     = "cycles"
+)
+
+/**
+* @api {GET} /repo/:repoId/sarif?findings=:findings Export analysis findings as SARIF.
+* @apiName Get Sarif.
+* @apiGroup Analysis
+* @apiPermission analyst
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String} [findings=violations,deadcode,clones,cycles] Comma separated findings to export.
+*
+* @apiDescription Exports the rule violations, dead code, duplicated functions and include cycles
+* of the parsed repository as a SARIF 2.1.0 log, to be loaded in code scanning viewers.
+* File uris are relative to the root of the repository, "SRCROOT", and regions are the lines of the code.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+*		"version": "2.1.0",
+*		"runs": [
+*			{
+*				"tool": {
+*					"driver": {
+*						"name": "codevis",
+*						"rules": [
+*							{"id": "include-cycle", "shortDescription": {"text": "Files include each other in a cycle"}, "defaultConfiguration": {"level": "warning"}}
+*						]
+*					}
+*				},
+*				"results": [
+*					{
+*						"ruleId": "include-cycle",
+*						"level": "warning",
+*						"message": {"text": "Include cycle: src/World.hpp -> src/Entity.hpp -> src/World.hpp"},
+*						"locations": [{"physicalLocation": {"artifactLocation": {"uri": "src/World.hpp", "uriBaseId": "SRCROOT"}}}],
+*						"relatedLocations": [{"physicalLocation": {"artifactLocation": {"uri": "src/Entity.hpp", "uriBaseId": "SRCROOT"}}}]
+*					}
+*				]
+*			}
+*		]
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'findings'
+*	}
+ */
+
+// GetSarif exports the findings of a repository as a SARIF log.
+func (analysis AnalysisController) GetSarif(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for sarif", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for sarif", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/sarif+json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+		user, _ := CurrentUser(r)
+
+		findings := map[string]bool{
+			findingViolations: true,
+			findingDeadCode:   true,
+			findingClones:     true,
+			findingCycles:     true,
+		}
+
+		if requested := r.URL.Query().Get("findings"); len(requested) > 0 {
+			selected := make(map[string]bool)
+			for _, finding := range strings.Split(requested, ",") {
+				finding = strings.TrimSpace(finding)
+				if !findings[finding] {
+					http.Error(w, "Invalid url parameter 'findings'", http.StatusBadRequest)
+					return
+				}
+				selected[finding] = true
+			}
+			findings = selected
+		}
+
+		exstRepo, ok := findRepo(w, analysis.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		config, ok := analysisConfig(w, analysis.Storage, exstRepo)
+		if !ok {
+			return
+		}
+
+		builder := model.NewSarifBuilder(vars["repoId"] + "/")
+
+		if findings[findingViolations] {
+			builder.AddViolations(model.CheckRules(exstRepo.ParsedRepo, config))
+		}
+
+		if findings[findingDeadCode] {
+			builder.AddDeadCode(model.FindDeadCode(exstRepo.ParsedRepo, config, analysis.Storage.RepoPath))
+		}
+
+		if findings[findingClones] {
+			report, err := model.FindClones(exstRepo.ParsedRepo, config, analysis.Storage.RepoPath, model.CloneOptions{})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				util.TypeLogger.Error("%s: Failed to find clones: %s", packageName, err.Error())
+				recordAudit(user, clientIP(r), model.AuditExport, vars["repoId"], model.OutcomeFailure, err.Error())
+				return
+			}
+			builder.AddClones(report)
+		}
+
+		if findings[findingCycles] {
+			builder.AddIncludeCycles(model.FindIncludeCycles(exstRepo.ParsedRepo))
+		}
+
+		recordAudit(user, clientIP(r), model.AuditExport, vars["repoId"], model.OutcomeSuccess, "sarif")
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(builder.Log())
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// analysisConfig returns the configuration of repo, responding with an error if it is invalid.
+func analysisConfig(w http.ResponseWriter, storage config.Storage, repo model.RepoModel) (model.RepoConfig, bool) {
+	config, err := repo.GetConfig(storage)
+	if err != nil {
+		http.Error(w, "Invalid "+model.RepoConfigFile+": "+err.Error(), http.StatusUnprocessableEntity)
+		return model.RepoConfig{}, false
+	}
+
+	return config, true
+}
@@ -0,0 +1,157 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// AuditController represents the audit log of repository actions.
+type AuditController struct {
+}
+
+/**
+* @api {GET} /audit Query the audit log.
+* @apiName Get Audit Log.
+* @apiGroup Audit
+* @apiPermission admin users of the server
+*
+* @apiParam {String} [user] Name of the user who acted.
+* @apiParam {String} [repo] Id of the repository acted on.
+* @apiParam {String="add","parse","reparse","export","delete","access"} [action] What was done, "access" for requests denied by the role of the user.
+* @apiParam {String="success","failure","denied"} [outcome] How it ended.
+* @apiParam {String} [since] RFC 3339 time of the oldest entry.
+* @apiParam {String} [until] RFC 3339 time entries must be older than.
+* @apiParam {Number} [limit=100] Maximum number of entries, at most 1000.
+*
+* @apiDescription Returns the newest entries first. Only users whose id is in $ADMIN_USERS may read the audit log.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"entries": [
+*			{
+*				"id": "5c90a1f24122c70f6a0ab1d3",
+*				"time": "2019-03-19T08:14:42Z",
+*				"user": "bob",
+*				"client_ip": "10.0.3.17",
+*				"action": "export",
+*				"repo_id": "5c62d1904122c760dafe9341",
+*				"outcome": "success",
+*				"detail": "graphml"
+*			}
+*		]
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'since'
+*	}
+ */
+
+// GetAudit responds with the entries of the audit log matching the query parameters.
+func (audit AuditController) GetAudit(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for audit log", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for audit log", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		values := r.URL.Query()
+		query := model.AuditQuery{
+			UserName: values.Get("user"),
+			RepoID:   values.Get("repo"),
+			Action:   values.Get("action"),
+			Outcome:  values.Get("outcome"),
+		}
+
+		var err error
+		for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+			if value := values.Get(name); len(value) > 0 {
+				if *target, err = time.Parse(time.RFC3339, value); err != nil {
+					http.Error(w, "Invalid url parameter '"+name+"'", http.StatusBadRequest)
+					return
+				}
+			}
+		}
+
+		if value := values.Get("limit"); len(value) > 0 {
+			if query.Limit, err = strconv.Atoi(value); err != nil || query.Limit < 1 {
+				http.Error(w, "Invalid url parameter 'limit'", http.StatusBadRequest)
+				return
+			}
+		}
+
+		entries, err := model.FindAudit(query)
+		if err == model.ErrInvalidAuditQuery {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to query audit log: %s", packageName, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries": entries,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// RequireAdmin returns a middleware responding with 403 Forbidden unless the user from RequireUser
+// is one of adminUsers, the admins of the server.
+func RequireAdmin(adminUsers []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := CurrentUser(r)
+			if !ok || !user.IsAdmin(adminUsers) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				util.TypeLogger.Warn("%s: Denied %s %s to %s, not an admin", packageName, r.Method, r.URL.Path, user.Name)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// recordAudit adds the action of user, requesting from ip, on the repository with repoID to the audit log.
+// It takes the user and address instead of the request, so jobs record after their request ended.
+// Failing to record is logged, but does not fail the request.
+func recordAudit(user model.UserModel, ip string, action string, repoID string, outcome string, detail string) {
+	model.AuditModel{
+		UserID:   user.ID,
+		UserName: user.Name,
+		ClientIP: ip,
+		Action:   action,
+		RepoID:   repoID,
+		Outcome:  outcome,
+		Detail:   detail,
+	}.Record()
+}
+
+// clientIP returns the address r came from, without port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
 	"gopkg.in/mgo.v2/bson"
@@ -15,18 +16,20 @@ import (
 
 // CodeSnippetController represents a section of code in a given file.
 type CodeSnippetController struct {
+	Storage config.Storage // Where repositories are cloned to
 }
 
 /**
-* @api {GET} http://localhost:8080/repo/:repoId/file/read/?lineStart=:StartNr&lineEnd=:EndNr&filePath=:filePath Fetch the implementation of file based upon range.
+* @api {GET} /repo/:repoId/file/read/?lineStart=:StartNr&lineEnd=:EndNr&filePath=:filePath Fetch the implementation of file based upon range.
 * @apiName Get Implementation.
 * @apiGroup File
-* @apiPermission none
+* @apiPermission viewer
 *
 * @apiParam {String} repoId Id of submitted git repository.
 * @apiParam {Int} StartNr line number where the fetch start.
 * @apiParam {Int} EndNr line number where the fetch stop.
-* @apiParam {filePath} filePath is the file to fetch from.
+* @apiParam {filePath} filePath is the file to fetch from, starting with repoId as in the parsed file names.
+* @apiParam {String="text","tokens","html"} [format=text] Return raw text, annotated tokens or highlighted html.
 *
  *
 * @apiParamExample {url} Parse repository:
@@ -43,10 +46,54 @@ type CodeSnippetController struct {
 *		"implementation": "\t\t{\n\t\t\tstd::string name = typeid(*this).name();\n\t\t\tif (typeid(*this).__is_pointer_p())\n\t\t\t\tname.erase(name.begin(), name.begin() + 1);\n\t\t\tif (removeDigits)\n\t\t\t{\n\t\t\t\tint i = 0;\n\t\t\t\twhile (isdigit(name.at(i))) i++;\n\t\t\t\tname.erase(name.begin(), name.begin() + i);\n\t\t\t}\n\t\t\treturn name;\n\t\t}\n"
 *	}
 *
+* @apiSuccessExample {json} Success-Response format=tokens:
+* 	HTTP/1.1 200 OK
+*	{
+*		"language": "cpp",
+*		"start_line": 17,
+*		"end_line": 17,
+*		"tokens": [
+*			{"line": 17, "column": 1, "text": "\t", "kind": "whitespace"},
+*			{"line": 17, "column": 2, "text": "return", "kind": "keyword"},
+*			{"line": 17, "column": 8, "text": " ", "kind": "whitespace"},
+*			{"line": 17, "column": 9, "text": "name", "kind": "identifier", "ref": {
+*				"kind": "variable",
+*				"name": "name",
+*				"qualified_name": "Component::getName::name",
+*				"file_name": "5c62d1904122c760dafe9341/main.cpp",
+*				"start_line": 5,
+*				"end_line": 28,
+*				"local": true
+*			}},
+*			{"line": 17, "column": 13, "text": ";", "kind": "operator"}
+*		]
+*	}
+*
+* @apiSuccessExample {json} Success-Response format=html:
+* 	HTTP/1.1 200 OK
+*	{
+*		"language": "cpp",
+*		"start_line": 17,
+*		"end_line": 17,
+*		"html": "\t<span class=\"tok-keyword\">return</span> <span class=\"tok-identifier\" data-kind=\"variable\" data-name=\"Component::getName::name\" data-file=\"5c62d1904122c760dafe9341/main.cpp\" data-start-line=\"5\" data-end-line=\"28\">name</span><span class=\"tok-operator\">;</span>"
+*	}
+*
 * @apiErrorExample {json} Invalid parameters.
 *	HTTP/1.1 400 Bad Request
 *	{
-*		"Invalid url parameter 'lineStart'|'lineEnd'|'filePath'"
+*		"Invalid url parameter 'lineStart'|'lineEnd'|'filePath'|'format'"
+*	}
+*
+* @apiErrorExample {text/plain} Path outside of the repository.
+*	HTTP/1.1 403 Forbidden
+*	{
+*		Forbidden
+*	}
+*
+* @apiErrorExample {text/plain} Unknown repository or file.
+*	HTTP/1.1 404 Not Found
+*	{
+*		Not Found
 *	}
 *
 * @apiErrorExample {json} Internal error.
@@ -63,11 +110,24 @@ func (codeSnippet CodeSnippetController) GetImplementation(w http.ResponseWriter
 	defer util.TypeLogger.Info("%s: Ended request for implementation", packageName)
 
 	http.Header.Add(w.Header(), "content-type", "application/json")
-	http.Header.Add(w.Header(), "Access-Control-Allow-Origin", "*")
 
 	if r.Method == "GET" {
 		vars := mux.Vars(r)
 
+		// The repository in the route scopes which files can be read.
+		if !bson.IsObjectIdHex(vars["repoId"]) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			util.TypeLogger.Warn("%s: Received invalid repository id", packageName)
+			return
+		}
+
+		exstRepo, err := model.RepoModel{}.GetRepoByID(codeSnippet.Storage, vars["repoId"])
+		if err != nil || !exstRepo.ID.Valid() {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			util.TypeLogger.Warn("%s: Failed to find repository: %s", packageName, vars["repoId"])
+			return
+		}
+
 		// Get lineStart parameter from url.
 		lineStart, ok := r.URL.Query()["lineStart"]
 		if !ok || len(lineStart[0]) < 1 {
@@ -92,6 +152,14 @@ func (codeSnippet CodeSnippetController) GetImplementation(w http.ResponseWriter
 			return
 		}
 
+		// Get optional format parameter from url.
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "text" && format != "tokens" && format != "html" {
+			http.Error(w, "Invalid url parameter 'format'", http.StatusBadRequest)
+			util.TypeLogger.Warn("%s: Received request with unknown format: %s", packageName, format)
+			return
+		}
+
 		// Convert strings to integers.
 		startLine, err := strconv.Atoi(lineStart[0])
 		if err != nil {
@@ -116,24 +184,26 @@ func (codeSnippet CodeSnippetController) GetImplementation(w http.ResponseWriter
 		if startLine > endLine {
 			http.Error(w, "Cant give negative interval", http.StatusBadRequest)
 			util.TypeLogger.Warn("%s: startLine vas greater than endLine: %d-%d", packageName, startLine, endLine)
+			return
 		}
 
-		// Fetch the content of file.
-		implementation, err := model.CodeSnippetModel{
+		codeSnippetModel := model.CodeSnippetModel{
 			FilePath:  filePath[0],
 			ID:        bson.ObjectIdHex(vars["repoId"]),
 			StartLine: startLine,
 			EndLine:   endLine,
-		}.FetchLinesOfCode()
+		}
 
-		if err != nil {
-			if err.Error() == "StartLine out of range" {
-				http.Error(w, err.Error(), http.StatusBadRequest)
-				return
-			}
+		if format == "tokens" || format == "html" {
+			codeSnippet.writeHighlighted(w, exstRepo, codeSnippetModel, format)
+			return
+		}
 
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-			util.TypeLogger.Error("%s: Failed to get lines of code: %s", packageName, err.Error())
+		// Fetch the content of file.
+		implementation, err := codeSnippetModel.FetchLinesOfCode(codeSnippet.Storage.RepoPath)
+
+		if err != nil {
+			writeSnippetError(w, err)
 			return
 		}
 
@@ -149,3 +219,54 @@ func (codeSnippet CodeSnippetController) GetImplementation(w http.ResponseWriter
 	}
 
 }
+
+// writeHighlighted writes the snippet as annotated tokens or highlighted html based on format.
+func (codeSnippet CodeSnippetController) writeHighlighted(w http.ResponseWriter, repo model.RepoModel, codeSnippetModel model.CodeSnippetModel, format string) {
+	config, err := repo.GetConfig(codeSnippet.Storage)
+	if err != nil {
+		http.Error(w, "Invalid "+model.RepoConfigFile+": "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	language, _ := config.LanguageOf(codeSnippetModel.FilePath)
+	file, _ := repo.ParsedRepo.FindFile(codeSnippetModel.FilePath)
+
+	tokens, err := codeSnippetModel.FetchTokens(codeSnippet.Storage.RepoPath, language, file)
+	if err != nil {
+		writeSnippetError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"language":   language,
+		"start_line": codeSnippetModel.StartLine,
+		"end_line":   codeSnippetModel.EndLine,
+	}
+
+	if format == "tokens" {
+		response["tokens"] = tokens
+	} else {
+		response["html"] = model.HighlightHTML(tokens, codeSnippetModel.StartLine, codeSnippetModel.EndLine)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeSnippetError responds with the status matching an error from reading a snippet.
+func writeSnippetError(w http.ResponseWriter, err error) {
+	switch err {
+	case model.ErrLineOutOfRange:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+	case model.ErrForbiddenPath:
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+	case model.ErrFileNotFound:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.TypeLogger.Error("%s: Failed to get lines of code: %s", packageName, err.Error())
+	}
+}
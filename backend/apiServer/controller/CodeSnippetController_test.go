@@ -9,18 +9,30 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"gopkg.in/mgo.v2/bson"
 )
 
+// testStorage keeps test repositories in "/tmp/".
+var testStorage = config.Storage{RepoPath: "/tmp/"}
+
 var validRepo = model.RepoModel{URI: "https://github.com/zohaib194/CodebaseVisualizer3D.git"}
 var client = http.Client{}
 var validTestFile = newTestFile()
 
+// otherRepoID is a repository not stored in the database, with a file on disk that must not be reachable.
+var otherRepoID = bson.NewObjectId().Hex()
+
+// escapingLink is a symbolic link in the valid repository pointing to the other repository.
+var escapingLink = "escape.link"
+
 type testFile struct {
 	relativePath string
 	content      string
@@ -56,7 +68,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 	}{
 		{
 			name:        "valid whole file",
-			codeSnippet: CodeSnippetController{},
+			codeSnippet: CodeSnippetController{Storage: testStorage},
 			args: args{
 				repoID:    validRepo.ID.Hex(),
 				lineStart: 1,
@@ -69,7 +81,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 			},
 		}, {
 			name:        "valid interval",
-			codeSnippet: CodeSnippetController{},
+			codeSnippet: CodeSnippetController{Storage: testStorage},
 			args: args{
 				repoID:    validRepo.ID.Hex(),
 				lineStart: 3,
@@ -82,7 +94,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 			},
 		}, {
 			name:        "valid LineEnd greater than EOF",
-			codeSnippet: CodeSnippetController{},
+			codeSnippet: CodeSnippetController{Storage: testStorage},
 			args: args{
 				repoID:    validRepo.ID.Hex(),
 				lineStart: 1,
@@ -95,7 +107,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 			},
 		}, {
 			name:        "inValid LineStart less than 1",
-			codeSnippet: CodeSnippetController{},
+			codeSnippet: CodeSnippetController{Storage: testStorage},
 			args: args{
 				repoID:    validRepo.ID.Hex(),
 				lineStart: 0,
@@ -108,7 +120,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 			},
 		}, {
 			name:        "inValid LineStart past EOF",
-			codeSnippet: CodeSnippetController{},
+			codeSnippet: CodeSnippetController{Storage: testStorage},
 			args: args{
 				repoID:    validRepo.ID.Hex(),
 				lineStart: 100,
@@ -119,9 +131,87 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 				statusCode:     400,
 				implementation: "",
 			},
+		}, {
+			name:        "inValid Path traversal",
+			codeSnippet: CodeSnippetController{Storage: testStorage},
+			args: args{
+				repoID:    validRepo.ID.Hex(),
+				lineStart: 1,
+				lineEnd:   7,
+				filePath:  "../../etc/passwd",
+			},
+			expected: expect{
+				statusCode:     403,
+				implementation: "",
+			},
+		}, {
+			name:        "inValid Other repository",
+			codeSnippet: CodeSnippetController{Storage: testStorage},
+			args: args{
+				repoID:    validRepo.ID.Hex(),
+				lineStart: 1,
+				lineEnd:   7,
+				filePath:  "../" + otherRepoID + "/" + validTestFile.relativePath,
+			},
+			expected: expect{
+				statusCode:     403,
+				implementation: "",
+			},
+		}, {
+			name:        "inValid Symbolic link leaving repository",
+			codeSnippet: CodeSnippetController{Storage: testStorage},
+			args: args{
+				repoID:    validRepo.ID.Hex(),
+				lineStart: 1,
+				lineEnd:   7,
+				filePath:  escapingLink,
+			},
+			expected: expect{
+				statusCode:     403,
+				implementation: "",
+			},
+		}, {
+			name:        "inValid Git directory",
+			codeSnippet: CodeSnippetController{Storage: testStorage},
+			args: args{
+				repoID:    validRepo.ID.Hex(),
+				lineStart: 1,
+				lineEnd:   7,
+				filePath:  ".git/config",
+			},
+			expected: expect{
+				statusCode:     403,
+				implementation: "",
+			},
+		}, {
+			name:        "inValid Missing file",
+			codeSnippet: CodeSnippetController{Storage: testStorage},
+			args: args{
+				repoID:    validRepo.ID.Hex(),
+				lineStart: 1,
+				lineEnd:   7,
+				filePath:  "missing.test",
+			},
+			expected: expect{
+				statusCode:     404,
+				implementation: "",
+			},
+		}, {
+			name:        "inValid Unknown repository",
+			codeSnippet: CodeSnippetController{Storage: testStorage},
+			args: args{
+				repoID:    otherRepoID,
+				lineStart: 1,
+				lineEnd:   7,
+				filePath:  validTestFile.relativePath,
+			},
+			expected: expect{
+				statusCode:     404,
+				implementation: "",
+			},
 		}, {
 			name:        "inValid Negative interval",
-			codeSnippet: CodeSnippetController{},
+			codeSnippet: CodeSnippetController{Storage: testStorage},
 			args: args{
 				repoID:    validRepo.ID.Hex(),
 				lineStart: 7,
@@ -139,7 +229,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 		router := mux.NewRouter()
 		router.HandleFunc("/repo/{repoId}/file/read/", tt.codeSnippet.GetImplementation)
 
-		path := fmt.Sprintf("/repo/%s/file/read/?lineStart=%d&lineEnd=%d&filePath=%s/%s", tt.args.repoID, tt.args.lineStart, tt.args.lineEnd, tt.args.repoID, tt.args.filePath)
+		path := fmt.Sprintf("/repo/%s/file/read/?lineStart=%d&lineEnd=%d&filePath=%s", tt.args.repoID, tt.args.lineStart, tt.args.lineEnd, url.QueryEscape(tt.args.repoID+"/"+tt.args.filePath))
 
 		t.Run(tt.name, func(t *testing.T) {
 			req, err := http.NewRequest("GET", path, nil)
@@ -151,7 +241,7 @@ func Test_CodeSnippetController_GetImplementation(t *testing.T) {
 			router.ServeHTTP(resp, req)
 
 			if resp.Code != tt.expected.statusCode {
-				t.Error("Incorrect statusCode")
+				t.Errorf("Incorrect statusCode %d, expected %d", resp.Code, tt.expected.statusCode)
 			}
 
 			decoder := json.NewDecoder(resp.Body)
@@ -201,7 +291,6 @@ func getInterval(str string, start int, stop int) (interval string) {
 // setup Sets variables used for storing repository files and initialize the database for testing
 func setup() {
 	model.DB.DatabaseName = "test"
-	model.RepoPath = "/tmp/"
 
 	if err := model.DB.Init(); err != nil {
 		log.Fatalf("Could not initialize database, database error: %s", err.Error())
@@ -220,6 +309,19 @@ func setup() {
 		os.Exit(1)
 	}
 
+	// Files outside of the valid repository that requests must not reach.
+	os.Mkdir("/tmp/"+otherRepoID, os.ModePerm)
+	os.Mkdir("/tmp/"+validRepo.ID.Hex()+"/.git", os.ModePerm)
+	if err := ioutil.WriteFile("/tmp/"+otherRepoID+"/"+validTestFile.relativePath, d1, 0644); err != nil {
+		log.Printf("Could not setup test environment, error writing file: %s", err.Error())
+	}
+	if err := ioutil.WriteFile("/tmp/"+validRepo.ID.Hex()+"/.git/config", d1, 0644); err != nil {
+		log.Printf("Could not setup test environment, error writing file: %s", err.Error())
+	}
+	if err := os.Symlink("/tmp/"+otherRepoID+"/"+validTestFile.relativePath, "/tmp/"+validRepo.ID.Hex()+"/"+escapingLink); err != nil {
+		log.Printf("Could not setup test environment, error creating link: %s", err.Error())
+	}
+
 }
 
 // tearDown reverts changes done by setup
@@ -228,7 +330,11 @@ func tearDown() {
 		log.Println("Could not clean up test database")
 	}
 
-	if err := os.Remove("/tmp/" + validRepo.ID.Hex() + "/test.test"); err != nil {
+	if err := os.RemoveAll("/tmp/" + validRepo.ID.Hex()); err != nil {
 		log.Println("Could not clean up repository example file")
 	}
+
+	if err := os.RemoveAll("/tmp/" + otherRepoID); err != nil {
+		log.Println("Could not clean up other repository example file")
+	}
 }
@@ -0,0 +1,97 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// ExportController represents exports of parsed repositories to other tools.
+type ExportController struct {
+	Storage config.Storage // Where repositories are cloned to
+}
+
+/**
+* @api {GET} /repo/:repoId/export?format=:format Export the parsed repository as a graph.
+* @apiName Export Repository.
+* @apiGroup Export
+* @apiPermission analyst
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String="graphml","dot","gexf","csv"} [format=graphml] Format of the graph.
+*
+* @apiDescription Exports the structure of the parsed repository, files containing namespaces containing
+* classes containing functions, with the calls between functions, the includes between files and the
+* inheritance between classes. Nodes have a "kind", "label", "file" and "line", edges have a "kind"
+* of "contains", "calls", "includes" or "inherits". GraphML opens in yEd and Gephi, GEXF in Gephi
+* and DOT in Graphviz. CSV is a table of the edges with the labels, kinds and files of their nodes.
+*
+* @apiSuccessExample {text} Success-Response:
+* 	HTTP/1.1 200 OK
+*	digraph project {
+*		rankdir=LR;
+*		n0 [label="5c62d1904122c760dafe9341/src/main.cpp", shape=folder, kind="file"];
+*		n1 [label="main", shape=ellipse, kind="function"];
+*		n0 -> n1 [style=dotted, arrowhead=none, kind="contains"];
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'format'
+*	}
+ */
+
+// GetExport writes the graph of a parsed repository in the requested format.
+func (export ExportController) GetExport(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for export", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for export", packageName)
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+		user, _ := CurrentUser(r)
+
+		format := r.URL.Query().Get("format")
+		if len(format) == 0 {
+			format = model.ExportGraphML
+		}
+
+		exportFormat, ok := model.ExportFormats[format]
+		if !ok {
+			http.Error(w, "Invalid url parameter 'format'", http.StatusBadRequest)
+			return
+		}
+
+		exstRepo, ok := findRepo(w, export.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		var body bytes.Buffer
+		if err := model.BuildExportGraph(exstRepo.ParsedRepo).Write(format, &body); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to export repository: %s", packageName, err.Error())
+			recordAudit(user, clientIP(r), model.AuditExport, vars["repoId"], model.OutcomeFailure, err.Error())
+			return
+		}
+
+		recordAudit(user, clientIP(r), model.AuditExport, vars["repoId"], model.OutcomeSuccess, format)
+
+		http.Header.Add(w.Header(), "content-type", exportFormat.ContentType)
+		http.Header.Add(w.Header(), "Content-Disposition", "attachment; filename=\""+vars["repoId"]+exportFormat.Extension+"\"")
+
+		w.WriteHeader(http.StatusOK)
+		body.WriteTo(w)
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
@@ -0,0 +1,164 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// MembersController represents the users with a role in a repository.
+type MembersController struct {
+	Storage config.Storage // Where repositories are cloned to
+}
+
+/**
+* @api {GET,PUT} /repo/:repoId/members Get or set the members of a repository.
+* @apiName Handle Repository Members.
+* @apiGroup Repository
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {Object[]} members Users besides the owner with a role in the repository.
+* @apiParam {String} members.name Name of the user.
+* @apiParam {String="viewer","analyst","admin"} members.role Role of the user.
+*
+* @apiDescription Every member can list the members, only admins can replace them with a PUT request.
+* The owner is always admin. Viewers read snippets, parsed data and analysis results, analysts also
+* re-parse and export the repository, and admins also delete it and manage its members and configuration.
+*
+* @apiParamExample {json} Set members:
+*	{
+*		"members": [
+*			{"name": "bob", "role": "analyst"},
+*			{"name": "carol", "role": "viewer"}
+*		]
+*	}
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"owner": "alice",
+*		"members": [
+*			{"name": "bob", "role": "analyst"},
+*			{"name": "carol", "role": "viewer"}
+*		]
+*	}
+*
+* @apiErrorExample {text/plain} Not an admin.
+*	HTTP/1.1 403 Forbidden
+*	{
+*		Forbidden
+*	}
+*
+* @apiErrorExample {text/plain} Unknown user.
+*	HTTP/1.1 422 Unprocessable Entity
+*	{
+*		Unknown user: dave
+*	}
+ */
+
+// HandleMembers gets or sets the members of a repository.
+func (members MembersController) HandleMembers(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for repository members", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for repository members", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	exstRepo, ok := findRepo(w, members.Storage, mux.Vars(r)["repoId"])
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		respondMembers(w, exstRepo)
+
+	case "PUT":
+		var body struct {
+			Members []memberName `json:"members"`
+		}
+
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&body); err != nil {
+			http.Error(w, "Invalid json", http.StatusBadRequest)
+			util.TypeLogger.Warn("%s: Failed to decode Json: %s", packageName, err.Error())
+			return
+		}
+
+		exstRepo.Members = []model.MemberModel{}
+		for _, entry := range body.Members {
+			if !model.ValidRole(entry.Role) {
+				http.Error(w, model.ErrInvalidRole.Error(), http.StatusBadRequest)
+				return
+			}
+
+			member, err := model.GetUserByName(entry.Name)
+			if err == model.ErrInvalidCredentials {
+				http.Error(w, "Unknown user: "+entry.Name, http.StatusUnprocessableEntity)
+				return
+			}
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				util.TypeLogger.Error("%s: Failed to find user: %s", packageName, err.Error())
+				return
+			}
+
+			if !exstRepo.HasMember(member.ID) {
+				exstRepo.Members = append(exstRepo.Members, model.MemberModel{UserID: member.ID, Role: entry.Role})
+			}
+		}
+
+		if err := exstRepo.UpdateMembers(); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		respondMembers(w, exstRepo)
+
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+	}
+}
+
+// memberName is a member of a repository as shown to users.
+type memberName struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// respondMembers responds with the names of the owner and members of repo.
+func respondMembers(w http.ResponseWriter, repo model.RepoModel) {
+	names := []memberName{}
+	for _, entry := range repo.Members {
+		member, err := model.GetUserByID(entry.UserID)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to find user: %s", packageName, err.Error())
+			return
+		}
+		if member.ID.Valid() {
+			names = append(names, memberName{Name: member.Name, Role: entry.Role})
+		}
+	}
+
+	owner, err := model.GetUserByID(repo.Owner)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.TypeLogger.Error("%s: Failed to find user: %s", packageName, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"owner":   owner.Name,
+		"members": names,
+	})
+}
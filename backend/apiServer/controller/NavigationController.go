@@ -0,0 +1,237 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// NavigationController represents navigation between symbols of a repository.
+type NavigationController struct {
+	Storage config.Storage // Where repositories are cloned to
+}
+
+/**
+* @api {GET} /repo/:repoId/definition?filePath=:filePath&line=:line&column=:column Find the definition of a symbol.
+* @apiName Get Definition.
+* @apiGroup Navigation
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String} filePath The file the symbol is used in, starting with repoId as in the parsed file names.
+* @apiParam {Int} line Line of the identifier, starting at 1.
+* @apiParam {Int} column Column of any character of the identifier, starting at 1.
+*
+* @apiDescription Resolves the identifier at the position against the parsed functions,
+* classes, namespaces and variables of the repository and returns where it is declared.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c62d1904122c760dafe9341",
+*		"symbol": {
+*			"kind": "function",
+*			"name": "getName",
+*			"qualified_name": "Component::getName",
+*			"file_name": "5c62d1904122c760dafe9341/src/Component.cpp",
+*			"start_line": 17,
+*			"end_line": 28
+*		}
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'filePath'|'line'|'column'
+*	}
+*
+* @apiErrorExample {text/plain} No symbol at the position.
+*	HTTP/1.1 404 Not Found
+*	{
+*		No symbol at position
+*	}
+ */
+
+// GetDefinition finds the declaration of the symbol at a position.
+func (navigation NavigationController) GetDefinition(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for definition", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for definition", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+
+		exstRepo, ok := findRepo(w, navigation.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		filePath, line, column, err := parsePosition(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		symbol, err := exstRepo.FindDefinition(navigation.Storage, filePath, line, column)
+		if err != nil {
+			writeNavigationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     vars["repoId"],
+			"symbol": symbol,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /repo/:repoId/references?filePath=:filePath&line=:line&column=:column Find all references to a symbol.
+* @apiName Get References.
+* @apiGroup Navigation
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String} filePath The file the symbol is used in, starting with repoId as in the parsed file names.
+* @apiParam {Int} line Line of the identifier, starting at 1.
+* @apiParam {Int} column Column of any character of the identifier, starting at 1.
+*
+* @apiDescription Resolves the identifier at the position and lists every identifier in the
+* parsed files of the repository referring to the same symbol. Each reference is the
+* definition itself, a call of a function or another reference.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c62d1904122c760dafe9341",
+*		"symbol": {
+*			"kind": "function",
+*			"name": "getName",
+*			"qualified_name": "Component::getName",
+*			"file_name": "5c62d1904122c760dafe9341/src/Component.cpp",
+*			"start_line": 17,
+*			"end_line": 28
+*		},
+*		"references": [
+*			{
+*				"kind": "definition",
+*				"file_name": "5c62d1904122c760dafe9341/src/Component.cpp",
+*				"line": 17,
+*				"column": 24,
+*				"snippet": "std::string Component::getName(bool removeDigits)"
+*			},
+*			{
+*				"kind": "call",
+*				"file_name": "5c62d1904122c760dafe9341/src/main.cpp",
+*				"line": 12,
+*				"column": 25,
+*				"snippet": "std::cout << component.getName();"
+*			}
+*		]
+*	}
+*
+* @apiErrorExample {text/plain} Invalid parameters.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid url parameter 'filePath'|'line'|'column'
+*	}
+*
+* @apiErrorExample {text/plain} No symbol at the position.
+*	HTTP/1.1 404 Not Found
+*	{
+*		No symbol at position
+*	}
+ */
+
+// GetReferences finds every reference to the symbol at a position.
+func (navigation NavigationController) GetReferences(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for references", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for references", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+
+		exstRepo, ok := findRepo(w, navigation.Storage, vars["repoId"])
+		if !ok {
+			return
+		}
+
+		filePath, line, column, err := parsePosition(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		symbol, references, err := exstRepo.FindReferences(navigation.Storage, filePath, line, column)
+		if err != nil {
+			writeNavigationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":         vars["repoId"],
+			"symbol":     symbol,
+			"references": references,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// parsePosition reads the filePath, line and column url parameters.
+func parsePosition(r *http.Request) (filePath string, line int, column int, err error) {
+	query := r.URL.Query()
+
+	filePath = query.Get("filePath")
+	if len(filePath) == 0 {
+		return "", 0, 0, errors.New("Invalid url parameter 'filePath'")
+	}
+
+	line, err = strconv.Atoi(query.Get("line"))
+	if err != nil || line < 1 {
+		return "", 0, 0, errors.New("Invalid url parameter 'line'")
+	}
+
+	column, err = strconv.Atoi(query.Get("column"))
+	if err != nil || column < 1 {
+		return "", 0, 0, errors.New("Invalid url parameter 'column'")
+	}
+
+	return filePath, line, column, nil
+}
+
+// writeNavigationError responds with the status matching an error from resolving a symbol.
+func writeNavigationError(w http.ResponseWriter, err error) {
+	switch err {
+	case model.ErrNoSymbol:
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+	case model.ErrForbiddenPath, model.ErrFileNotFound:
+		writeSnippetError(w, err)
+
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.TypeLogger.Error("%s: Failed to resolve symbol: %s", packageName, err.Error())
+	}
+}
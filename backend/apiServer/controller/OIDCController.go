@@ -0,0 +1,182 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/oidc"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// oidcCookie holds the state, nonce and PKCE verifier of a login at the provider until its callback.
+const oidcCookie = "codevis_oidc"
+
+// oidcLoginDuration is how long users have to log in at the provider.
+const oidcLoginDuration = 10 * time.Minute
+
+// OIDCController represents logins with an OpenID Connect provider.
+// Provider users are users like local ones: they only get access to a repository by being its owner or
+// one of the members set with /repo/{repoId}/members. Groups and roles claimed by the provider are ignored.
+type OIDCController struct {
+	Provider   *oidc.Provider // Provider users log in with
+	AfterLogin string         // Where users are sent after logging in
+}
+
+/**
+* @api {GET} /auth/oidc/login Log in with the OpenID Connect provider.
+* @apiName OIDC Login.
+* @apiGroup Authentication
+* @apiPermission none
+*
+* @apiDescription Redirects the browser to the provider, which sends it back to /auth/oidc/callback.
+* Only available when the api server is configured with a provider.
+*
+* @apiSuccessExample {text/plain} Success-Response:
+* 	HTTP/1.1 302 Found
+*	Location: https://login.example.com/authorize?client_id=codevis&code_challenge=...&code_challenge_method=S256&...
+ */
+
+// Login redirects the user to the provider to log in.
+func (login OIDCController) Login(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for oidc login", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for oidc login", packageName)
+
+	if r.Method == "GET" {
+		request, err := oidc.NewAuthRequest()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to create login request: %s", packageName, err.Error())
+			return
+		}
+
+		value, err := json.Marshal(request)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to encode Json: %s", packageName, err.Error())
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcCookie,
+			Value:    base64.RawURLEncoding.EncodeToString(value),
+			Path:     "/auth/oidc",
+			MaxAge:   int(oidcLoginDuration.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		http.Redirect(w, r, login.Provider.AuthCodeURL(request), http.StatusFound)
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /auth/oidc/callback Finish logging in with the OpenID Connect provider.
+* @apiName OIDC Callback.
+* @apiGroup Authentication
+* @apiPermission none
+*
+* @apiParam {String} code Authorization code from the provider.
+* @apiParam {String} state State of the login.
+*
+* @apiDescription Redeems the code with the PKCE verifier of the login and verifies the id token
+* against the keys of the provider. The first login creates a user named after the "preferred_username"
+* or "email" claim, who is then given access to repositories like any other user. Sets the session
+* cookie like /auth/login and redirects to the frontend.
+*
+* @apiSuccessExample {text/plain} Success-Response:
+* 	HTTP/1.1 302 Found
+*	Set-Cookie: codevis_session=cv_...; Path=/; HttpOnly; SameSite=Lax
+*	Location: /
+*
+* @apiErrorExample {text/plain} Login failed.
+*	HTTP/1.1 401 Unauthorized
+*	{
+*		Login failed
+*	}
+ */
+
+// Callback logs in the user the provider sent back.
+func (login OIDCController) Callback(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for oidc callback", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for oidc callback", packageName)
+
+	if r.Method == "GET" {
+		// The login request is used once
+		http.SetCookie(w, &http.Cookie{Name: oidcCookie, Path: "/auth/oidc", MaxAge: -1, HttpOnly: true})
+
+		query := r.URL.Query()
+		if len(query.Get("error")) > 0 {
+			http.Error(w, "Login failed", http.StatusUnauthorized)
+			util.TypeLogger.Info("%s: Provider refused login: %s", packageName, query.Get("error"))
+			return
+		}
+
+		request, ok := oidcRequest(r)
+		if !ok || subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(request.State)) != 1 {
+			http.Error(w, "Login failed", http.StatusUnauthorized)
+			util.TypeLogger.Warn("%s: Received oidc callback with invalid state", packageName)
+			return
+		}
+
+		claims, err := login.Provider.Exchange(r.Context(), query.Get("code"), request)
+		if err != nil {
+			http.Error(w, "Login failed", http.StatusUnauthorized)
+			util.TypeLogger.Warn("%s: Failed oidc login: %s", packageName, err.Error())
+			return
+		}
+
+		email := ""
+		if claims.EmailVerified {
+			email = claims.Email
+		}
+
+		user, err := model.UserForSubject(login.Provider.Issuer(), claims.Subject, claims.PreferredUsername, email)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to find user for provider: %s", packageName, err.Error())
+			return
+		}
+
+		if !startSession(w, r, user) {
+			return
+		}
+
+		http.Redirect(w, r, login.AfterLogin, http.StatusFound)
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// oidcRequest reads the login request stored by Login from the cookie of r.
+func oidcRequest(r *http.Request) (request oidc.AuthRequest, ok bool) {
+	cookie, err := r.Cookie(oidcCookie)
+	if err != nil {
+		return request, false
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return request, false
+	}
+
+	if err := json.Unmarshal(value, &request); err != nil || len(request.State) == 0 {
+		return request, false
+	}
+
+	return request, true
+}
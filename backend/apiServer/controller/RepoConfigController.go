@@ -0,0 +1,153 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// RepoConfigController represents the analysis configuration of a repository.
+type RepoConfigController struct {
+	Storage config.Storage // Where repositories are cloned to
+}
+
+/**
+* @api {GET} /repo/:repoId/config Get the analysis configuration of a repository.
+* @apiName Get Repository Configuration.
+* @apiGroup Repository
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+*
+* @apiDescription Returns the configuration used when parsing and analysing the repository.
+* It is made from the server defaults, the ".codevis.yml" file in the repository root
+* and the configuration set through this endpoint, the latter taking precedence.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"effective": {
+*			"languages": {".cpp": "cpp", ".h": "cpp", ".hpp": "cpp", ".java": "java"},
+*			"exclude": ["third_party"],
+*			"include_roots": ["include"],
+*			"entry_points": ["main"],
+*			"thresholds": {
+*				"function_lines": 60,
+*				"parameters": 5,
+*				"public_members": 20,
+*				"namespace_depth": 4
+*			}
+*		},
+*		"override": {
+*			"languages": {".h": "cpp"},
+*			"thresholds": {}
+*		}
+*	}
+*
+* @apiErrorExample {text/plain} Unknown repository.
+*	HTTP/1.1 404 Not Found
+*	{
+*		Not Found
+*	}
+ */
+
+/**
+* @api {PUT} /repo/:repoId/config Set the analysis configuration of a repository.
+* @apiName Set Repository Configuration.
+* @apiGroup Repository
+* @apiPermission admin
+*
+* @apiParam {String} repoId Id of submitted git repository.
+*
+* @apiDescription Stores a configuration overriding ".codevis.yml", using the same fields.
+* A DELETE request removes the stored configuration. The change is used the next time
+* the repository is parsed or analysed.
+*
+* @apiParamExample {json} Set configuration:
+*	{
+*		"languages": {".h": "cpp"},
+*		"exclude": ["third_party", "build/"]
+*	}
+*
+* @apiErrorExample {text/plain} Invalid configuration.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Unsupported language: python
+*	}
+ */
+
+// HandleConfig gets, sets or removes the api configuration of a repository.
+func (repoConfig RepoConfigController) HandleConfig(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for repository configuration", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for repository configuration", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	vars := mux.Vars(r)
+
+	exstRepo, err := model.RepoModel{}.GetRepoByID(repoConfig.Storage, vars["repoId"])
+	if err != nil || !exstRepo.ID.Valid() {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		util.TypeLogger.Warn("%s: Failed to find repository: %s", packageName, vars["repoId"])
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		effective, err := exstRepo.GetConfig(repoConfig.Storage)
+		if err != nil {
+			http.Error(w, "Invalid "+model.RepoConfigFile+": "+err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"effective": effective,
+			"override":  exstRepo.Config,
+		})
+
+	case "PUT":
+		var config model.RepoConfig
+
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			http.Error(w, "Invalid json", http.StatusBadRequest)
+			util.TypeLogger.Warn("%s: Failed to decode Json: %s", packageName, err.Error())
+			return
+		}
+
+		if err := config.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		exstRepo.Config = &config
+		if err := exstRepo.UpdateConfig(); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(config)
+
+	case "DELETE":
+		exstRepo.Config = nil
+		if err := exstRepo.UpdateConfig(); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+	}
+}
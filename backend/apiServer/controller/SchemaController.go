@@ -0,0 +1,63 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// SchemaController represents the schema of parsed repositories.
+type SchemaController struct {
+}
+
+/**
+* @api {GET} /schema Get the JSON Schema of parse results.
+* @apiName Get Schema.
+* @apiGroup Schema
+* @apiPermission none
+*
+* @apiDescription Returns the JSON Schema, draft-07, of the output of the java parser for one file.
+* The stored "parsedrepo" of a repository is described by the "ProjectModel" definition, with
+* "schema_version" telling which version of the schema it follows. Properties marked "readOnly"
+* are set by the api server after parsing. The same schema is in backend/schema/parse-result.schema.json.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"$schema": "http://json-schema.org/draft-07/schema#",
+*		"$id": "https://github.com/zohaib194/CodebaseVisualizer3D/schema/parse-result.schema.json",
+*		"$comment": "Schema version 2",
+*		"$ref": "#/definitions/FileWrapperModel",
+*		"title": "CodebaseVisualizer3D parse result",
+*		"definitions": {
+*			"FileWrapperModel": {
+*				"description": "Output of the java parser for one file",
+*				"type": "object",
+*				"properties": {"file": {"$ref": "#/definitions/FileModel"}},
+*				"additionalProperties": false
+*			}
+*		}
+*	}
+ */
+
+// GetSchema responds with the JSON Schema of parse results.
+func (schema SchemaController) GetSchema(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for schema", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for schema", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/schema+json")
+
+	if r.Method == "GET" {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(model.ParseResultSchema())
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
@@ -0,0 +1,235 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// SearchController represents searches through parsed repositories.
+type SearchController struct {
+	Storage config.Storage // Where repositories are cloned to
+}
+
+/**
+* @api {GET} /repo/:repoId/search?q=:query&mode=:mode&kind=:kind&limit=:limit Search symbols and content of a repository.
+* @apiName Search Repository.
+* @apiGroup Search
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String} query Text to search for.
+* @apiParam {String="prefix","fuzzy","regex"} [mode=prefix] How the query is matched against names and content.
+* @apiParam {String} [kind] Comma separated result kinds: function, class, namespace, variable, parameter, content.
+* @apiParam {Int} [limit=50] Maximum number of results, at most 500.
+*
+* @apiDescription Searches function names and declarator ids, class names, namespaces,
+* variables and the content of the files of a parsed repository. Results are ranked
+* with symbols before file content and carry the file and line range they are found at.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c62d1904122c760dafe9341",
+*		"results": [
+*			{
+*				"kind": "function",
+*				"name": "getName",
+*				"qualified_name": "Component::getName",
+*				"file_name": "5c62d1904122c760dafe9341/src/Component.cpp",
+*				"start_line": 17,
+*				"end_line": 28,
+*				"score": 0.74
+*			},
+*			{
+*				"kind": "content",
+*				"name": "getName",
+*				"file_name": "5c62d1904122c760dafe9341/src/main.cpp",
+*				"start_line": 12,
+*				"end_line": 12,
+*				"score": 0.3,
+*				"snippet": "std::cout << component.getName();"
+*			}
+*		]
+*	}
+*
+* @apiErrorExample {text/plain} Invalid query.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid search query
+*	}
+ */
+
+// SearchRepo searches one repository based upon query parameters.
+func (search SearchController) SearchRepo(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for repository search", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for repository search", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		vars := mux.Vars(r)
+
+		query, err := parseSearchQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		exstRepo, err := model.RepoModel{}.GetRepoByID(search.Storage, vars["repoId"])
+		if err != nil || !exstRepo.ID.Valid() {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			util.TypeLogger.Warn("%s: Failed to find repository: %s", packageName, vars["repoId"])
+			return
+		}
+
+		results, err := exstRepo.GetSearchIndex(search.Storage).Search(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      vars["repoId"],
+			"results": results,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /search?q=:query&mode=:mode&kind=:kind&limit=:limit Search symbols across all repositories of the user.
+* @apiName Search Repositories.
+* @apiGroup Search
+* @apiPermission user
+*
+* @apiParam {String} query Text to search for.
+* @apiParam {String="prefix","fuzzy","regex"} [mode=prefix] How the query is matched against names.
+* @apiParam {String} [kind] Comma separated symbol kinds: function, class, namespace, variable, parameter.
+* @apiParam {Int} [limit=50] Maximum number of results per repository, at most 500.
+*
+* @apiDescription Searches the symbols of every parsed repository at once, to find where
+* a shared interface or function name is implemented. File content is not searched.
+* Results are grouped by repository, the group with the best match first.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"repos": [
+*			{
+*				"id": "5c768dae4122c7135145a1a3",
+*				"uri": "https://github.com/<USER_NAME>/ECS.git",
+*				"results": [
+*					{
+*						"kind": "class",
+*						"name": "Component",
+*						"qualified_name": "ecs::Component",
+*						"file_name": "5c768dae4122c7135145a1a3/src/Component.hpp",
+*						"start_line": 8,
+*						"end_line": 40,
+*						"score": 1
+*					}
+*				]
+*			},
+*			{
+*				"id": "5c7684364122c703a493e292",
+*				"uri": "https://github.com/<USER_NAME>/imgui.git",
+*				"results": [
+*					{
+*						"kind": "function",
+*						"name": "ComponentCount",
+*						"qualified_name": "ImGui::ComponentCount",
+*						"file_name": "5c7684364122c703a493e292/imgui.cpp",
+*						"start_line": 102,
+*						"end_line": 110,
+*						"score": 0.78
+*					}
+*				]
+*			}
+*		]
+*	}
+*
+* @apiErrorExample {text/plain} Invalid query.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		Invalid search query
+*	}
+ */
+
+// SearchAll searches the symbols of all repositories of the authenticated user based upon query parameters.
+func (search SearchController) SearchAll(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for search across repositories", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for search across repositories", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		query, err := parseSearchQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, _ := CurrentUser(r)
+		groups, err := model.SearchAllRepos(search.Storage, query, user.ID)
+		if err == model.ErrInvalidSearch {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to search repositories: %s", packageName, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"repos": groups,
+		})
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// parseSearchQuery reads the query parameters shared by the search endpoints.
+func parseSearchQuery(r *http.Request) (query model.SearchQuery, err error) {
+	values := r.URL.Query()
+
+	query.Text = values.Get("q")
+	query.Mode = values.Get("mode")
+
+	if kinds := splitQueryList(values["kind"]); len(kinds) > 0 {
+		query.Kinds = make(map[string]bool)
+		for _, kind := range kinds {
+			query.Kinds[kind] = true
+		}
+	}
+
+	if limit := values.Get("limit"); len(limit) > 0 {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 {
+			return query, model.ErrInvalidSearch
+		}
+	}
+
+	if len(query.Text) == 0 {
+		return query, model.ErrInvalidSearch
+	}
+
+	return query, nil
+}
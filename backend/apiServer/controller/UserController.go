@@ -0,0 +1,380 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// UserController represents user accounts, their logins and api tokens.
+type UserController struct {
+}
+
+// credentials is the body of register and login requests.
+type credentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+/**
+* @api {POST} /auth/register Create a user account.
+* @apiName Register.
+* @apiGroup Authentication
+* @apiPermission none
+*
+* @apiParam {String} name User name of 3 to 64 letters, digits, '.', '_' or '-'.
+* @apiParam {String} password Password of at least 8 characters.
+*
+* @apiParamExample {json} Request-Example:
+*	{
+*		"name": "alice",
+*		"password": "correct horse battery"
+*	}
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 201 Created
+*	{
+*		"id": "5c8f6e2b4122c70f6a0ab1c2",
+*		"name": "alice",
+*		"created": "2019-03-18T10:12:43Z"
+*	}
+*
+* @apiErrorExample {text/plain} Name taken.
+*	HTTP/1.1 409 Conflict
+*	{
+*		User already exists
+*	}
+ */
+
+// Register creates a user account.
+func (user UserController) Register(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for register", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for register", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "POST" {
+		body, ok := decodeCredentials(w, r)
+		if !ok {
+			return
+		}
+
+		created, err := model.NewUser(body.Name, body.Password)
+		switch err {
+		case nil:
+		case model.ErrInvalidUserName, model.ErrInvalidPassword:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case model.ErrUserExists:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to create user: %s", packageName, err.Error())
+			return
+		}
+
+		util.TypeLogger.Info("%s: Created user %s", packageName, created.Name)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	} else { // if not POST request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {POST} /auth/login Log in with name and password.
+* @apiName Login.
+* @apiGroup Authentication
+* @apiPermission none
+*
+* @apiParam {String} name User name.
+* @apiParam {String} password Password.
+*
+* @apiDescription Starts a session lasting a week, kept in the "codevis_session" cookie.
+* Browsers send the cookie with requests and when opening WebSockets.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	Set-Cookie: codevis_session=cv_...; Path=/; HttpOnly; SameSite=Lax
+*	{
+*		"id": "5c8f6e2b4122c70f6a0ab1c2",
+*		"name": "alice",
+*		"created": "2019-03-18T10:12:43Z"
+*	}
+*
+* @apiErrorExample {text/plain} Wrong name or password.
+*	HTTP/1.1 401 Unauthorized
+*	{
+*		Invalid credentials
+*	}
+ */
+
+// Login starts a session for a user with valid credentials.
+func (user UserController) Login(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for login", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for login", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "POST" {
+		body, ok := decodeCredentials(w, r)
+		if !ok {
+			return
+		}
+
+		authenticated, err := model.AuthenticateUser(body.Name, body.Password)
+		if err == model.ErrInvalidCredentials {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			util.TypeLogger.Info("%s: Failed login for %s", packageName, body.Name)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to authenticate user: %s", packageName, err.Error())
+			return
+		}
+
+		if !startSession(w, r, authenticated) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(authenticated)
+
+	} else { // if not POST request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {POST} /auth/logout End the session of the request.
+* @apiName Logout.
+* @apiGroup Authentication
+* @apiPermission user
+*
+* @apiSuccessExample {text/plain} Success-Response:
+* 	HTTP/1.1 204 No Content
+ */
+
+// Logout ends the session of the request and clears its cookie.
+func (user UserController) Logout(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for logout", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for logout", packageName)
+
+	if r.Method == "POST" {
+		if cookie, err := r.Cookie(SessionCookie); err == nil {
+			if err := model.RevokeSecret(cookie.Value); err != nil {
+				util.TypeLogger.Error("%s: Failed to revoke session: %s", packageName, err.Error())
+			}
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: SessionCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+		w.WriteHeader(http.StatusNoContent)
+
+	} else { // if not POST request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /auth/me Get the authenticated user.
+* @apiName Get Current User.
+* @apiGroup Authentication
+* @apiPermission user
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c8f6e2b4122c70f6a0ab1c2",
+*		"name": "alice",
+*		"created": "2019-03-18T10:12:43Z"
+*	}
+ */
+
+// GetMe responds with the authenticated user.
+func (user UserController) GetMe(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for current user", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for current user", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		current, _ := CurrentUser(r)
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(current)
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET,POST} /auth/tokens List or create api tokens.
+* @apiName Handle Tokens.
+* @apiGroup Authentication
+* @apiPermission user
+*
+* @apiParam {String} name Name to recognize the token by, when creating one.
+*
+* @apiDescription Api tokens authenticate scripts with "Authorization: Bearer <token>" and do not expire.
+* The token itself is only returned when it is created.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 201 Created
+*	{
+*		"token": "cv_4f9c2d...",
+*		"info": {
+*			"id": "5c8f70a14122c70f6a0ab1c5",
+*			"kind": "api",
+*			"name": "ci",
+*			"created": "2019-03-18T10:21:37Z"
+*		}
+*	}
+ */
+
+// HandleTokens lists the api tokens of the authenticated user, or creates one.
+func (user UserController) HandleTokens(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for tokens", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for tokens", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	current, _ := CurrentUser(r)
+
+	switch r.Method {
+	case "GET":
+		tokens, err := current.Tokens()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to list tokens: %s", packageName, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tokens": tokens,
+		})
+
+	case "POST":
+		var body struct {
+			Name string `json:"name"`
+		}
+
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&body); err != nil || len(body.Name) == 0 {
+			http.Error(w, "Invalid json", http.StatusBadRequest)
+			return
+		}
+
+		secret, token, err := current.NewToken(model.TokenAPI, body.Name, 0)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to create token: %s", packageName, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token": secret,
+			"info":  token,
+		})
+
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+	}
+}
+
+/**
+* @api {DELETE} /auth/tokens/:tokenId Revoke an api token.
+* @apiName Revoke Token.
+* @apiGroup Authentication
+* @apiPermission user
+*
+* @apiParam {String} tokenId Id of the token.
+*
+* @apiSuccessExample {text/plain} Success-Response:
+* 	HTTP/1.1 204 No Content
+ */
+
+// RevokeToken deletes an api token of the authenticated user.
+func (user UserController) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for token revocation", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for token revocation", packageName)
+
+	if r.Method == "DELETE" {
+		current, _ := CurrentUser(r)
+
+		err := current.RevokeToken(mux.Vars(r)["tokenId"])
+		if err == model.ErrTokenNotFound {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to revoke token: %s", packageName, err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	} else { // if not DELETE request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// decodeCredentials reads the name and password in the body of r, responding with an error if it is invalid.
+func decodeCredentials(w http.ResponseWriter, r *http.Request) (body credentials, ok bool) {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil || len(body.Name) == 0 || len(body.Password) == 0 {
+		http.Error(w, "Invalid json", http.StatusBadRequest)
+		return body, false
+	}
+
+	return body, true
+}
+
+// startSession creates a session for user and sets its cookie, responding with an error if it fails.
+func startSession(w http.ResponseWriter, r *http.Request, user model.UserModel) bool {
+	secret, token, err := user.NewToken(model.TokenSession, r.UserAgent(), model.SessionDuration)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.TypeLogger.Error("%s: Failed to create session: %s", packageName, err.Error())
+		return false
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    secret,
+		Path:     "/",
+		Expires:  token.Expires,
+		MaxAge:   int(time.Until(token.Expires).Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return true
+}
@@ -0,0 +1,121 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// SessionCookie is the name of the cookie holding the session token of a login.
+const SessionCookie = "codevis_session"
+
+// contextKey is the type of the request context values set by the middlewares.
+type contextKey string
+
+// userContextKey holds the authenticated user of a request.
+const userContextKey = contextKey("user")
+
+// CurrentUser returns the user authenticated by RequireUser for r.
+func CurrentUser(r *http.Request) (model.UserModel, bool) {
+	user, ok := r.Context().Value(userContextKey).(model.UserModel)
+	return user, ok
+}
+
+// withUser returns r carrying user as the authenticated user.
+func withUser(r *http.Request, user model.UserModel) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+}
+
+// requestToken returns the token of r from the "Authorization: Bearer" header, or else from the session cookie.
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); len(header) > 0 {
+		if fields := strings.Fields(header); len(fields) == 2 && strings.EqualFold(fields[0], "Bearer") {
+			return fields[1]
+		}
+		return ""
+	}
+
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
+
+// RequireUser is a middleware responding with 401 Unauthorized to requests without a valid api or session token.
+// The user is available to the next handler through CurrentUser.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, _, err := model.UserByToken(requestToken(r))
+		if err == model.ErrInvalidCredentials {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="codevis"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			util.TypeLogger.Info("%s: Rejected unauthenticated request for %s", packageName, r.URL.Path)
+			return
+		}
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			util.TypeLogger.Error("%s: Failed to authenticate request: %s", packageName, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, withUser(r, user))
+	})
+}
+
+// RequireRole returns a middleware for routes with a "repoId" letting through users from RequireUser
+// with at least role in the repository, cloned to storage. Users without a role get 404 Not Found, so repositories of
+// other teams can not be told apart from missing ones, and users with a lower role get 403 Forbidden.
+// Every decision is logged with the user, role and request, and denials are added to the audit log.
+func RequireRole(storage config.Storage, role string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			repoID, ok := mux.Vars(r)["repoId"]
+			if !ok || r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := CurrentUser(r)
+			if !ok {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			exstRepo, ok := findRepo(w, storage, repoID)
+			if !ok {
+				return
+			}
+
+			userRole, ok := exstRepo.RoleOf(user.ID)
+			if !ok {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				util.TypeLogger.Warn("%s: Denied %s %s to %s without role in repository %s", packageName, r.Method, r.URL.Path, user.Name, repoID)
+				recordAudit(user, clientIP(r), model.AuditAccess, repoID, model.OutcomeDenied, r.Method+" "+r.URL.Path+" without role")
+				return
+			}
+
+			if !model.RoleAllows(userRole, role) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				util.TypeLogger.Warn("%s: Denied %s %s to %s with role %s, requires %s", packageName, r.Method, r.URL.Path, user.Name, userRole, role)
+				recordAudit(user, clientIP(r), model.AuditAccess, repoID, model.OutcomeDenied, r.Method+" "+r.URL.Path+" as "+userRole+", requires "+role)
+				return
+			}
+
+			util.TypeLogger.Info("%s: Allowed %s %s to %s with role %s", packageName, r.Method, r.URL.Path, user.Name, userRole)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
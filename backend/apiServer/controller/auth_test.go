@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_requestToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		want   string
+	}{
+		{name: "Bearer header", header: "Bearer cv_abc", want: "cv_abc"},
+		{name: "Lowercase scheme", header: "bearer cv_abc", want: "cv_abc"},
+		{name: "Session cookie", cookie: "cv_def", want: "cv_def"},
+		{name: "Header before cookie", header: "Bearer cv_abc", cookie: "cv_def", want: "cv_abc"},
+		{name: "Other scheme", header: "Basic YWxpY2U6cGFzcw==", cookie: "cv_def", want: ""},
+		{name: "No token", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/repo/list", nil)
+			if len(tt.header) > 0 {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if len(tt.cookie) > 0 {
+				r.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.cookie})
+			}
+
+			if got := requestToken(r); got != tt.want {
+				t.Errorf("requestToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
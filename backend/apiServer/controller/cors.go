@@ -0,0 +1,109 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Methods and headers websites may use in cross origin requests.
+const (
+	corsMethods       = "GET, POST, PUT, DELETE"
+	corsHeaders       = "Authorization, Content-Type"
+	corsExposeHeaders = "Retry-After"
+	corsMaxAge        = "600"
+)
+
+// CORS returns a middleware applying the cross origin policy of origins, the origins of websites
+// allowed to use the api from a browser like "https://codevis.example.com". "*" allows every origin
+// but without the credentials of the user. Preflight requests are answered here, and requests from
+// other origins not in origins are refused with 403 Forbidden.
+func CORS(origins []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Header.Add(w.Header(), "Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if len(origin) == 0 || sameOrigin(r, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, withCredentials := originAllowed(origin, origins)
+			if !allowed {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				util.TypeLogger.Warn("%s: Refused %s %s from origin %s", packageName, r.Method, r.URL.Path, origin)
+				return
+			}
+
+			if withCredentials {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+
+			// Preflight request, asking what the actual request may do
+			if r.Method == "OPTIONS" && len(r.Header.Get("Access-Control-Request-Method")) > 0 {
+				http.Header.Add(w.Header(), "Vary", "Access-Control-Request-Method")
+				http.Header.Add(w.Header(), "Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+				w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkOrigin tells if a websocket may be opened for r, by the same policy as CORS with origins.
+func checkOrigin(r *http.Request, origins []string) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 || sameOrigin(r, origin) {
+		return true
+	}
+
+	// Browsers send cookies with websockets to every origin, so only listed origins may open them
+	allowed, withCredentials := originAllowed(origin, origins)
+	if !allowed || !withCredentials {
+		util.TypeLogger.Warn("%s: Refused websocket from origin %s", packageName, origin)
+		return false
+	}
+
+	return true
+}
+
+// originAllowed tells if origin is in origins, and if it may send the credentials of the user.
+func originAllowed(origin string, origins []string) (allowed bool, withCredentials bool) {
+	for _, allowedOrigin := range origins {
+		if allowedOrigin == "*" {
+			allowed = true
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(allowedOrigin, "/"), origin) {
+			return true, true
+		}
+	}
+
+	return allowed, false
+}
+
+// sameOrigin tells if origin is the host r was sent to.
+func sameOrigin(r *http.Request, origin string) bool {
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(originURL.Host, r.Host)
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	handler := CORS([]string{"https://codevis.example.com/"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		wantStatus  int
+		wantOrigin  string
+		wantMethods bool
+	}{
+		{name: "No origin", method: "GET", wantStatus: http.StatusOK},
+		{name: "Same origin", method: "POST", origin: "http://api.example.com", wantStatus: http.StatusOK},
+		{name: "Allowed origin", method: "GET", origin: "https://codevis.example.com", wantStatus: http.StatusOK, wantOrigin: "https://codevis.example.com"},
+		{name: "Other origin", method: "POST", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+		{name: "Preflight allowed", method: "OPTIONS", origin: "https://codevis.example.com", wantStatus: http.StatusNoContent, wantOrigin: "https://codevis.example.com", wantMethods: true},
+		{name: "Preflight other", method: "OPTIONS", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://api.example.com/repo/list", nil)
+			if len(tt.origin) > 0 {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == "OPTIONS" {
+				r.Header.Set("Access-Control-Request-Method", "DELETE")
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := len(w.Header().Get("Access-Control-Allow-Methods")) > 0; got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods set = %v, want %v", got, tt.wantMethods)
+			}
+		})
+	}
+}
+
+func Test_checkOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{name: "No origin", want: true},
+		{name: "Same origin", origin: "http://api.example.com", want: true},
+		{name: "Listed origin", allowed: []string{"https://codevis.example.com"}, origin: "https://CODEVIS.example.com", want: true},
+		{name: "Other origin", allowed: []string{"https://codevis.example.com"}, origin: "https://evil.example.com", want: false},
+		{name: "Wildcard", allowed: []string{"*"}, origin: "https://evil.example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://api.example.com/repo/add", nil)
+			if len(tt.origin) > 0 {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := checkOrigin(r, tt.allowed); got != tt.want {
+				t.Errorf("checkOrigin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
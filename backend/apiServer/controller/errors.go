@@ -0,0 +1,86 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// ErrorResponse is the body of every error response of the versioned api.
+type ErrorResponse struct {
+	Error *model.ErrorModel `json:"error"`
+}
+
+// writeError responds with status and message in the error schema of the versioned api.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: model.NewError(status, message)}); err != nil {
+		util.TypeLogger.Error("%s: Failed to encode Json: %s", packageName, err.Error())
+	}
+}
+
+// JSONErrors is a middleware for the versioned api rewriting the plain text errors of http.Error,
+// written by the middlewares and handlers shared with the unversioned api, into the error schema.
+func JSONErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &errorWriter{ResponseWriter: w}
+		next.ServeHTTP(writer, r)
+		writer.flush()
+	})
+}
+
+// NotFound responds to requests for routes of the versioned api that do not exist.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Warn("%s: Received request for unknown route %s %s", packageName, r.Method, r.URL.Path)
+	writeError(w, http.StatusNotFound, "No route "+r.URL.Path)
+}
+
+// MethodNotAllowed responds to requests with methods a route of the versioned api does not support.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+	writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+}
+
+// errorWriter holds back plain text errors to write them in the error schema once the handler is done.
+type errorWriter struct {
+	http.ResponseWriter
+	status  int          // Status of the plain text error held back, 0 if there is none
+	message bytes.Buffer // Text of the plain text error
+}
+
+// WriteHeader holds back plain text errors and writes any other status.
+func (writer *errorWriter) WriteHeader(status int) {
+	if status >= http.StatusBadRequest && strings.HasPrefix(writer.Header().Get("Content-Type"), "text/plain") {
+		writer.status = status
+		return
+	}
+
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+// Write collects the text of a plain text error, and writes anything else.
+func (writer *errorWriter) Write(content []byte) (int, error) {
+	if writer.status != 0 {
+		return writer.message.Write(content)
+	}
+
+	return writer.ResponseWriter.Write(content)
+}
+
+// flush writes the plain text error held back in the error schema.
+func (writer *errorWriter) flush() {
+	if writer.status == 0 {
+		return
+	}
+
+	writeError(writer.ResponseWriter, writer.status, strings.TrimSpace(writer.message.String()))
+}
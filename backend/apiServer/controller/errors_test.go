@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+)
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantStatus  int
+		wantMessage string
+		wantBody    string
+	}{
+		{
+			name: "Plain text error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Invalid url parameter 'format'", http.StatusBadRequest)
+			},
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Invalid url parameter 'format'",
+		},
+		{
+			name: "Rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "12")
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			},
+			wantStatus:  http.StatusTooManyRequests,
+			wantMessage: "Too Many Requests",
+		},
+		{
+			name: "Json error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, http.StatusConflict, "Repository is not parsed, start a parse job first")
+			},
+			wantStatus:  http.StatusConflict,
+			wantMessage: "Repository is not parsed, start a parse job first",
+		},
+		{
+			name: "Success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("digraph project {}"))
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "digraph project {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			JSONErrors(tt.handler).ServeHTTP(w, httptest.NewRequest("GET", APIPrefix+"/repos", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if len(tt.wantMessage) == 0 {
+				if got := w.Body.String(); got != tt.wantBody {
+					t.Errorf("body = %q, want %q", got, tt.wantBody)
+				}
+				return
+			}
+
+			var response ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("body is not an error response: %v", err)
+			}
+			want := model.NewError(tt.wantStatus, tt.wantMessage)
+			if response.Error == nil || *response.Error != *want {
+				t.Errorf("error = %+v, want %+v", response.Error, want)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+		})
+	}
+}
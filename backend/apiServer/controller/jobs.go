@@ -0,0 +1,557 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// APIPrefix is the path the versioned api is served under.
+const APIPrefix = "/api/v1"
+
+// JobController represents the clones and parses running in the background for the versioned api.
+type JobController struct{}
+
+// JobResponse is a job with links to follow it.
+type JobResponse struct {
+	model.JobModel
+	Links map[string]string `json:"links"` // "self" for the job, "project" for the parsed repository once known
+}
+
+/**
+* @api {POST} /api/v1/repos Add a git repository and parse it in the background.
+* @apiName Add repository job.
+* @apiGroup Jobs
+* @apiPermission user
+*
+* @apiParam {String} uri URI to git repository.
+*
+* @apiDescription REST equivalent of /repo/add followed by /repo/:id/initial/ for scripts.
+* Responds with 202 Accepted and a job in the "Location" header, poll it with /api/v1/jobs/:jobId
+* until its status is "Done", "Failed" or "Interrupted". Once done, the parsed repository is read
+* with /api/v1/repos/:repoId/project. Clones and parses share their slots and queues with the
+* websockets, which remain available to stream the progress.
+* Every user clones their own copy. If the user already added the repository, the job fails with
+* status 409 and contains its id. Server admins adding a repository added before there were users
+* become its owner and get the same failed job.
+*
+* @apiParamExample {json} Add repository:
+*	{
+*		"uri": "https://github.com/zohaib194/CodebaseVisualizer3D.git"
+*	}
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 202 Accepted
+*	Location: /api/v1/jobs/5c8a0f6b4122c7135145a1a5
+*	{
+*		"id": "5c8a0f6b4122c7135145a1a5",
+*		"kind": "add",
+*		"status": "Queued",
+*		"parsedFileCount": 0,
+*		"skippedFileCount": 0,
+*		"fileCount": 0,
+*		"created": "2019-03-14T08:15:07Z",
+*		"updated": "2019-03-14T08:15:07Z",
+*		"links": {
+*			"self": "/api/v1/jobs/5c8a0f6b4122c7135145a1a5"
+*		}
+*	}
+*
+* @apiErrorExample {json} Invalid git URI.
+*	HTTP/1.1 400 Bad Request
+*	{
+*		"error": {
+*			"status": 400,
+*			"title": "Bad Request",
+*			"message": "Expected URI to git repository"
+*		}
+*	}
+*
+* @apiErrorExample {json} Too many clones waiting.
+*	HTTP/1.1 429 Too Many Requests
+*	Retry-After: 30
+*	{
+*		"error": {
+*			"status": 429,
+*			"title": "Too Many Requests",
+*			"message": "Too many requests, try again later"
+*		}
+*	}
+ */
+
+// AddRepo starts a job cloning the git repository in the posted json and parsing it.
+func (repo RepoController) AddRepo(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for new repo job", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for new repo job", packageName)
+
+	if r.Method == "POST" {
+		user, _ := CurrentUser(r)
+
+		var postData map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&postData); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid json")
+			util.TypeLogger.Error("%s: Failed to decode Json: %s", packageName, err.Error())
+			return
+		}
+
+		// Check that valid uri is given and that it is a .git
+		if isValid, err := validateURI(postData["uri"],
+			func(url string) (isValid bool, err error) { return regexp.Match(`\.git$`, []byte(url)) }); !isValid || (err != nil) {
+			writeError(w, http.StatusBadRequest, "Expected URI to git repository")
+			util.TypeLogger.Error("%s: Received invalid URI to git repository", packageName)
+			return
+		}
+
+		// Server admins re-adding a repository added before there were users claim it
+		if claimedID := repo.claimUnownedRepo(r, postData["uri"], user); len(claimedID) > 0 {
+			job := model.NewJob(model.JobAdd, claimedID, user.ID)
+			failJob(job.ID, http.StatusConflict, "Repository already exists")
+			job, _ = model.GetJob(job.ID)
+			writeJob(w, http.StatusAccepted, job)
+			return
+		}
+
+		ready, position, err := repo.CloneSlots.Acquire()
+		if err == util.ErrQueueFull {
+			writeQueueFull(w)
+			util.TypeLogger.Warn("%s: Rejected new repo job, queue is full", packageName)
+			return
+		}
+
+		job := model.NewJob(model.JobAdd, "", user.ID)
+		go repo.runAdd(user, clientIP(r), job.ID, postData["uri"], ready, position)
+
+		writeJob(w, http.StatusAccepted, job)
+
+	} else { // if not POST request
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {POST} /api/v1/repos/:repoId/parse Parse a repository again in the background.
+* @apiName Parse repository job.
+* @apiGroup Jobs
+* @apiPermission analyst
+*
+* @apiParam {String} repoId Id of submitted git repository.
+* @apiParam {String} [include] Comma separated globs, only files matching one of them are parsed.
+* @apiParam {String} [exclude] Comma separated globs for files and directories that are skipped.
+*
+* @apiDescription REST equivalent of /repo/:id/reparse/. Responds with 202 Accepted and a job
+* to poll like the one of POST /api/v1/repos.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 202 Accepted
+*	Location: /api/v1/jobs/5c8a10214122c7135145a1a6
+*	{
+*		"id": "5c8a10214122c7135145a1a6",
+*		"kind": "parse",
+*		"repoId": "5c62d1904122c760dafe9341",
+*		"status": "Queued",
+*		"parsedFileCount": 0,
+*		"skippedFileCount": 0,
+*		"fileCount": 0,
+*		"created": "2019-03-14T08:18:09Z",
+*		"updated": "2019-03-14T08:18:09Z",
+*		"links": {
+*			"self": "/api/v1/jobs/5c8a10214122c7135145a1a6",
+*			"project": "/api/v1/repos/5c62d1904122c760dafe9341/project"
+*		}
+*	}
+*
+* @apiErrorExample {json} Not an analyst.
+*	HTTP/1.1 403 Forbidden
+*	{
+*		"error": {
+*			"status": 403,
+*			"title": "Forbidden",
+*			"message": "Forbidden"
+*		}
+*	}
+ */
+
+// ParseRepo starts a job parsing a repository again, replacing the stored result.
+func (repo RepoController) ParseRepo(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for parse job", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for parse job", packageName)
+
+	if r.Method == "POST" {
+		user, _ := CurrentUser(r)
+
+		exstRepo, ok := findRepo(w, repo.Storage, mux.Vars(r)["repoId"])
+		if !ok {
+			return
+		}
+		repoID := exstRepo.ID.Hex()
+
+		// List all files in the repository directory, honoring globs given with the request.
+		files, err := exstRepo.GetRepoFiles(repo.Storage, model.FileFilter{
+			Include: splitQueryList(r.URL.Query()["include"]),
+			Exclude: splitQueryList(r.URL.Query()["exclude"]),
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to find repository files")
+			util.TypeLogger.Error("%s: Failed to find repository files: %s", packageName, err.Error())
+			recordAudit(user, clientIP(r), model.AuditReparse, repoID, model.OutcomeFailure, err.Error())
+			return
+		}
+
+		ready, position, err := repo.ParseSlots.Acquire()
+		if err == util.ErrQueueFull {
+			writeQueueFull(w)
+			util.TypeLogger.Warn("%s: Rejected parse job on %s, queue is full", packageName, repoID)
+			return
+		}
+
+		job := model.NewJob(model.JobParse, repoID, user.ID)
+		ip := clientIP(r)
+		go func() {
+			waitForJobSlot(job.ID, ready, position)
+			repo.runParse(user, ip, job.ID, exstRepo, files, model.AuditReparse)
+		}()
+
+		writeJob(w, http.StatusAccepted, job)
+
+	} else { // if not POST request
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /api/v1/repos/:repoId/project Get the parsed repository.
+* @apiName Get project.
+* @apiGroup Repository
+* @apiPermission viewer
+*
+* @apiParam {String} repoId Id of submitted git repository.
+*
+* @apiDescription The result of the last finished parse, as sent in the final message of /repo/:id/initial/.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"schema_version": 2,
+*		"files": [
+*			{
+*				"file": {
+*					"parsed": true,
+*					"file_name": "5c62d1904122c760dafe9341/HelloWorld/Main.java",
+*					"functions": null,
+*					"namespaces": null,
+*					"classes": null,
+*					"linesInFile": 8
+*				}
+*			}
+*		]
+*	}
+*
+* @apiErrorExample {json} Not parsed yet.
+*	HTTP/1.1 409 Conflict
+*	{
+*		"error": {
+*			"status": 409,
+*			"title": "Conflict",
+*			"message": "Repository is not parsed, start a parse job first"
+*		}
+*	}
+ */
+
+// GetProject gets the parsed repository.
+func (repo RepoController) GetProject(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for project", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for project", packageName)
+
+	if r.Method == "GET" {
+		exstRepo, ok := findRepo(w, repo.Storage, mux.Vars(r)["repoId"])
+		if !ok {
+			return
+		}
+
+		if len(exstRepo.ParsedRepo.Files) == 0 {
+			writeError(w, http.StatusConflict, "Repository is not parsed, start a parse job first")
+			return
+		}
+
+		http.Header.Add(w.Header(), "content-type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(exstRepo.ParsedRepo)
+
+	} else { // if not GET request
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+/**
+* @api {GET} /api/v1/jobs/:jobId Get the status of a job.
+* @apiName Get job.
+* @apiGroup Jobs
+* @apiPermission user
+*
+* @apiParam {String} jobId Id of the job.
+*
+* @apiDescription Jobs are only visible to the user who started them, and kept for an hour after they
+* finished. The status is "Queued", "Cloning", "Parsing", "Done", "Failed" or "Interrupted", failed and
+* interrupted jobs have an error in the error schema. Interrupted jobs were stopped by a shutdown of the
+* server, parse the repository again to continue where they stopped.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c8a0f6b4122c7135145a1a5",
+*		"kind": "add",
+*		"repoId": "5c62d1904122c760dafe9341",
+*		"status": "Parsing",
+*		"currentFile": "5c62d1904122c760dafe9341/HelloWorld/Main.java",
+*		"parsedFileCount": 1,
+*		"skippedFileCount": 3,
+*		"fileCount": 5,
+*		"created": "2019-03-14T08:15:07Z",
+*		"updated": "2019-03-14T08:15:21Z",
+*		"links": {
+*			"self": "/api/v1/jobs/5c8a0f6b4122c7135145a1a5",
+*			"project": "/api/v1/repos/5c62d1904122c760dafe9341/project"
+*		}
+*	}
+*
+* @apiSuccessExample {json} Failed job:
+* 	HTTP/1.1 200 OK
+*	{
+*		"id": "5c8a0f6b4122c7135145a1a5",
+*		"kind": "add",
+*		"repoId": "5c62d1904122c760dafe9341",
+*		"status": "Failed",
+*		"parsedFileCount": 0,
+*		"skippedFileCount": 0,
+*		"fileCount": 0,
+*		"error": {
+*			"status": 409,
+*			"title": "Conflict",
+*			"message": "Repository already exists"
+*		},
+*		"created": "2019-03-14T08:15:07Z",
+*		"updated": "2019-03-14T08:15:08Z",
+*		"links": {
+*			"self": "/api/v1/jobs/5c8a0f6b4122c7135145a1a5",
+*			"project": "/api/v1/repos/5c62d1904122c760dafe9341/project"
+*		}
+*	}
+*
+* @apiErrorExample {json} Unknown job.
+*	HTTP/1.1 404 Not Found
+*	{
+*		"error": {
+*			"status": 404,
+*			"title": "Not Found",
+*			"message": "Job not found"
+*		}
+*	}
+ */
+
+// GetJob gets a job started by the authenticated user.
+func (jobs JobController) GetJob(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for job", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for job", packageName)
+
+	if r.Method == "GET" {
+		user, _ := CurrentUser(r)
+
+		// Jobs of other users can not be told apart from missing ones
+		job, err := model.GetJob(mux.Vars(r)["jobId"])
+		if err != nil || job.Owner != user.ID {
+			writeError(w, http.StatusNotFound, model.ErrJobNotFound.Error())
+			return
+		}
+
+		writeJob(w, http.StatusOK, job)
+
+	} else { // if not GET request
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// writeJob responds with status and job, telling where to poll it in the "Location" header.
+func writeJob(w http.ResponseWriter, status int, job model.JobModel) {
+	links := map[string]string{"self": APIPrefix + "/jobs/" + job.ID}
+	if len(job.RepoID) > 0 {
+		links["project"] = APIPrefix + "/repos/" + job.RepoID + "/project"
+	}
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+	w.Header().Set("Location", links["self"])
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(JobResponse{JobModel: job, Links: links}); err != nil {
+		util.TypeLogger.Error("%s: Failed to encode Json: %s", packageName, err.Error())
+	}
+}
+
+// waitForJobSlot waits for the slot acquired as ready, with the job with jobID queued at position meanwhile.
+func waitForJobSlot(jobID string, ready chan struct{}, position int) {
+	if position > 0 {
+		model.UpdateJob(jobID, func(job *model.JobModel) {
+			job.Status = model.JobQueued
+			job.Position = position
+		})
+	}
+
+	<-ready
+}
+
+// writeQueueFull responds with 429 Too Many Requests to a job rejected by a full queue, with a "Retry-After"
+// header like the rate limit.
+func writeQueueFull(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(queueFullRetryAfter.Seconds())))
+	writeError(w, http.StatusTooManyRequests, "Too many requests, try again later")
+}
+
+// failJob stops the job with jobID with status and message.
+func failJob(jobID string, status int, message string) {
+	model.UpdateJob(jobID, func(job *model.JobModel) {
+		job.Status = model.JobFailed
+		job.Error = model.NewError(status, message)
+	})
+}
+
+// runAdd runs the job with jobID cloning the repository at uri for user, requesting from ip, once the clone
+// slot acquired as ready is free, and then parses it.
+func (repo RepoController) runAdd(user model.UserModel, ip string, jobID string, uri string, ready chan struct{}, position int) {
+	waitForJobSlot(jobID, ready, position)
+
+	// Save the new repo in database and on file in the background
+	saverChannel := repo.cloneInBackground(func(c chan model.SaveResponse) {
+		model.RepoModel{URI: uri, Owner: user.ID}.Save(repo.Storage, c)
+	})
+
+	for {
+		saverResponse := <-saverChannel
+
+		if saverResponse.Err != nil {
+			recordAudit(user, ip, model.AuditAdd, saverResponse.ID, model.OutcomeFailure, uri+": "+saverResponse.Err.Error())
+
+			if saverResponse.Err.Error() == "Already exists" {
+				util.TypeLogger.Info("%s: Job conflicted with existing repository", packageName)
+				model.UpdateJob(jobID, func(job *model.JobModel) {
+					job.RepoID = saverResponse.ID
+				})
+				failJob(jobID, http.StatusConflict, "Repository already exists")
+				return
+			}
+
+			if saverResponse.StatusText == "Done" {
+				failJob(jobID, http.StatusBadGateway, "Failed to clone repository")
+				return
+			}
+
+			failJob(jobID, http.StatusInternalServerError, "Database error")
+			return
+		}
+
+		model.UpdateJob(jobID, func(job *model.JobModel) {
+			job.RepoID = saverResponse.ID
+			job.Status = model.JobCloning
+		})
+
+		if saverResponse.StatusText == "Done" {
+			recordAudit(user, ip, model.AuditAdd, saverResponse.ID, model.OutcomeSuccess, uri)
+			break
+		}
+	}
+
+	job, _ := model.GetJob(jobID)
+
+	exstRepo, err := model.RepoModel{}.GetRepoByID(repo.Storage, job.RepoID)
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to find repository in database: %s", packageName, err.Error())
+		failJob(jobID, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	files, err := exstRepo.GetRepoFiles(repo.Storage, model.FileFilter{})
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to find repository files: %s", packageName, err.Error())
+		recordAudit(user, ip, model.AuditParse, job.RepoID, model.OutcomeFailure, err.Error())
+		failJob(jobID, http.StatusInternalServerError, "Failed to find repository files")
+		return
+	}
+
+	ready, position, err = repo.ParseSlots.Acquire()
+	if err == util.ErrQueueFull {
+		util.TypeLogger.Warn("%s: Rejected parse of %s, queue is full", packageName, job.RepoID)
+		failJob(jobID, http.StatusTooManyRequests, "Too many requests, parse the repository later")
+		return
+	}
+
+	waitForJobSlot(jobID, ready, position)
+	repo.runParse(user, ip, jobID, exstRepo, files, model.AuditParse)
+}
+
+// runParse runs the job with jobID parsing files of exstRepo, recorded as action of user, requesting from ip,
+// in the audit log. The parse slot must be taken, it is released when parsing is done.
+func (repo RepoController) runParse(user model.UserModel, ip string, jobID string, exstRepo model.RepoModel, files []string, action string) {
+	repoID := exstRepo.ID.Hex()
+
+	model.UpdateJob(jobID, func(job *model.JobModel) {
+		job.Status = model.JobParsing
+	})
+
+	// Setting up channel and go routine to parse all files in repository
+	parseChannel := make(chan model.ParseResponse)
+	go func() {
+		defer repo.ParseSlots.Release()
+		exstRepo.ParseDataFromFiles(repo.Storage, files, 1, parseChannel)
+	}()
+
+	for {
+		parserResponse := <-parseChannel
+
+		if parserResponse.Err == model.ErrInterrupted {
+			util.TypeLogger.Info("%s: Parsing of %s interrupted by shutdown", packageName, repoID)
+			recordAudit(user, ip, action, repoID, model.OutcomeFailure,
+				fmt.Sprintf("interrupted after %d of %d files", parserResponse.ParsedFileCount+parserResponse.SkippedFileCount, parserResponse.FileCount))
+			model.UpdateJob(jobID, func(job *model.JobModel) {
+				job.Progress(parserResponse)
+				job.Status = model.JobInterrupted
+				job.Error = model.NewError(http.StatusServiceUnavailable, "Parsing was interrupted by a shutdown of the server")
+			})
+			return
+		}
+
+		if parserResponse.Err != nil {
+			util.TypeLogger.Error("%s: Failed to parse files: %s", packageName, parserResponse.Err.Error())
+			recordAudit(user, ip, action, repoID, model.OutcomeFailure, parserResponse.Err.Error())
+			failJob(jobID, http.StatusInternalServerError, "Failed to parse files")
+			return
+		}
+
+		model.UpdateJob(jobID, func(job *model.JobModel) {
+			job.Progress(parserResponse)
+		})
+
+		if parserResponse.StatusText == "Done" {
+			recordAudit(user, ip, action, repoID, model.OutcomeSuccess,
+				fmt.Sprintf("%d of %d files parsed", parserResponse.ParsedFileCount, parserResponse.FileCount))
+			model.UpdateJob(jobID, func(job *model.JobModel) {
+				job.Status = model.JobDone
+				job.CurrentFile = ""
+			})
+			return
+		}
+	}
+}
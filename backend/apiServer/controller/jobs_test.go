@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestJobController_GetJob(t *testing.T) {
+	owner := model.UserModel{ID: bson.NewObjectId(), Name: "alice"}
+	other := model.UserModel{ID: bson.NewObjectId(), Name: "bob"}
+
+	job := model.NewJob(model.JobParse, "5c62d1904122c760dafe9341", owner.ID)
+
+	tests := []struct {
+		name       string
+		user       model.UserModel
+		jobID      string
+		wantStatus int
+	}{
+		{name: "Owner", user: owner, jobID: job.ID, wantStatus: http.StatusOK},
+		{name: "Other user", user: other, jobID: job.ID, wantStatus: http.StatusNotFound},
+		{name: "Unknown job", user: owner, jobID: bson.NewObjectId().Hex(), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", APIPrefix+"/jobs/"+tt.jobID, nil)
+			r = mux.SetURLVars(withUser(r, tt.user), map[string]string{"jobId": tt.jobID})
+			w := httptest.NewRecorder()
+
+			JobController{}.GetJob(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response JobResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode job: %v", err)
+			}
+			if response.ID != job.ID || response.Status != model.JobQueued {
+				t.Errorf("job = %+v, want queued job %s", response, job.ID)
+			}
+			if want := APIPrefix + "/repos/5c62d1904122c760dafe9341/project"; response.Links["project"] != want {
+				t.Errorf("project link = %q, want %q", response.Links["project"], want)
+			}
+		})
+	}
+}
+
+func TestRepoController_AddRepo_queueFull(t *testing.T) {
+	repo := RepoController{CloneSlots: util.NewSlots(1, 0)}
+	if _, _, err := repo.CloneSlots.Acquire(); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	r := httptest.NewRequest("POST", APIPrefix+"/repos", strings.NewReader(`{"uri": "https://github.com/zohaib194/CodebaseVisualizer3D.git"}`))
+	r = withUser(r, model.UserModel{ID: bson.NewObjectId(), Name: "alice"})
+	w := httptest.NewRecorder()
+
+	repo.AddRepo(w, r)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+}
@@ -0,0 +1,911 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification the document follows.
+const OpenAPIVersion = "3.0.3"
+
+// Permissions of endpoints besides the roles in a repository.
+const (
+	permissionNone      = "none"       // Anyone
+	permissionUser      = "user"       // Any logged in user
+	permissionAdminUser = "admin user" // Admin users of the server
+)
+
+// componentsRef prefixes references to the schemas of the document.
+const componentsRef = "#/components/schemas/"
+
+// OpenAPIController serves the OpenAPI document of the api server.
+type OpenAPIController struct {
+}
+
+// OpenAPI is an OpenAPI 3 document describing the routes of the api server.
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"` // Operations by path and lower case method
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo names the api.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIOperation describes a method of a path.
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags"`
+	Permission  string                      `json:"x-permission"`       // "none", "user", "admin user" or the role needed in the repository
+	Security    []map[string][]string       `json:"security,omitempty"` // Ways to authenticate, empty when anyone may call the operation
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"` // Responses by status code
+}
+
+// OpenAPIParameter describes a path or query parameter of an operation.
+type OpenAPIParameter struct {
+	Name        string            `json:"name"`
+	In          string            `json:"in"`
+	Description string            `json:"description"`
+	Required    bool              `json:"required,omitempty"`
+	Schema      *model.JSONSchema `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the body of a request.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"` // Body by content type
+}
+
+// OpenAPIResponse describes a response of an operation.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]OpenAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"` // Body by content type
+}
+
+// OpenAPIHeader describes a header of a response.
+type OpenAPIHeader struct {
+	Description string            `json:"description"`
+	Schema      *model.JSONSchema `json:"schema"`
+}
+
+// OpenAPIMediaType describes a body of some content type.
+type OpenAPIMediaType struct {
+	Schema *model.JSONSchema `json:"schema"`
+}
+
+// OpenAPIComponents holds the schemas and security schemes the operations refer to.
+type OpenAPIComponents struct {
+	Schemas         map[string]*model.JSONSchema     `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes a way to authenticate.
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// endpoint is a route of the api server as described in the OpenAPI document.
+type endpoint struct {
+	method     string
+	path       string                   // Path with parameters in braces, the same as the route
+	permission string                   // permissionNone, permissionUser, permissionAdminUser or the role needed in the repository
+	limited    bool                     // Requests count against the rate limit of the client
+	operation  OpenAPIOperation         // Operation without its permission, security and responses
+	responses  map[int]*OpenAPIResponse // Successful responses by status
+	errors     map[int]string           // Errors of the handler by status, the errors of the permission and rate limit are added
+}
+
+/**
+* @api {GET} /openapi.json Get the OpenAPI document of the api.
+* @apiName Get OpenAPI.
+* @apiGroup Schema
+* @apiPermission none
+*
+* @apiDescription Returns an OpenAPI 3 document describing every route of the api server, the
+* unversioned routes as well as the versioned api under /api/v1. Responses and request bodies refer
+* to schemas generated from the models, "x-permission" tells who may call each operation and routes
+* counting against the rate limit document the 429 Too Many Requests response.
+*
+* @apiSuccessExample {json} Success-Response:
+* 	HTTP/1.1 200 OK
+*	{
+*		"openapi": "3.0.3",
+*		"info": {"title": "CodebaseVisualizer3D api", "description": "...", "version": "1"},
+*		"paths": {
+*			"/schema": {
+*				"get": {
+*					"operationId": "getSchema",
+*					"summary": "Get the JSON Schema of parse results",
+*					"tags": ["Schema"],
+*					"x-permission": "none",
+*					"responses": {"200": {"description": "...", "content": {"application/schema+json": {...}}}}
+*				}
+*			}
+*		},
+*		"components": {"schemas": {...}, "securitySchemes": {...}}
+*	}
+ */
+
+// GetOpenAPI responds with the OpenAPI document of the api server.
+func (openAPI OpenAPIController) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for openapi", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for openapi", packageName)
+
+	http.Header.Add(w.Header(), "content-type", "application/json")
+
+	if r.Method == "GET" {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(OpenAPIDocument())
+
+	} else { // if not GET request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// OpenAPIDocument describes every route of the api server, with schemas generated from the models.
+func OpenAPIDocument() OpenAPI {
+	document := OpenAPI{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title: "CodebaseVisualizer3D api",
+			Description: "Adds, parses and analyses git repositories. Errors of the versioned api under " + APIPrefix +
+				" follow the ErrorResponse schema, other routes respond to errors with plain text.",
+			Version: "1",
+		},
+		Paths: make(map[string]map[string]*OpenAPIOperation),
+		Components: OpenAPIComponents{
+			Schemas: model.ComponentSchemas(
+				model.UserModel{}, model.AccessTokenModel{}, model.AuditModel{}, model.RepoConfig{},
+				model.ProjectModel{}, model.TokenModel{}, model.SymbolModel{}, model.ReferenceModel{},
+				model.SearchResultModel{}, model.RepoSearchResultModel{}, model.DeadCodeReport{},
+				model.CloneReport{}, model.ViolationReport{}, model.SarifLog{}, model.JSONSchema{},
+				WebsocketResponse{}, JobResponse{}, ErrorResponse{}, OpenAPI{},
+			),
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				"bearer": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Api token created with POST /auth/tokens",
+				},
+				"session": {
+					Type:        "apiKey",
+					In:          "cookie",
+					Name:        SessionCookie,
+					Description: "Session token set by logging in",
+				},
+			},
+		},
+	}
+
+	for _, endpoint := range openAPIEndpoints() {
+		document.add(endpoint)
+	}
+
+	return document
+}
+
+// add describes endpoint in the document, with the errors of its permission and rate limit.
+func (document *OpenAPI) add(endpoint endpoint) {
+	operation := endpoint.operation
+	operation.Permission = endpoint.permission
+	operation.Responses = make(map[string]*OpenAPIResponse)
+	for status, response := range endpoint.responses {
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+
+	errors := make(map[int]string)
+	for status, description := range endpoint.errors {
+		errors[status] = description
+	}
+
+	if endpoint.permission != permissionNone {
+		operation.Security = []map[string][]string{{"bearer": {}}, {"session": {}}}
+		errors[http.StatusUnauthorized] = "Not logged in, or the token expired"
+	}
+
+	switch endpoint.permission {
+	case permissionAdminUser:
+		errors[http.StatusForbidden] = "The user is not an admin of the server"
+	case model.RoleAnalyst, model.RoleAdmin:
+		errors[http.StatusForbidden] = "The role of the user in the repository is below " + endpoint.permission
+		fallthrough
+	case model.RoleViewer:
+		if _, ok := errors[http.StatusNotFound]; !ok {
+			errors[http.StatusNotFound] = "Repository not found, or the user has no role in it"
+		}
+	}
+
+	if endpoint.limited {
+		errors[http.StatusTooManyRequests] = "The client is over its rate limit"
+	}
+
+	for status, description := range errors {
+		operation.Responses[strconv.Itoa(status)] = errorResponse(endpoint.path, description)
+	}
+
+	if endpoint.limited {
+		operation.Responses[strconv.Itoa(http.StatusTooManyRequests)].Headers = map[string]OpenAPIHeader{
+			"Retry-After": {Description: "Seconds until the client may try again", Schema: schemaType("integer")},
+		}
+	}
+
+	if _, ok := document.Paths[endpoint.path]; !ok {
+		document.Paths[endpoint.path] = make(map[string]*OpenAPIOperation)
+	}
+	document.Paths[endpoint.path][strings.ToLower(endpoint.method)] = &operation
+}
+
+// errorResponse describes an error of the route with path, in json for the versioned api and in plain text otherwise.
+func errorResponse(path string, description string) *OpenAPIResponse {
+	if strings.HasPrefix(path, APIPrefix) {
+		return jsonResponse(description, ref("ErrorResponse"))
+	}
+
+	return &OpenAPIResponse{
+		Description: description,
+		Content:     map[string]OpenAPIMediaType{"text/plain": {Schema: schemaType("string")}},
+	}
+}
+
+// jsonResponse describes a json response following schema.
+func jsonResponse(description string, schema *model.JSONSchema) *OpenAPIResponse {
+	return &OpenAPIResponse{
+		Description: description,
+		Content:     map[string]OpenAPIMediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// emptyResponse describes a response without a body.
+func emptyResponse(description string) *OpenAPIResponse {
+	return &OpenAPIResponse{Description: description}
+}
+
+// jsonBody describes a required json request body following schema.
+func jsonBody(schema *model.JSONSchema) *OpenAPIRequestBody {
+	return &OpenAPIRequestBody{
+		Required: true,
+		Content:  map[string]OpenAPIMediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// pathParameter describes a parameter in the path.
+func pathParameter(name string, description string) OpenAPIParameter {
+	return OpenAPIParameter{Name: name, In: "path", Description: description, Required: true, Schema: schemaType("string")}
+}
+
+// queryParameter describes an optional parameter in the query.
+func queryParameter(name string, description string, schema *model.JSONSchema) OpenAPIParameter {
+	return OpenAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// requiredQueryParameter describes a parameter in the query every request needs.
+func requiredQueryParameter(name string, description string, schema *model.JSONSchema) OpenAPIParameter {
+	parameter := queryParameter(name, description, schema)
+	parameter.Required = true
+	return parameter
+}
+
+// ref refers to the schema of the document with name.
+func ref(name string) *model.JSONSchema {
+	return &model.JSONSchema{Ref: componentsRef + name}
+}
+
+// schemaType describes values of the json type.
+func schemaType(jsonType string) *model.JSONSchema {
+	return &model.JSONSchema{Type: jsonType}
+}
+
+// enum describes strings that are one of values.
+func enum(values ...string) *model.JSONSchema {
+	return &model.JSONSchema{Type: "string", Enum: values}
+}
+
+// arrayOf describes arrays of items.
+func arrayOf(items *model.JSONSchema) *model.JSONSchema {
+	return &model.JSONSchema{Type: "array", Items: items}
+}
+
+// objectOf describes objects with properties, of which the required ones are always present.
+func objectOf(properties map[string]*model.JSONSchema, required ...string) *model.JSONSchema {
+	sort.Strings(required)
+	return &model.JSONSchema{Type: "object", Properties: properties, Required: required}
+}
+
+// openAPIEndpoints lists every route of the api server.
+func openAPIEndpoints() []endpoint {
+	repoID := pathParameter("repoId", "Id of submitted git repository")
+	position := []OpenAPIParameter{
+		repoID,
+		requiredQueryParameter("filePath", "The file the symbol is used in, starting with repoId as in the parsed file names", schemaType("string")),
+		requiredQueryParameter("line", "Line of the identifier, starting at 1", schemaType("integer")),
+		requiredQueryParameter("column", "Column of any character of the identifier, starting at 1", schemaType("integer")),
+	}
+	globs := []OpenAPIParameter{
+		repoID,
+		queryParameter("include", "Comma separated globs, only files matching one of them are parsed", schemaType("string")),
+		queryParameter("exclude", "Comma separated globs for files and directories that are skipped", schemaType("string")),
+	}
+	searchModes := enum("prefix", "fuzzy", "regex")
+	roles := enum(model.RoleViewer, model.RoleAnalyst, model.RoleAdmin)
+	credentials := objectOf(map[string]*model.JSONSchema{
+		"name":     schemaType("string"),
+		"password": schemaType("string"),
+	}, "name", "password")
+	repoSummary := objectOf(map[string]*model.JSONSchema{
+		"_id": schemaType("string"),
+		"uri": schemaType("string"),
+	}, "_id", "uri")
+	members := objectOf(map[string]*model.JSONSchema{
+		"members": arrayOf(objectOf(map[string]*model.JSONSchema{
+			"name": schemaType("string"),
+			"role": roles,
+		}, "name", "role")),
+	}, "members")
+	membersWithOwner := objectOf(map[string]*model.JSONSchema{
+		"owner":   schemaType("string"),
+		"members": members.Properties["members"],
+	}, "owner", "members")
+	websocket := map[int]*OpenAPIResponse{
+		http.StatusSwitchingProtocols: emptyResponse("Upgraded to a websocket sending WebsocketResponse messages"),
+	}
+
+	exportContent := make(map[string]OpenAPIMediaType)
+	exportNames := make([]string, 0, len(model.ExportFormats))
+	for name, format := range model.ExportFormats {
+		exportContent[format.ContentType] = OpenAPIMediaType{Schema: schemaType("string")}
+		exportNames = append(exportNames, name)
+	}
+	sort.Strings(exportNames)
+
+	return []endpoint{
+		// Authentication
+		{
+			method: "POST", path: "/auth/register", permission: permissionNone, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "register",
+				Summary:     "Create a user account",
+				Tags:        []string{"Authentication"},
+				RequestBody: jsonBody(credentials),
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusCreated: jsonResponse("The created user", ref("UserModel"))},
+			errors: map[int]string{
+				http.StatusBadRequest: "Invalid json, name or password",
+				http.StatusConflict:   "The name is taken",
+			},
+		},
+		{
+			method: "POST", path: "/auth/login", permission: permissionNone, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "login",
+				Summary:     "Log in with name and password",
+				Tags:        []string{"Authentication"},
+				RequestBody: jsonBody(credentials),
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The user, with the session token in the \""+SessionCookie+"\" cookie", ref("UserModel"))},
+			errors: map[int]string{
+				http.StatusBadRequest:   "Invalid json",
+				http.StatusUnauthorized: "Wrong name or password",
+			},
+		},
+		{
+			method: "GET", path: "/auth/oidc/login", permission: permissionNone,
+			operation: OpenAPIOperation{
+				OperationID: "oidcLogin",
+				Summary:     "Log in with the OpenID Connect provider",
+				Description: "Only routed when the server is configured with a provider.",
+				Tags:        []string{"Authentication"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusFound: emptyResponse("Redirect to the provider")},
+		},
+		{
+			method: "GET", path: "/auth/oidc/callback", permission: permissionNone,
+			operation: OpenAPIOperation{
+				OperationID: "oidcCallback",
+				Summary:     "Finish logging in with the OpenID Connect provider",
+				Description: "Only routed when the server is configured with a provider.",
+				Tags:        []string{"Authentication"},
+				Parameters: []OpenAPIParameter{
+					queryParameter("code", "Authorization code from the provider", schemaType("string")),
+					queryParameter("state", "State of the login", schemaType("string")),
+					queryParameter("error", "Why the provider refused the login", schemaType("string")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusFound: emptyResponse("Logged in, redirect to the website with the session token in the \"" + SessionCookie + "\" cookie")},
+			errors:    map[int]string{http.StatusUnauthorized: "The login failed or expired"},
+		},
+		{
+			method: "POST", path: "/auth/logout", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "logout",
+				Summary:     "End the session of the request",
+				Tags:        []string{"Authentication"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusNoContent: emptyResponse("Logged out")},
+		},
+		{
+			method: "GET", path: "/auth/me", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "getMe",
+				Summary:     "Get the authenticated user",
+				Tags:        []string{"Authentication"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The user", ref("UserModel"))},
+		},
+		{
+			method: "GET", path: "/auth/tokens", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "listTokens",
+				Summary:     "List the api tokens of the user",
+				Tags:        []string{"Authentication"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The tokens, without their secrets", objectOf(map[string]*model.JSONSchema{
+				"tokens": arrayOf(ref("AccessTokenModel")),
+			}, "tokens"))},
+		},
+		{
+			method: "POST", path: "/auth/tokens", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "createToken",
+				Summary:     "Create an api token",
+				Tags:        []string{"Authentication"},
+				RequestBody: jsonBody(objectOf(map[string]*model.JSONSchema{"name": schemaType("string")}, "name")),
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusCreated: jsonResponse("The secret of the token, only shown once, and the token", objectOf(map[string]*model.JSONSchema{
+				"token": schemaType("string"),
+				"info":  ref("AccessTokenModel"),
+			}, "token", "info"))},
+			errors: map[int]string{http.StatusBadRequest: "Invalid json or missing name"},
+		},
+		{
+			method: "DELETE", path: "/auth/tokens/{tokenId}", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "revokeToken",
+				Summary:     "Revoke an api token",
+				Tags:        []string{"Authentication"},
+				Parameters:  []OpenAPIParameter{pathParameter("tokenId", "Id of the token")},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusNoContent: emptyResponse("Revoked")},
+			errors: map[int]string{
+				http.StatusBadRequest: "Invalid token id",
+				http.StatusNotFound:   "The user has no token with the id",
+			},
+		},
+
+		// Search, schemas and audit
+		{
+			method: "GET", path: "/search", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "searchAll",
+				Summary:     "Search symbols across all repositories of the user",
+				Tags:        []string{"Search"},
+				Parameters: []OpenAPIParameter{
+					requiredQueryParameter("q", "Text to search for", schemaType("string")),
+					queryParameter("mode", "How the query is matched against names, prefix by default", searchModes),
+					queryParameter("kind", "Comma separated symbol kinds: function, class, namespace, variable, parameter", schemaType("string")),
+					queryParameter("limit", "Maximum number of results per repository, at most 500", schemaType("integer")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("Results grouped by repository", objectOf(map[string]*model.JSONSchema{
+				"repos": arrayOf(ref("RepoSearchResultModel")),
+			}, "repos"))},
+			errors: map[int]string{http.StatusBadRequest: "Invalid query, mode, kind or limit"},
+		},
+		{
+			method: "GET", path: "/schema", permission: permissionNone,
+			operation: OpenAPIOperation{
+				OperationID: "getSchema",
+				Summary:     "Get the JSON Schema of parse results",
+				Tags:        []string{"Schema"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: {
+				Description: "The JSON Schema, draft-07, of the output of the java parser for one file",
+				Content:     map[string]OpenAPIMediaType{"application/schema+json": {Schema: ref("JSONSchema")}},
+			}},
+		},
+		{
+			method: "GET", path: "/openapi.json", permission: permissionNone,
+			operation: OpenAPIOperation{
+				OperationID: "getOpenAPI",
+				Summary:     "Get the OpenAPI document of the api",
+				Tags:        []string{"Schema"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("This document", ref("OpenAPI"))},
+		},
+		{
+			method: "GET", path: "/audit", permission: permissionAdminUser,
+			operation: OpenAPIOperation{
+				OperationID: "getAudit",
+				Summary:     "Query the audit log",
+				Tags:        []string{"Audit"},
+				Parameters: []OpenAPIParameter{
+					queryParameter("user", "Name of the user who acted", schemaType("string")),
+					queryParameter("repo", "Id of the repository acted on", schemaType("string")),
+					queryParameter("action", "What was done, \"access\" for requests denied by the role of the user",
+						enum(model.AuditAdd, model.AuditParse, model.AuditReparse, model.AuditExport, model.AuditDelete, model.AuditAccess)),
+					queryParameter("outcome", "How it ended", enum(model.OutcomeSuccess, model.OutcomeFailure, model.OutcomeDenied)),
+					queryParameter("since", "RFC 3339 time of the oldest entry", &model.JSONSchema{Type: "string", Format: "date-time"}),
+					queryParameter("until", "RFC 3339 time entries must be older than", &model.JSONSchema{Type: "string", Format: "date-time"}),
+					queryParameter("limit", "Maximum number of entries, at most 1000", schemaType("integer")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("Entries, newest first", objectOf(map[string]*model.JSONSchema{
+				"entries": arrayOf(ref("AuditModel")),
+			}, "entries"))},
+			errors: map[int]string{http.StatusBadRequest: "Invalid filter"},
+		},
+
+		// Repositories
+		{
+			method: "GET", path: "/repo/add", permission: permissionUser, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "addRepoSocket",
+				Summary:     "Add a git repository over a websocket",
+				Description: "The client sends {\"uri\": ...} and receives the status of the clone and the id of the repository.",
+				Tags:        []string{"Repository"},
+			},
+			responses: websocket,
+		},
+		{
+			method: "GET", path: "/repo/list", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "listRepos",
+				Summary:     "Get all git repositories the user has access to",
+				Tags:        []string{"Repository"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("Ids and URIs of the repositories", arrayOf(repoSummary))},
+		},
+		{
+			method: "DELETE", path: "/repo/{repoId}", permission: model.RoleAdmin,
+			operation: OpenAPIOperation{
+				OperationID: "deleteRepo",
+				Summary:     "Delete a repository",
+				Tags:        []string{"Repository"},
+				Parameters:  []OpenAPIParameter{repoID},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusNoContent: emptyResponse("Deleted")},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/initial/", permission: model.RoleViewer, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "parseRepoSocket",
+				Summary:     "Parse a repository over a websocket",
+				Description: "Messages report the progress, the last one contains the parsed repository as \"result\".",
+				Tags:        []string{"Repository"},
+				Parameters:  globs,
+			},
+			responses: websocket,
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/reparse/", permission: model.RoleAnalyst, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "reparseRepoSocket",
+				Summary:     "Parse a repository again over a websocket",
+				Tags:        []string{"Repository"},
+				Parameters:  globs,
+			},
+			responses: websocket,
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/config", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getRepoConfig",
+				Summary:     "Get the analysis configuration of a repository",
+				Tags:        []string{"Repository"},
+				Parameters:  []OpenAPIParameter{repoID},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The configuration in effect and the override set through the api", objectOf(map[string]*model.JSONSchema{
+				"effective": ref("RepoConfig"),
+				"override":  ref("RepoConfig"),
+			}, "effective"))},
+			errors: map[int]string{http.StatusUnprocessableEntity: "Invalid " + model.RepoConfigFile + " in the repository"},
+		},
+		{
+			method: "PUT", path: "/repo/{repoId}/config", permission: model.RoleAdmin,
+			operation: OpenAPIOperation{
+				OperationID: "setRepoConfig",
+				Summary:     "Set the analysis configuration of a repository",
+				Tags:        []string{"Repository"},
+				Parameters:  []OpenAPIParameter{repoID},
+				RequestBody: jsonBody(ref("RepoConfig")),
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The stored override", ref("RepoConfig"))},
+			errors:    map[int]string{http.StatusBadRequest: "Invalid json or configuration"},
+		},
+		{
+			method: "DELETE", path: "/repo/{repoId}/config", permission: model.RoleAdmin,
+			operation: OpenAPIOperation{
+				OperationID: "deleteRepoConfig",
+				Summary:     "Remove the analysis configuration set through the api",
+				Tags:        []string{"Repository"},
+				Parameters:  []OpenAPIParameter{repoID},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusNoContent: emptyResponse("Removed")},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/members", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getMembers",
+				Summary:     "Get the members of a repository",
+				Tags:        []string{"Repository"},
+				Parameters:  []OpenAPIParameter{repoID},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The owner and the other members", membersWithOwner)},
+		},
+		{
+			method: "PUT", path: "/repo/{repoId}/members", permission: model.RoleAdmin,
+			operation: OpenAPIOperation{
+				OperationID: "setMembers",
+				Summary:     "Replace the members of a repository",
+				Tags:        []string{"Repository"},
+				Parameters:  []OpenAPIParameter{repoID},
+				RequestBody: jsonBody(members),
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The owner and the new members", membersWithOwner)},
+			errors: map[int]string{
+				http.StatusBadRequest:          "Invalid json or role",
+				http.StatusUnprocessableEntity: "Unknown user",
+			},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/file/read/", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getImplementation",
+				Summary:     "Fetch the implementation of a file between two lines",
+				Tags:        []string{"File"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					requiredQueryParameter("lineStart", "Line number where the fetch starts", schemaType("integer")),
+					requiredQueryParameter("lineEnd", "Line number where the fetch stops", schemaType("integer")),
+					requiredQueryParameter("filePath", "The file to fetch from, starting with repoId as in the parsed file names", schemaType("string")),
+					queryParameter("format", "Return raw text, annotated tokens or highlighted html, text by default", enum("text", "tokens", "html")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The \"implementation\" as text, or the language and lines with \"tokens\" or \"html\"", objectOf(map[string]*model.JSONSchema{
+				"implementation": schemaType("string"),
+				"language":       schemaType("string"),
+				"start_line":     schemaType("integer"),
+				"end_line":       schemaType("integer"),
+				"tokens":         arrayOf(ref("TokenModel")),
+				"html":           schemaType("string"),
+			}))},
+			errors: map[int]string{
+				http.StatusBadRequest:          "Invalid lines, file or format",
+				http.StatusForbidden:           "The file is outside the repository",
+				http.StatusNotFound:            "Repository or file not found, or the user has no role in the repository",
+				http.StatusUnprocessableEntity: "Invalid " + model.RepoConfigFile + " in the repository",
+			},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/search", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "searchRepo",
+				Summary:     "Search symbols and content of a repository",
+				Tags:        []string{"Search"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					requiredQueryParameter("q", "Text to search for", schemaType("string")),
+					queryParameter("mode", "How the query is matched against names and content, prefix by default", searchModes),
+					queryParameter("kind", "Comma separated result kinds: function, class, namespace, variable, parameter, content", schemaType("string")),
+					queryParameter("limit", "Maximum number of results, at most 500", schemaType("integer")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("Results, best first", objectOf(map[string]*model.JSONSchema{
+				"id":      schemaType("string"),
+				"results": arrayOf(ref("SearchResultModel")),
+			}, "id", "results"))},
+			errors: map[int]string{http.StatusBadRequest: "Invalid query, mode, kind or limit"},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/definition", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getDefinition",
+				Summary:     "Find the definition of a symbol",
+				Tags:        []string{"Navigation"},
+				Parameters:  position,
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The symbol at the position", objectOf(map[string]*model.JSONSchema{
+				"id":     schemaType("string"),
+				"symbol": ref("SymbolModel"),
+			}, "id", "symbol"))},
+			errors: map[int]string{
+				http.StatusBadRequest: "Invalid file, line or column",
+				http.StatusNotFound:   "Repository or symbol not found, or the user has no role in the repository",
+			},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/references", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getReferences",
+				Summary:     "Find all references to a symbol",
+				Tags:        []string{"Navigation"},
+				Parameters:  position,
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The symbol at the position and its references", objectOf(map[string]*model.JSONSchema{
+				"id":         schemaType("string"),
+				"symbol":     ref("SymbolModel"),
+				"references": arrayOf(ref("ReferenceModel")),
+			}, "id", "symbol", "references"))},
+			errors: map[int]string{
+				http.StatusBadRequest: "Invalid file, line or column",
+				http.StatusNotFound:   "Repository or symbol not found, or the user has no role in the repository",
+			},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/deadcode", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getDeadCode",
+				Summary:     "Report code not reachable from the entry points",
+				Tags:        []string{"Analysis"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					queryParameter("overlay", "Also return the parsed repository with \"dead\" set on every function when \"true\"", schemaType("boolean")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The report", objectOf(map[string]*model.JSONSchema{
+				"id":         schemaType("string"),
+				"report":     ref("DeadCodeReport"),
+				"parsedrepo": ref("ProjectModel"),
+			}, "id", "report"))},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/clones", permission: model.RoleViewer, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "getClones",
+				Summary:     "Find duplicated functions",
+				Tags:        []string{"Analysis"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					queryParameter("min_tokens", "Functions with fewer tokens are ignored, 50 by default", schemaType("integer")),
+					queryParameter("threshold", "Minimum similarity between 0 and 1 for near clones, 0.8 by default", schemaType("number")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The report", objectOf(map[string]*model.JSONSchema{
+				"id":     schemaType("string"),
+				"report": ref("CloneReport"),
+			}, "id", "report"))},
+			errors: map[int]string{http.StatusBadRequest: "Invalid min_tokens or threshold"},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/violations", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getViolations",
+				Summary:     "Evaluate quality rules",
+				Tags:        []string{"Analysis"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					queryParameter("severity", "Only return violations at least this severe, info by default",
+						enum(model.SeverityInfo, model.SeverityWarning, model.SeverityError)),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The report", objectOf(map[string]*model.JSONSchema{
+				"id":     schemaType("string"),
+				"report": ref("ViolationReport"),
+			}, "id", "report"))},
+			errors: map[int]string{
+				http.StatusBadRequest:          "Invalid severity",
+				http.StatusUnprocessableEntity: "Invalid " + model.RepoConfigFile + " in the repository",
+			},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/sarif", permission: model.RoleAnalyst, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "getSarif",
+				Summary:     "Export analysis findings as SARIF",
+				Tags:        []string{"Analysis"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					queryParameter("findings", "Comma separated findings to export, violations,deadcode,clones,cycles by default", schemaType("string")),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: {
+				Description: "SARIF 2.1.0 log",
+				Content:     map[string]OpenAPIMediaType{"application/sarif+json": {Schema: ref("SarifLog")}},
+			}},
+			errors: map[int]string{
+				http.StatusBadRequest:          "Invalid findings",
+				http.StatusUnprocessableEntity: "Invalid " + model.RepoConfigFile + " in the repository",
+			},
+		},
+		{
+			method: "GET", path: "/repo/{repoId}/export", permission: model.RoleAnalyst, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "getExport",
+				Summary:     "Export the parsed repository as a graph",
+				Tags:        []string{"Export"},
+				Parameters: []OpenAPIParameter{
+					repoID,
+					queryParameter("format", "Format of the graph, graphml by default", enum(exportNames...)),
+				},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: {Description: "The graph, as an attachment", Content: exportContent}},
+			errors:    map[int]string{http.StatusBadRequest: "Invalid format"},
+		},
+
+		// Versioned api
+		{
+			method: "GET", path: APIPrefix + "/repos", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "listReposV1",
+				Summary:     "Get all git repositories the user has access to",
+				Tags:        []string{"Repository"},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("Ids and URIs of the repositories", arrayOf(repoSummary))},
+		},
+		{
+			method: "POST", path: APIPrefix + "/repos", permission: permissionUser, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "addRepo",
+				Summary:     "Add a git repository and parse it in the background",
+				Tags:        []string{"Jobs"},
+				RequestBody: jsonBody(objectOf(map[string]*model.JSONSchema{"uri": schemaType("string")}, "uri")),
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusAccepted: jobResponse("The job adding the repository")},
+			errors:    map[int]string{http.StatusBadRequest: "Invalid json or git URI"},
+		},
+		{
+			method: "GET", path: APIPrefix + "/repos/{repoId}/project", permission: model.RoleViewer,
+			operation: OpenAPIOperation{
+				OperationID: "getProject",
+				Summary:     "Get the parsed repository",
+				Tags:        []string{"Jobs"},
+				Parameters:  []OpenAPIParameter{repoID},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The parsed repository", ref("ProjectModel"))},
+			errors:    map[int]string{http.StatusConflict: "The repository was not parsed yet"},
+		},
+		{
+			method: "POST", path: APIPrefix + "/repos/{repoId}/parse", permission: model.RoleAnalyst, limited: true,
+			operation: OpenAPIOperation{
+				OperationID: "parseRepo",
+				Summary:     "Parse a repository again in the background",
+				Tags:        []string{"Jobs"},
+				Parameters:  globs,
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusAccepted: jobResponse("The job parsing the repository")},
+		},
+		{
+			method: "GET", path: APIPrefix + "/jobs/{jobId}", permission: permissionUser,
+			operation: OpenAPIOperation{
+				OperationID: "getJob",
+				Summary:     "Get the status of a job",
+				Tags:        []string{"Jobs"},
+				Parameters:  []OpenAPIParameter{pathParameter("jobId", "Id of the job")},
+			},
+			responses: map[int]*OpenAPIResponse{http.StatusOK: jsonResponse("The job", ref("JobResponse"))},
+			errors:    map[int]string{http.StatusNotFound: "Job not found, expired or started by another user"},
+		},
+	}
+}
+
+// jobResponse describes the response of a request starting a job.
+func jobResponse(description string) *OpenAPIResponse {
+	response := jsonResponse(description, ref("JobResponse"))
+	response.Headers = map[string]OpenAPIHeader{
+		"Location": {Description: "Url of the job", Schema: schemaType("string")},
+	}
+	return response
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Successful responses of authenticated users follow the document, for handlers not needing the database.
+func TestOpenAPIDocument_successResponses(t *testing.T) {
+	document := OpenAPIDocument()
+	user := model.UserModel{ID: bson.NewObjectId(), Name: "alice"}
+
+	queued := model.NewJob(model.JobParse, "5c62d1904122c760dafe9341", user.ID)
+	done := model.NewJob(model.JobParse, "5c62d1904122c760dafe9341", user.ID)
+	model.UpdateJob(done.ID, func(job *model.JobModel) {
+		job.Status = model.JobDone
+		job.ParsedFileCount = 2
+		job.FileCount = 2
+	})
+	failed := model.NewJob(model.JobAdd, "", user.ID)
+	failJob(failed.ID, http.StatusConflict, "Repository already exists")
+
+	// The only clone slot is taken, so the added repository stays queued without reaching the database
+	cloneSlots := util.NewSlots(1, 1)
+	if _, _, err := cloneSlots.Acquire(); err != nil {
+		t.Fatalf("Failed to take the clone slot: %v", err)
+	}
+	repo := RepoController{CloneSlots: cloneSlots, ParseSlots: util.NewSlots(1, 1)}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		vars       map[string]string
+		body       string
+		handler    http.HandlerFunc
+		wantStatus int
+	}{
+		{name: "Current user", method: "GET", path: "/auth/me", handler: UserController{}.GetMe, wantStatus: http.StatusOK},
+		{name: "OpenAPI document", method: "GET", path: "/openapi.json", handler: OpenAPIController{}.GetOpenAPI, wantStatus: http.StatusOK},
+		{
+			name: "Queued job", method: "GET", path: APIPrefix + "/jobs/{jobId}", vars: map[string]string{"jobId": queued.ID},
+			handler: JobController{}.GetJob, wantStatus: http.StatusOK,
+		},
+		{
+			name: "Done job", method: "GET", path: APIPrefix + "/jobs/{jobId}", vars: map[string]string{"jobId": done.ID},
+			handler: JobController{}.GetJob, wantStatus: http.StatusOK,
+		},
+		{
+			name: "Failed job", method: "GET", path: APIPrefix + "/jobs/{jobId}", vars: map[string]string{"jobId": failed.ID},
+			handler: JobController{}.GetJob, wantStatus: http.StatusOK,
+		},
+		{
+			name: "Add repository", method: "POST", path: APIPrefix + "/repos", body: `{"uri": "https://github.com/zohaib194/CodebaseVisualizer3D.git"}`,
+			handler: repo.AddRepo, wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			for name, value := range tt.vars {
+				path = strings.Replace(path, "{"+name+"}", value, 1)
+			}
+			r := httptest.NewRequest(tt.method, path, strings.NewReader(tt.body))
+			r = mux.SetURLVars(withUser(r, user), tt.vars)
+			w := httptest.NewRecorder()
+
+			tt.handler(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			operation, ok := document.Paths[tt.path][strings.ToLower(tt.method)]
+			if !ok {
+				t.Fatalf("%s %s is not documented", tt.method, tt.path)
+			}
+			response, ok := operation.Responses[strconv.Itoa(w.Code)]
+			if !ok {
+				t.Fatalf("status %d is not documented", w.Code)
+			}
+			media, ok := response.Content[w.Header().Get("Content-Type")]
+			if !ok {
+				t.Fatalf("content type %q is not documented", w.Header().Get("Content-Type"))
+			}
+
+			var body interface{}
+			decoder := json.NewDecoder(w.Body)
+			decoder.UseNumber()
+			if err := decoder.Decode(&body); err != nil {
+				t.Fatalf("Failed to decode body: %v", err)
+			}
+			if err := model.ValidateResponse(media.Schema, body, document.Components.Schemas); err != nil {
+				t.Errorf("body does not follow the documented schema: %v", err)
+			}
+		})
+	}
+}
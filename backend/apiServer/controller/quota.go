@@ -0,0 +1,105 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// queueFullRetryAfter is how long clients rejected by a full queue are asked to wait, about the time of a clone.
+const queueFullRetryAfter = 30 * time.Second
+
+// RateLimit returns a middleware responding with 429 Too Many Requests to clients out of tokens in limiter.
+// Users from RequireUser are limited by their id, others by their address.
+func RateLimit(limiter *util.RateLimiter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := "ip:" + clientIP(r)
+			if user, ok := CurrentUser(r); ok {
+				key = "user:" + user.ID.Hex()
+			}
+
+			if allowed, retryAfter := limiter.Allow(key); !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				util.TypeLogger.Warn("%s: Rate limited %s %s for %s", packageName, r.Method, r.URL.Path, key)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// waitForSlot takes one of slots for the work on the repository with repoID requested over conn.
+// While waiting the client is told its place in the queue. If the queue is full or the client leaves,
+// the socket is closed and false returned. The slot must be released when the work is done.
+func waitForSlot(conn *websocket.Conn, slots *util.Slots, repoID string) bool {
+	ready, position, err := slots.Acquire()
+	if err == util.ErrQueueFull {
+		util.TypeLogger.Warn("%s: Rejected work on %s, queue is full", packageName, repoID)
+		reason := WebsocketResponse{
+			StatusText: http.StatusText(http.StatusTooManyRequests),
+			StatusCode: http.StatusTooManyRequests,
+			Body: map[string]string{ // Short enough for a close frame
+				"id":     repoID,
+				"status": "Queue full",
+			},
+		}
+		if err := socketCloseWithResponse(conn, reason); err != nil {
+			util.TypeLogger.Error("%s: Failed to write webSocket closer: %s", packageName, err.Error())
+		}
+		return false
+	}
+
+	if position == 0 {
+		return true
+	}
+
+	response := WebsocketResponse{
+		StatusText: http.StatusText(http.StatusAccepted),
+		StatusCode: http.StatusAccepted,
+		Body: map[string]interface{}{
+			"id":       repoID,
+			"status":   "Queued",
+			"position": position,
+		},
+	}
+	if err := conn.WriteJSON(response); err != nil {
+		util.TypeLogger.Error("%s: Failed to write webSocket message: %s", packageName, err.Error())
+		slots.Cancel(ready)
+		return false
+	}
+
+	select {
+	case <-ready:
+		return true
+	case <-socketClosed(conn):
+		util.TypeLogger.Info("%s: Client left the queue for %s", packageName, repoID)
+		slots.Cancel(ready)
+		return false
+	}
+}
+
+// socketClosed returns a channel closed once the client closes conn.
+// It reads and drops the messages of the client, so nothing else may read from conn.
+func socketClosed(conn *websocket.Conn) <-chan struct{} {
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return closed
+}
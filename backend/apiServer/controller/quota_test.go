@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(util.NewRateLimiter(1, 2))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/auth/login", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := request("10.0.0.1:4000"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want %d", i, w.Code, http.StatusOK)
+		}
+	}
+
+	w := request("10.0.0.1:4001")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+
+	if w := request("10.0.0.2:4000"); w.Code != http.StatusOK {
+		t.Errorf("other client: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestWaitForSlot_queueFull(t *testing.T) {
+	slots := util.NewSlots(1, 0)
+	if _, _, err := slots.Acquire(); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := RepoController{}.upgrader().Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		if waitForSlot(conn, slots, "5c62d1904122c760dafe9341") {
+			t.Error("waitForSlot() = true with a full queue")
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	_, _, err = conn.ReadMessage()
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok || closeErr.Code != websocket.CloseNormalClosure {
+		t.Fatalf("error = %v, want normal closure", err)
+	}
+
+	var reason WebsocketResponse
+	if err := json.Unmarshal([]byte(closeErr.Text), &reason); err != nil {
+		t.Fatalf("Failed to decode close reason %q: %v", closeErr.Text, err)
+	}
+	if reason.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("statuscode = %d, want %d", reason.StatusCode, http.StatusTooManyRequests)
+	}
+}
+
+func TestSocketCloseWithResponse_long(t *testing.T) {
+	long := WebsocketResponse{
+		StatusCode: http.StatusBadRequest,
+		StatusText: http.StatusText(http.StatusBadRequest),
+		Body:       map[string]string{"status": strings.Repeat("x", 200)},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := RepoController{}.upgrader().Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		if err := socketCloseWithResponse(conn, long); err != nil {
+			t.Errorf("socketCloseWithResponse() error = %v", err)
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	// The whole response comes as a message, the close frame only has its status
+	var message WebsocketResponse
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Failed to read response message: %v", err)
+	}
+	if body, _ := message.Body.(map[string]interface{}); body["status"] != strings.Repeat("x", 200) {
+		t.Errorf("message body = %v, want the long status", message.Body)
+	}
+
+	_, _, err = conn.ReadMessage()
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok || closeErr.Code != websocket.CloseNormalClosure {
+		t.Fatalf("error = %v, want normal closure", err)
+	}
+
+	var reason WebsocketResponse
+	if err := json.Unmarshal([]byte(closeErr.Text), &reason); err != nil || reason.StatusCode != http.StatusBadRequest {
+		t.Errorf("close reason = %q, want statuscode %d", closeErr.Text, http.StatusBadRequest)
+	}
+}
+
+func TestCloneInBackground_socketClosed(t *testing.T) {
+	repo := RepoController{CloneSlots: util.NewSlots(1, 1)}
+	if _, _, err := repo.CloneSlots.Acquire(); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	handlerDone := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(handlerDone)
+		conn, err := repo.upgrader().Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		// The clone is still running when the handler gives up on its socket
+		saverChannel := repo.cloneInBackground(func(c chan model.SaveResponse) {
+			c <- model.SaveResponse{ID: "5c62d1904122c760dafe9341", StatusText: "Cloning"}
+			<-handlerDone
+			c <- model.SaveResponse{ID: "5c62d1904122c760dafe9341", StatusText: "Done"}
+		})
+
+		if err := conn.WriteJSON(<-saverChannel); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+			return
+		}
+		if _, _, err := conn.ReadMessage(); err == nil {
+			t.Error("ReadMessage() error = nil, want the socket closed")
+		}
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	var response model.SaveResponse
+	if err := conn.ReadJSON(&response); err != nil || response.StatusText != "Cloning" {
+		t.Fatalf("response = %+v, %v, want cloning", response, err)
+	}
+	conn.Close()
+
+	ready, _, err := repo.CloneSlots.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("clone slot still taken after the socket was closed")
+	}
+}
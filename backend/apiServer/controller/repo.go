@@ -4,20 +4,29 @@ package controller
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
 )
 
 var packageName = "controller"
 
 // RepoController represents metadata for a git repository.
 type RepoController struct {
-	URI string // Where the repository was found
+	URI        string         // Where the repository was found
+	Storage    config.Storage // Where repositories are cloned to
+	Origins    []string       // Origins allowed to open websockets, see CORS
+	CloneSlots *util.Slots    // Caps the clones running at once
+	ParseSlots *util.Slots    // Caps the parses running at once
+	AdminUsers []string       // Server admins, who claim repositories added before there were users
 }
 
 // WebsocketResponse is the response format of a websocket
@@ -27,25 +36,32 @@ type WebsocketResponse struct {
 	Body       interface{} `json:"body"`       // Body is the content expected by the client.
 }
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(*http.Request) bool {
-		return true
-	},
+// upgrader upgrades requests to websockets from the origins the controller allows.
+func (repo RepoController) upgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			return checkOrigin(r, repo.Origins)
+		},
+	}
 }
 
 /**
 * @api {GET} /repo/add Add new git repository to server.
 * @apiName Add repository.
 * @apiGroup Repository
-* @apiPermission none
+* @apiPermission user
 **
 
 * @apiDescription Expects a get request requesting a websocket upgrade.
 * The following assumes a websocket has been established. On success
 * the content will conatin a statuscode and statustext based on http status
 * codes and a body with the repository id and creation status.
+* The authenticated user becomes the owner of the repository. Every user
+* clones their own copy, the conflict with its id is only sent when the user
+* already added the repository. Server admins adding a repository added
+* before there were users become its owner and get the conflict instead.
 *
 * @apiParam {String} URI URI to git repository.
 *
@@ -54,6 +70,18 @@ var upgrader = websocket.Upgrader{
 *		uri: "git@github.com:zohaib194/CodebaseVisualizer3D.git"
 *	}
 *
+* @apiSuccessExample {json} Status Queued, sent while other clones take every slot:
+* 	WebSocket 1 TextMessage
+*	{
+*		"statuscode": 202
+*		"statustext": Accepted
+*		"body":{
+*			"id": ""
+*			"status": Queued
+*			"position": 3
+*		}
+*	}
+*
 * @apiSuccessExample {json} Status Cloning:
 * 	WebSocket 1 TextMessage
 *	{
@@ -86,6 +114,24 @@ var upgrader = websocket.Upgrader{
 *	WebSocket 8 CloseMessage 1000 CloseNormalClosure
 *
 *		Invalid json
+*
+*
+* @apiErrorExample {json} Too many clones waiting.
+*	WebSocket 8 CloseMessage 1000 CloseNormalClosure
+*	{
+*		"statuscode": 429
+*		"statustext": Too Many Requests
+*		"body":{
+*			"id": ""
+*			"status": Queue full
+*		}
+*	}
+*
+* @apiErrorExample {text/plain} Too many requests.
+*	HTTP/1.1 429 Too Many Requests
+*	Retry-After: 12
+*
+*		Too Many Requests
 *
  */
 
@@ -96,18 +142,20 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 	defer util.TypeLogger.Info("%s: Ended request for new repo", packageName)
 
 	http.Header.Add(w.Header(), "content-type", "application/json")
-	http.Header.Add(w.Header(), "Access-Control-Allow-Origin", "*")
 
 	// Uses get to setup websocket
 	if r.Method == "GET" {
-		conn, err := upgrader.Upgrade(w, r, nil)
+		user, _ := CurrentUser(r)
+
+		conn, err := repo.upgrader().Upgrade(w, r, nil)
 		if err != nil {
 			http.Error(w, "Expected to established WebSocket", http.StatusBadRequest)
 			util.TypeLogger.Error("%s: Failed to upgrade to websocket: %s", packageName, err.Error())
 			return
 		}
+		defer trackSocket(conn)()
 
-		messageType, r, err := conn.NextReader()
+		messageType, reader, err := conn.NextReader()
 		if err != nil {
 			util.TypeLogger.Error("%s: Failed to read websocket message: %s", packageName, err.Error())
 			return
@@ -130,7 +178,7 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 			return
 		}
 
-		decoder := json.NewDecoder(r)
+		decoder := json.NewDecoder(reader)
 		var postData map[string]string
 
 		if err := decoder.Decode(&postData); err != nil {
@@ -181,9 +229,31 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 		}
 		repo.URI = postData["uri"]
 
-		// Setting up channel and go routine to save the new repo in database and on file
-		saverChannel := make(chan model.SaveResponse)
-		go model.RepoModel{URI: repo.URI}.Save(saverChannel)
+		// Server admins re-adding a repository added before there were users claim it
+		if claimedID := repo.claimUnownedRepo(r, repo.URI, user); len(claimedID) > 0 {
+			err = socketCloseWithResponse(conn, WebsocketResponse{
+				StatusText: http.StatusText(http.StatusConflict),
+				StatusCode: http.StatusConflict,
+				Body: map[string]string{
+					"id":     claimedID,
+					"status": "Repository already exists",
+				},
+			})
+			if err != nil {
+				util.TypeLogger.Error("%s: Failed to write webSocket closer: %s", packageName, err.Error())
+			}
+			return
+		}
+
+		// Wait for a free clone slot
+		if !waitForSlot(conn, repo.CloneSlots, "") {
+			return
+		}
+
+		// Save the new repo in database and on file in the background
+		saverChannel := repo.cloneInBackground(func(c chan model.SaveResponse) {
+			model.RepoModel{URI: repo.URI, Owner: user.ID}.Save(repo.Storage, c)
+		})
 
 		// Expecting response of save to contain save status and potential error.
 		saverResponse := <-saverChannel
@@ -192,6 +262,8 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 		for {
 
 			if saverResponse.Err != nil {
+				recordAudit(user, clientIP(r), model.AuditAdd, saverResponse.ID, model.OutcomeFailure, repo.URI+": "+saverResponse.Err.Error())
+
 				if saverResponse.Err.Error() == "Already exists" {
 					util.TypeLogger.Info("%s: Request conflicted with existing repository", packageName)
 					reason := WebsocketResponse{
@@ -264,6 +336,8 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 					return
 				}
 			} else if saverResponse.StatusText == "Done" {
+				recordAudit(user, clientIP(r), model.AuditAdd, saverResponse.ID, model.OutcomeSuccess, repo.URI)
+
 				response := WebsocketResponse{
 					StatusText: http.StatusText(http.StatusCreated),
 					StatusCode: http.StatusCreated,
@@ -308,20 +382,34 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 * @api {GET} /repo/:id/initial/ Parse the repository assosiated with id.
 * @apiName Parse repository.
 * @apiGroup Repository
-* @apiPermission none
+* @apiPermission viewer
 *
 * @apiParam {String} Id Id of submitted git repository.
+* @apiParam {String} [include] Comma separated globs, only files matching one of them are parsed.
+* @apiParam {String} [exclude] Comma separated globs for files and directories that are skipped.
 *
 * @apiDescription Expects a get request requesting a websocket upgrade.
+* Files ignored by ".gitignore" and filtered by the include and exclude
+* lists of the repository's configuration are never considered, and files
+* are parsed based on the configured language of their extension.
 * The following assumes a websocket has been established. On success
 * the content will conatin a statuscode and statustext based on http status
 * codes and a body.
 * The body can contains:
-*	  	CurrentFile - file last parsed
-*		ParsedFileCount - How many files have been parsed at current time
-*		SkippedFileCount - How many files considered but not parsed, usualy if language is not supported
-*		FileCount - How many files in the repository being considered
-*		Result - The final result of the completed parsing, only for last message
+*		id - Id of the repository
+*		status - "Queued", "Parsing", "Done" or "Interrupted"
+*		currentFile - file last parsed, while parsing
+*		parsedFileCount - How many files have been parsed at current time
+*		skippedFileCount - How many files considered but not parsed, usualy if language is not supported
+*		fileCount - How many files in the repository being considered
+*		result - The final result of the completed parsing, only for last message
+* While other parses take every slot, the client is first sent the status
+* "Queued" with its position in the queue. If the queue is full, the socket
+* is closed with statuscode 429. Clients over their rate limit get
+* 429 Too Many Requests with a Retry-After header instead of the upgrade.
+* When the server shuts down, parsing stops after the current file and the
+* socket is closed as going away with statuscode 503 and the status
+* "Interrupted". The next parse of the repository continues where it stopped.
 *
 * @apiParamExample {url} Parse repository:
 *     {
@@ -335,78 +423,40 @@ func (repo RepoController) NewRepoFromURI(w http.ResponseWriter, r *http.Request
 *			"statuscode": 200,
 *			"statustext": "OK",
 *			"body": {
-*			  "FileCount": 5,
+*			  "fileCount": 5,
 *			  "id": "5c7ea320b7fa7003137f003e",
 *			  "parsedFileCount": 1,
 *			  "result": {
+*			    "schema_version": 2,
 *			    "files": [
 *			      {
-*			        "file": {
-*			          "parsed": false,
-*			          "file_name": "5c7ea320b7fa7003137f003e/.gitignore",
-*			          "functions": null,
-*			          "namespaces": null,
-*			          "classes": null,
-*			          "linesInFile": 0
-*			        }
-*			      },
-*			      {
-*			        "file": {
-*			          "parsed": true,
-*			          "file_name": "5c7ea320b7fa7003137f003e/HelloWorld/Main.java",
-*			          "functions": null,
-*			          "namespaces": [
-*			            {
-*			              "namespace": {
-*			                "functions": [
-*			                  {
-*			                    "function": {
-*			                      "name": "main(String[]args)",
-*			                      "start_line": 6,
-*			                      "end_line": 8
-*			                    }
-*			                  }
-*			                ],
-*			                "name": "HelloWorld",
-*			                "namespaces": null,
-*			                "classes": null
-*			              },
-*			              "line_nr": 0
-*			            }
-*			          ],
-*			          "classes": null,
-*			          "linesInFile": 8
-*			        }
+*			        "parsed": false,
+*			        "file_name": "5c7ea320b7fa7003137f003e/.gitignore",
+*			        "linesInFile": 0
 *			      },
 *			      {
-*			        "file": {
-*			          "parsed": false,
-*			          "file_name": "5c7ea320b7fa7003137f003e/LICENSE",
-*			          "functions": null,
-*			          "namespaces": null,
-*			          "classes": null,
-*			          "linesInFile": 0
-*			        }
+*			        "parsed": true,
+*			        "file_name": "5c7ea320b7fa7003137f003e/HelloWorld/Main.java",
+*			        "namespaces": [
+*			          {
+*			            "name": "HelloWorld",
+*			            "functions": [
+*			              {
+*			                "name": "main(String[]args)",
+*			                "declrator_id": "main",
+*			                "function_body": {},
+*			                "start_line": 6,
+*			                "end_line": 8
+*			              }
+*			            ]
+*			          }
+*			        ],
+*			        "linesInFile": 8
 *			      },
 *			      {
-*			        "file": {
-*			          "parsed": false,
-*			          "file_name": "5c7ea320b7fa7003137f003e/Manifest.txt",
-*			          "functions": null,
-*			          "namespaces": null,
-*			          "classes": null,
-*			          "linesInFile": 0
-*			        }
-*			      },
-*			      {
-*			        "file": {
-*			          "parsed": false,
-*			          "file_name": "5c7ea320b7fa7003137f003e/README.rst",
-*			          "functions": null,
-*			          "namespaces": null,
-*			          "classes": null,
-*			          "linesInFile": 0
-*			        }
+*			        "parsed": false,
+*			        "file_name": "5c7ea320b7fa7003137f003e/README.rst",
+*			        "linesInFile": 0
 *			      }
 *			    ]
 *			  },
@@ -449,21 +499,55 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 	util.TypeLogger.Info("%s: Received request for initial data", packageName)
 	defer util.TypeLogger.Info("%s: Ended request for initial data", packageName)
 
+	repo.parse(w, r, false)
+}
+
+/**
+* @api {GET} /repo/:id/reparse/ Parse the repository assosiated with id again.
+* @apiName Reparse repository.
+* @apiGroup Repository
+* @apiPermission analyst
+*
+* @apiParam {String} Id Id of submitted git repository.
+* @apiParam {String} [include] Comma separated globs, only files matching one of them are parsed.
+* @apiParam {String} [exclude] Comma separated globs for files and directories that are skipped.
+*
+* @apiDescription Works like /repo/:id/initial/, but parses the repository even if it was parsed before,
+* replacing the stored result. Use it after changing the configuration of the repository.
+ */
+
+// Reparse parses a repository again, replacing the stored result.
+func (repo RepoController) Reparse(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for reparse", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for reparse", packageName)
+
+	repo.parse(w, r, true)
+}
+
+// parse streams the parsing of the repository of the request over a websocket.
+// Unless reparse is set, a stored result is sent instead of parsing again.
+func (repo RepoController) parse(w http.ResponseWriter, r *http.Request, reparse bool) {
+	action := model.AuditParse
+	if reparse {
+		action = model.AuditReparse
+	}
+
 	http.Header.Add(w.Header(), "content-type", "application/json")
-	http.Header.Add(w.Header(), "Access-Control-Allow-Origin", "*")
 
 	if r.Method == "GET" {
+		user, _ := CurrentUser(r)
 
-		conn, err := upgrader.Upgrade(w, r, nil)
+		conn, err := repo.upgrader().Upgrade(w, r, nil)
 		if err != nil {
 			http.Error(w, "Expected to established WebSocket", http.StatusBadRequest)
 			util.TypeLogger.Error("%s: Failed to upgrade to websocket: %s", packageName, err.Error())
 			return
 		}
+		defer trackSocket(conn)()
 		vars := mux.Vars(r)
 
 		// Validate that the project exist in DB.
-		exstRepo, err := model.RepoModel{}.GetRepoByID(vars["repoId"])
+		exstRepo, err := model.RepoModel{}.GetRepoByID(repo.Storage, vars["repoId"])
 
 		if err != nil {
 			util.TypeLogger.Error("%s: Failed to find repository in database: %s", packageName, err.Error())
@@ -481,7 +565,7 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		if len(exstRepo.ParsedRepo.Files) > 0 {
+		if len(exstRepo.ParsedRepo.Files) > 0 && !reparse {
 			util.TypeLogger.Error("%v", exstRepo.ParsedRepo)
 			// Respond with message
 			reason := WebsocketResponse{
@@ -514,11 +598,15 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		// List all files in the repository directory.
-		files, err := exstRepo.GetRepoFiles()
+		// List all files in the repository directory, honoring globs given with the request.
+		files, err := exstRepo.GetRepoFiles(repo.Storage, model.FileFilter{
+			Include: splitQueryList(r.URL.Query()["include"]),
+			Exclude: splitQueryList(r.URL.Query()["exclude"]),
+		})
 
 		if err != nil {
 			util.TypeLogger.Error("%s: Failed to find repository files: %s", packageName, err.Error())
+			recordAudit(user, clientIP(r), action, vars["repoId"], model.OutcomeFailure, err.Error())
 			reason := WebsocketResponse{
 				StatusText: http.StatusText(http.StatusInternalServerError),
 				StatusCode: http.StatusInternalServerError,
@@ -533,6 +621,11 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		// Wait for a free parse slot
+		if !waitForSlot(conn, repo.ParseSlots, vars["repoId"]) {
+			return
+		}
+
 		// Tell the client that the request was accepted
 		response := WebsocketResponse{
 			StatusText: http.StatusText(http.StatusAccepted),
@@ -545,17 +638,40 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 
 		if err := conn.WriteJSON(response); err != nil {
 			util.TypeLogger.Error("%s: Failed to write webSocket message: %s", packageName, err.Error())
+			repo.ParseSlots.Release()
 			return
 		}
 		// Setting up channel and go routine to parse all files in repository
 		parseChannel := make(chan model.ParseResponse)
-		go exstRepo.ParseDataFromFiles(files, 1, parseChannel)
+		go func() {
+			defer repo.ParseSlots.Release()
+			exstRepo.ParseDataFromFiles(repo.Storage, files, 1, parseChannel)
+		}()
 
 		// Expecting response of parser to contain save status, potential error and potential result.
 		parserResponse := <-parseChannel
 		for {
+			if parserResponse.Err == model.ErrInterrupted {
+				util.TypeLogger.Info("%s: Parsing of %s interrupted by shutdown", packageName, vars["repoId"])
+				recordAudit(user, clientIP(r), action, vars["repoId"], model.OutcomeFailure,
+					fmt.Sprintf("interrupted after %d of %d files", parserResponse.ParsedFileCount+parserResponse.SkippedFileCount, parserResponse.FileCount))
+				reason := WebsocketResponse{
+					StatusText: http.StatusText(http.StatusServiceUnavailable),
+					StatusCode: http.StatusServiceUnavailable,
+					Body: map[string]string{
+						"id":     vars["repoId"],
+						"status": "Interrupted",
+					},
+				}
+				if err := socketGoingAwayWithResponse(conn, reason); err != nil {
+					util.TypeLogger.Error("%s: Failed to write webSocket closer: %s", packageName, err.Error())
+				}
+				return
+			}
+
 			if parserResponse.Err != nil {
-				util.TypeLogger.Error("%s: Failed to parse files: %s", packageName, err.Error())
+				util.TypeLogger.Error("%s: Failed to parse files: %s", packageName, parserResponse.Err.Error())
+				recordAudit(user, clientIP(r), action, vars["repoId"], model.OutcomeFailure, parserResponse.Err.Error())
 				reason := WebsocketResponse{
 					StatusText: http.StatusText(http.StatusInternalServerError),
 					StatusCode: http.StatusInternalServerError,
@@ -590,6 +706,9 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 				}
 
 			} else { // if done
+				recordAudit(user, clientIP(r), action, vars["repoId"], model.OutcomeSuccess,
+					fmt.Sprintf("%d of %d files parsed", parserResponse.ParsedFileCount, parserResponse.FileCount))
+
 				// Respond with message
 				reason := WebsocketResponse{
 					StatusText: http.StatusText(http.StatusOK),
@@ -632,10 +751,10 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 }
 
 /**
-* @api {Get} /repo/list Get all git repository stored in server.
+* @api {Get} /repo/list Get all git repository the user has access to.
 * @apiName Get Repositories List.
 * @apiGroup Repository
-* @apiPermission none
+* @apiPermission user
 *
 *
 * @apiSuccessExample {json} Success-Response:
@@ -663,16 +782,16 @@ func (repo RepoController) ParseInitial(w http.ResponseWriter, r *http.Request)
 *	}
  */
 
-// GetAllRepos gets all repositories stored.
+// GetAllRepos gets all repositories the authenticated user owns or is a member of.
 func (repo RepoController) GetAllRepos(w http.ResponseWriter, r *http.Request) {
 	util.TypeLogger.Info("%s: Received request for repository list", packageName)
 	defer util.TypeLogger.Info("%s: Ended request for repository list", packageName)
 
 	http.Header.Add(w.Header(), "content-type", "application/json")
-	http.Header.Add(w.Header(), "Access-Control-Allow-Origin", "*")
 
 	if r.Method == "GET" {
-		repos, err := model.RepoModel{}.FetchAll()
+		user, _ := CurrentUser(r)
+		repos, err := model.RepoModel{}.FetchAllForUser(user.ID)
 
 		if err != nil {
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -691,10 +810,117 @@ func (repo RepoController) GetAllRepos(w http.ResponseWriter, r *http.Request) {
 
 }
 
+/**
+* @api {DELETE} /repo/:repoId Delete a repository.
+* @apiName Delete Repository.
+* @apiGroup Repository
+* @apiPermission admin
+*
+* @apiParam {String} repoId Id of submitted git repository.
+*
+* @apiDescription Removes the repository, its parsed data and its clone for every member.
+*
+* @apiSuccessExample {text/plain} Success-Response:
+* 	HTTP/1.1 204 No Content
+ */
+
+// DeleteRepo deletes a repository and its clone.
+func (repo RepoController) DeleteRepo(w http.ResponseWriter, r *http.Request) {
+	util.TypeLogger.Info("%s: Received request for repository deletion", packageName)
+	defer util.TypeLogger.Info("%s: Ended request for repository deletion", packageName)
+
+	if r.Method == "DELETE" {
+		user, _ := CurrentUser(r)
+
+		exstRepo, ok := findRepo(w, repo.Storage, mux.Vars(r)["repoId"])
+		if !ok {
+			return
+		}
+
+		if err := exstRepo.Delete(repo.Storage); err != nil {
+			recordAudit(user, clientIP(r), model.AuditDelete, exstRepo.ID.Hex(), model.OutcomeFailure, err.Error())
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		recordAudit(user, clientIP(r), model.AuditDelete, exstRepo.ID.Hex(), model.OutcomeSuccess, exstRepo.URI)
+		w.WriteHeader(http.StatusNoContent)
+
+	} else { // if not DELETE request
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		util.TypeLogger.Warn("%s: Received unsuported method", packageName)
+		return
+	}
+}
+
+// saveResponses is the most responses model.RepoModel.Save sends.
+const saveResponses = 2
+
+// cloneInBackground runs save, cloning a repository, and gives back the clone slot once it is done.
+// The returned channel holds every response, so save finishes and frees the slot even when the caller
+// stops reading, like a handler whose websocket was closed during the clone.
+func (repo RepoController) cloneInBackground(save func(c chan model.SaveResponse)) chan model.SaveResponse {
+	saverChannel := make(chan model.SaveResponse, saveResponses)
+	go func() {
+		defer repo.CloneSlots.Release()
+		save(saverChannel)
+	}()
+
+	return saverChannel
+}
+
+// claimUnownedRepo makes user the owner of the repository at uri added before there were users, if user is
+// a server admin, and returns its id. Other users and uris without such a repository get an empty id.
+func (repo RepoController) claimUnownedRepo(r *http.Request, uri string, user model.UserModel) string {
+	if !user.IsAdmin(repo.AdminUsers) {
+		return ""
+	}
+
+	claimed, err := model.RepoModel{URI: uri}.ClaimUnowned(user.ID)
+	if err != nil || !claimed.ID.Valid() {
+		return ""
+	}
+
+	recordAudit(user, clientIP(r), model.AuditAdd, claimed.ID.Hex(), model.OutcomeSuccess, uri+": claimed unowned repository")
+	util.TypeLogger.Info("%s: User %s claimed repository %s", packageName, user.Name, claimed.ID.Hex())
+	return claimed.ID.Hex()
+}
+
+// findRepo looks up the repository with repoID cloned to storage, responding with not found if it does not exist.
+func findRepo(w http.ResponseWriter, storage config.Storage, repoID string) (model.RepoModel, bool) {
+	if !bson.IsObjectIdHex(repoID) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		util.TypeLogger.Warn("%s: Received invalid repository id", packageName)
+		return model.RepoModel{}, false
+	}
+
+	exstRepo, err := model.RepoModel{}.GetRepoByID(storage, repoID)
+	if err != nil || !exstRepo.ID.Valid() {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		util.TypeLogger.Warn("%s: Failed to find repository: %s", packageName, repoID)
+		return model.RepoModel{}, false
+	}
+
+	return exstRepo, true
+}
+
+// splitQueryList flattens repeated and comma separated query values into one list.
+func splitQueryList(values []string) (list []string) {
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				list = append(list, item)
+			}
+		}
+	}
+
+	return list
+}
+
 func socketCloseWithResponse(conn *websocket.Conn, reason WebsocketResponse) error {
 	util.TypeLogger.Debug("%s: Received request for repository list", packageName)
 	defer util.TypeLogger.Debug("%s: Ended request for repository list", packageName)
-	jsonResponse, err := json.Marshal(reason)
+	closeReason, err := socketCloseReason(conn, reason)
 	if err != nil {
 		return err
 	}
@@ -703,8 +929,30 @@ func socketCloseWithResponse(conn *websocket.Conn, reason WebsocketResponse) err
 		websocket.CloseMessage,
 		websocket.FormatCloseMessage(
 			websocket.CloseNormalClosure,
-			string(jsonResponse),
+			closeReason,
 		),
 	)
 	return err
 }
+
+// maxCloseReason is the longest reason of a close frame, which carries at most 125 bytes with its 2 byte code.
+const maxCloseReason = 123
+
+// socketCloseReason encodes reason for a close frame of conn. Responses too long for it are written
+// as a text message first, and the close frame only carries their status.
+func socketCloseReason(conn *websocket.Conn, reason WebsocketResponse) (string, error) {
+	jsonResponse, err := json.Marshal(reason)
+	if err != nil {
+		return "", err
+	}
+	if len(jsonResponse) <= maxCloseReason {
+		return string(jsonResponse), nil
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, jsonResponse); err != nil {
+		return "", err
+	}
+
+	jsonResponse, err = json.Marshal(WebsocketResponse{StatusCode: reason.StatusCode, StatusText: reason.StatusText})
+	return string(jsonResponse), err
+}
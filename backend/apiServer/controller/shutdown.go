@@ -0,0 +1,70 @@
+//Package controller refers to controll part of mvc.
+//It performs validation, errorhandling and buisness logic
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// closeGracePeriod is how long closing messages may take to reach clients on shutdown.
+const closeGracePeriod = 5 * time.Second
+
+// openSockets are the websockets of running requests, closed by CloseSockets.
+var (
+	socketsMutex sync.Mutex
+	openSockets  = make(map[*websocket.Conn]bool)
+)
+
+// trackSocket adds conn to the open sockets, the returned function removes it again.
+func trackSocket(conn *websocket.Conn) func() {
+	socketsMutex.Lock()
+	defer socketsMutex.Unlock()
+
+	openSockets[conn] = true
+
+	return func() {
+		socketsMutex.Lock()
+		defer socketsMutex.Unlock()
+
+		delete(openSockets, conn)
+	}
+}
+
+// CloseSockets tells the clients of every open websocket that the server is going away and closes them.
+// The http server does not know of websockets, so they must be closed after it is shut down.
+func CloseSockets() {
+	util.TypeLogger.Debug("%s: Call to CloseSockets", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to CloseSockets", packageName)
+
+	socketsMutex.Lock()
+	defer socketsMutex.Unlock()
+
+	for conn := range openSockets {
+		message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "Server shutting down")
+		if err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(closeGracePeriod)); err != nil {
+			util.TypeLogger.Warn("%s: Failed to write webSocket closer: %s", packageName, err.Error())
+		}
+		conn.Close()
+		delete(openSockets, conn)
+	}
+}
+
+// socketGoingAwayWithResponse closes conn with reason as the server is going away.
+func socketGoingAwayWithResponse(conn *websocket.Conn, reason WebsocketResponse) error {
+	closeReason, err := socketCloseReason(conn, reason)
+	if err != nil {
+		return err
+	}
+
+	return conn.WriteMessage(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(
+			websocket.CloseGoingAway,
+			closeReason,
+		),
+	)
+}
@@ -1,13 +1,21 @@
-//main deals with environment and api definition.
+//main deals with configuration and api definition.
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"flag"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/controller"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/oidc"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
 )
 
@@ -15,51 +23,112 @@ var packageName = "main"
 
 // Indicate the importance of a warning
 func main() {
-	router := mux.NewRouter()
-
-	// Get environment variables
-	port := os.Getenv("PORT")
-	model.RepoPath = os.Getenv("REPOSITORY_PATH")
-	dbLocation := os.Getenv("DB_LOCATION")
-	model.JavaParserPath = os.Getenv("JAVA_PARSER")
-	logLevel := os.Getenv("LOG_LEVEL")
-	logFile := os.Getenv("LOG_FILE")
-
-	// Validate variables
-	if len(port) == 0 {
-		util.TypeLogger.Fatal("$PORT was not set")
+	// Read configuration from file, environment and flags
+	cfg, err := config.Load(os.Args[0], os.Args[1:], os.Getenv)
+	if err == flag.ErrHelp {
+		return
 	}
-	if len(model.RepoPath) == 0 {
-		util.TypeLogger.Fatal("$REPOSITORY_PATH was not set")
+	if err != nil {
+		util.TypeLogger.Fatal("%s", err.Error())
 	}
-	if len(dbLocation) == 0 {
-		util.TypeLogger.Fatal("$DB_LOCATION was not set")
+
+	if len(cfg.Log.Level) == 0 || !util.SetLogLevel(cfg.Log.Level) {
+		util.TypeLogger.Warn("log.level not set")
 	}
-	if len(model.JavaParserPath) == 0 {
-		util.TypeLogger.Fatal("$JAVA_PARSER was not set")
+	if len(cfg.Log.File) == 0 || !util.SetLogFile(cfg.Log.File) {
+		util.TypeLogger.Warn("log.file not set, fallback to stdout")
 	}
-	if len(logLevel) == 0 || !util.SetLogLevel(logLevel) {
-		util.TypeLogger.Warn("$LOG_LEVEL not set")
+	if len(cfg.Auth.AdminUsers) == 0 {
+		util.TypeLogger.Warn("auth.admin_users not set, nobody can read the audit log")
 	}
-	if len(logFile) == 0 || !util.SetLogFile(logFile) {
-		util.TypeLogger.Warn("$LOG_FILE not set, fallback to stdout")
+	if len(cfg.Server.CORSOrigins) == 0 {
+		util.TypeLogger.Warn("server.cors_origins not set, only websites on the same host can use the api")
 	}
 
 	// Database setup
 	util.TypeLogger.Info("%s: Setting up database", packageName)
-	model.DB.DatabaseURL = dbLocation
+	model.DB.DatabaseURL = cfg.Database.Location
 	if err := model.DB.Init(); err != nil {
 		util.TypeLogger.Fatal("Could not initialize database")
 	}
 
-	// API routings
-	util.TypeLogger.Info("%s: Setting up api routes", packageName)
-	router.HandleFunc("/repo/add", controller.RepoController{}.NewRepoFromURI)
-	router.HandleFunc("/repo/list", controller.RepoController{}.GetAllRepos)
-	router.HandleFunc("/repo/{repoId}/initial/", controller.RepoController{}.ParseInitial)
-	router.HandleFunc("/repo/{repoId}/file/read/", controller.CodeSnippetController{}.GetImplementation)
+	router := newRouter(cfg, oidcLogin(cfg.Auth.OIDC))
+
+	// Start server, websockets keep their connection without the timeouts
+	port := strconv.Itoa(cfg.Server.Port)
+	server := &http.Server{
+		Addr:         ":" + port,
+		Handler:      controller.CORS(cfg.Server.CORSOrigins)(router),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		TLSConfig:    &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	stopped := make(chan struct{})
+	go shutdownOnSignal(server, cfg.Server.ShutdownTimeout, stopped)
+
+	if cfg.TLS() {
+		util.TypeLogger.Info("%s: Listening with TLS on port: %s", packageName, port)
+		err = server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+	} else {
+		util.TypeLogger.Info("%s: Listening on port: %s", packageName, port)
+		err = server.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		util.TypeLogger.Fatal("%s: Failed to serve: %s", packageName, err.Error())
+	}
+
+	<-stopped
+	util.TypeLogger.Info("%s: Server stopped", packageName)
+}
+
+// shutdownOnSignal shuts down server on SIGTERM or SIGINT, closing stopped when done.
+// In-flight requests and parses get timeout to finish, parses are checkpointed and websockets closed as going away.
+func shutdownOnSignal(server *http.Server, timeout time.Duration, stopped chan struct{}) {
+	defer close(stopped)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	received := <-signals
+	util.TypeLogger.Info("%s: Received %s, shutting down", packageName, received)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Stop accepting requests and drain the running ones while parses checkpoint
+	drained := make(chan error, 1)
+	go func() {
+		drained <- server.Shutdown(ctx)
+	}()
+
+	if err := model.StopParsing(ctx); err != nil {
+		util.TypeLogger.Warn("%s: Parses were not checkpointed in time: %s", packageName, err.Error())
+	}
+	if err := <-drained; err != nil {
+		util.TypeLogger.Warn("%s: Requests were not drained in time: %s", packageName, err.Error())
+	}
+
+	controller.CloseSockets()
+}
+
+// oidcLogin sets up logins with the OpenID Connect provider configured by oidcConfig, nil if there is none.
+func oidcLogin(oidcConfig config.OIDC) *controller.OIDCController {
+	if len(oidcConfig.Issuer) == 0 {
+		util.TypeLogger.Info("%s: auth.oidc.issuer not set, only local accounts can log in", packageName)
+		return nil
+	}
+
+	provider, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:       oidcConfig.Issuer,
+		ClientID:     oidcConfig.ClientID,
+		ClientSecret: oidcConfig.ClientSecret,
+		RedirectURL:  oidcConfig.RedirectURL,
+		Scopes:       oidcConfig.Scopes,
+	}, nil)
+	if err != nil {
+		util.TypeLogger.Fatal("Could not discover OpenID Connect provider: %s", err.Error())
+	}
 
-	// Start server
-	util.TypeLogger.Info("%s: Listening on port: %s", packageName, port)
-	http.ListenAndServe(":"+port, router)
+	return &controller.OIDCController{Provider: provider, AfterLogin: oidcConfig.AfterLoginURL}
 }
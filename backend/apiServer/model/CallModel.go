@@ -2,6 +2,6 @@ package model
 
 // CallModel represents a function call from code.
 type CallModel struct {
-	Identifier string       `json:"identifier"`
+	Identifier string       `json:"identifier" schema:"required"`
 	Scope      []ScopeModel `json:"scopes,omitempty"`
 }
@@ -6,9 +6,6 @@ import (
 	"errors"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
 	"gopkg.in/mgo.v2/bson"
-	"os/exec"
-	"strconv"
-	"strings"
 )
 
 // CodeSnippetModel represents metadata for a file from a git project.
@@ -20,39 +17,66 @@ type CodeSnippetModel struct {
 }
 
 // FetchLinesOfCode fetch loc from specified range.
-func (codeSnippet CodeSnippetModel) FetchLinesOfCode() (string, error) {
+// The file must be inside the clone of the repository with the snippets ID in repoPath.
+func (codeSnippet CodeSnippetModel) FetchLinesOfCode(repoPath string) (string, error) {
 	util.TypeLogger.Info("%s: Received request for implementation", packageName)
 	defer util.TypeLogger.Info("%s: Ended request for implementation", packageName)
 
-	// Ready  word count command and execute it.
-	cmd := exec.Command("wc", "-l", RepoPath+"/"+codeSnippet.FilePath)
-	output, err := cmd.CombinedOutput()
-
+	filename, err := ResolveRepoFile(repoPath, codeSnippet.ID.Hex(), codeSnippet.FilePath)
 	if err != nil {
-		util.TypeLogger.Error("%s: Failed to count lines in file: %s", packageName, err.Error())
-		return "", errors.New("Failed to count lines in file")
+		return "", err
 	}
 
-	// Split the output on space and grab the first entry (lines in file)
-	linesTotal, err := strconv.Atoi(strings.Split(string(output), " ")[0])
+	implementation, err := ReadLines(filename, codeSnippet.StartLine, codeSnippet.EndLine)
 
-	if codeSnippet.StartLine > linesTotal {
+	if err == ErrLineOutOfRange {
 		util.TypeLogger.Warn("%s: StartLine out of range", packageName)
-		return "", errors.New("StartLine out of range")
+		return "", err
 	}
 	if err != nil {
-		util.TypeLogger.Error("%s: Failed to convert string to int: %s", packageName, err.Error())
-		return "", errors.New("Failed to count lines in file")
+		util.TypeLogger.Error("%s: Failed to read lines in file: %s", packageName, err.Error())
+		return "", errors.New("Failed to read lines in file")
 	}
 
-	// Command to run sed for fetching file content.
-	cmd = exec.Command("sed", "-n", strconv.Itoa(codeSnippet.StartLine)+","+strconv.Itoa(codeSnippet.EndLine)+"p;", RepoPath+"/"+codeSnippet.FilePath)
-	output, err = cmd.CombinedOutput()
+	return implementation, nil
+}
+
+// FetchTokens tokenizes the file in language and returns the tokens in the snippets range.
+// Identifiers are annotated with the symbols of file, the parsed model of the same file.
+// The file is lexed from the start so comments and strings opened before the range are recognized.
+func (codeSnippet CodeSnippetModel) FetchTokens(repoPath string, language string, file FileModel) ([]TokenModel, error) {
+	util.TypeLogger.Debug("%s: Call to FetchTokens", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FetchTokens", packageName)
 
+	filename, err := ResolveRepoFile(repoPath, codeSnippet.ID.Hex(), codeSnippet.FilePath)
 	if err != nil {
-		util.TypeLogger.Error("%s: Error executing sed parser: %s", packageName, err.Error())
-		return "", err
+		return nil, err
 	}
 
-	return string(output), err
+	lines, err := CountLines(filename)
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to count lines in file: %s", packageName, err.Error())
+		return nil, errors.New("Failed to read lines in file")
+	}
+	if codeSnippet.StartLine > lines {
+		util.TypeLogger.Warn("%s: StartLine out of range", packageName)
+		return nil, ErrLineOutOfRange
+	}
+
+	source, err := ReadLines(filename, 1, codeSnippet.EndLine)
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to read lines in file: %s", packageName, err.Error())
+		return nil, errors.New("Failed to read lines in file")
+	}
+
+	var tokens []TokenModel
+	for _, token := range Tokenize(source, language) {
+		if token.Line >= codeSnippet.StartLine {
+			tokens = append(tokens, token)
+		}
+	}
+
+	AnnotateTokens(tokens, file)
+
+	return tokens, nil
 }
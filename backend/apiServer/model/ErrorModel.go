@@ -0,0 +1,15 @@
+package model
+
+import "net/http"
+
+// ErrorModel describes a failed request or job of the versioned api.
+type ErrorModel struct {
+	Status  int    `json:"status"`  // Http status code
+	Title   string `json:"title"`   // Http status text of Status
+	Message string `json:"message"` // What went wrong
+}
+
+// NewError creates an error with status and message.
+func NewError(status int, message string) *ErrorModel {
+	return &ErrorModel{Status: status, Title: http.StatusText(status), Message: message}
+}
@@ -7,13 +7,14 @@ type FileWrapperModel struct {
 
 // FileModel represents a single code file
 type FileModel struct {
-	Parsed          bool                  `json:"parsed"`
-	FileName        string                `json:"file_name"`
+	Parsed          bool                  `json:"parsed" schema:"server"`
+	FileName        string                `json:"file_name" schema:"required"`
 	Functions       []FunctionModel       `json:"functions,omitempty"`
 	Namespaces      []NamespaceModel      `json:"namespaces,omitempty"`
 	UsingNamespaces []UsingNamespaceModel `json:"using_namespaces,omitempty"`
 	Includes        []string              `json:"includes,omitempty"`
+	IncludedFiles   []string              `json:"included_files,omitempty" schema:"server"` // Repository files the includes resolve to
 	Classes         []ClassModel          `json:"classes,omitempty"`
 	Variables       []VariableModel       `json:"variables,omitempty"`
-	LinesInFile     int                   `json:"linesInFile"`
+	LinesInFile     int                   `json:"linesInFile" schema:"server"`
 }
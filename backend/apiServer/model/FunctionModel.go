@@ -2,12 +2,16 @@ package model
 
 // FunctionModel represents code for a single function
 type FunctionModel struct {
-	Name         string            `json:"name"`
+	Name         string            `json:"name" schema:"required"`
 	DeclID       string            `json:"declrator_id"`
 	ReturnType   string            `json:"return_type,omitempty"`
 	FunctionBody FunctionBodyModel `json:"function_body,omitempty"`
 	Parameters   []ParameterModel  `json:"parameters,omitempty"`
 	Scope        string            `json:"scope,omitempty"`
-	StartLine    int               `json:"start_line"`
-	EndLine      int               `json:"end_line"`
+	StartLine    int               `json:"start_line" schema:"required"`
+	EndLine      int               `json:"end_line" schema:"required"`
+
+	// Overlay set by dead code detection.
+	Dead           bool   `json:"dead,omitempty" schema:"server"`            // Not reachable from any entry point
+	DeadConfidence string `json:"dead_confidence,omitempty" schema:"server"` // Confidence level of Dead
 }
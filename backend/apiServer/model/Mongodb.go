@@ -16,10 +16,13 @@ type MongoDB struct {
 	DatabaseURL  string
 	DatabaseName string
 	RepoColl     string
+	UserColl     string
+	TokenColl    string
+	AuditColl    string
 }
 
-// DB is a mongo database with name CodeVis3D and collection gitRepository
-var DB = &MongoDB{"mongodb://localhost", "CodeVis3D", "gitRepository"}
+// DB is a mongo database with name CodeVis3D and collections gitRepository, users, tokens and audit
+var DB = &MongoDB{"mongodb://localhost", "CodeVis3D", "gitRepository", "users", "tokens", "audit"}
 
 // Init - initializes the mongoDB database
 func (db *MongoDB) Init() error {
@@ -36,13 +39,22 @@ func (db *MongoDB) Init() error {
 		return err
 	}
 
-	// Set up currency collation indexing
+	// Repositories are unique per owner, replacing the index making them unique on the whole server
 	index := mgo.Index{
-		Key:        []string{"uri"},
+		Key:        []string{"uri", "owner"},
 		Unique:     true,
-		DropDups:   true,
 		Background: true,
-		Sparse:     true,
+	}
+
+	indexes, _ := session.DB(db.DatabaseName).C(db.RepoColl).Indexes()
+	for _, existing := range indexes {
+		if existing.Name != "uri_1" {
+			continue
+		}
+		if err := session.DB(db.DatabaseName).C(db.RepoColl).DropIndexName(existing.Name); err != nil {
+			util.TypeLogger.Fatal("%s: Failed to drop \"uri\" index on collection %s: %s", packageName, db.RepoColl, err.Error())
+			return err
+		}
 	}
 
 	// Ensure collation follows the index
@@ -53,6 +65,40 @@ func (db *MongoDB) Init() error {
 		return err
 	}
 
+	// User names and token hashes are unique
+	for coll, key := range map[string]string{db.UserColl: "name", db.TokenColl: "hash"} {
+		if len(coll) == 0 {
+			continue
+		}
+
+		util.TypeLogger.Info("%s: Creating collection %s Ensure \"%s\"", packageName, coll, key)
+		err = session.DB(db.DatabaseName).C(coll).EnsureIndex(mgo.Index{Key: []string{key}, Unique: true, Background: true})
+		if err != nil {
+			util.TypeLogger.Fatal("%s: Failed to ensure \"%s\" on collection %s: %s", packageName, key, coll, err.Error())
+			return err
+		}
+	}
+
+	// Users of a provider are unique by issuer and subject
+	if len(db.UserColl) > 0 {
+		util.TypeLogger.Info("%s: Creating collection %s Ensure \"subject\"", packageName, db.UserColl)
+		err = session.DB(db.DatabaseName).C(db.UserColl).EnsureIndex(mgo.Index{Key: []string{"issuer", "subject"}, Unique: true, Sparse: true, Background: true})
+		if err != nil {
+			util.TypeLogger.Fatal("%s: Failed to ensure \"subject\" on collection %s: %s", packageName, db.UserColl, err.Error())
+			return err
+		}
+	}
+
+	// Audit entries are read newest first, by time
+	if len(db.AuditColl) > 0 {
+		util.TypeLogger.Info("%s: Creating collection %s Ensure \"time\"", packageName, db.AuditColl)
+		err = session.DB(db.DatabaseName).C(db.AuditColl).EnsureIndex(mgo.Index{Key: []string{"-time"}, Background: true})
+		if err != nil {
+			util.TypeLogger.Fatal("%s: Failed to ensure \"time\" on collection %s: %s", packageName, db.AuditColl, err.Error())
+			return err
+		}
+	}
+
 	// Postpone closing connection until we return
 	defer session.Close()
 
@@ -61,7 +107,7 @@ func (db *MongoDB) Init() error {
 	return nil
 }
 
-//Add adds rm to db if it is not already in it.
+//Add adds rm to db if its owner did not already add it.
 func (db *MongoDB) Add(rm *RepoModel) error {
 	util.TypeLogger.Debug("%s: Call for add", packageName)
 	defer util.TypeLogger.Debug("%s: Ended Call for add", packageName)
@@ -76,7 +122,7 @@ func (db *MongoDB) Add(rm *RepoModel) error {
 		return errors.New("URI is empty")
 	}
 
-	exstRepo, err := db.findRepoByURI(rm.URI)
+	exstRepo, err := db.findRepo(bson.M{"uri": rm.URI, "owner": rm.Owner})
 
 	if err != nil {
 		return err
@@ -93,11 +139,20 @@ func (db *MongoDB) Add(rm *RepoModel) error {
 	return session.DB(db.DatabaseName).C(db.RepoColl).Insert(rm)
 }
 
-// findRepoByURI takes the repo with field uri as given uri.
+// FindUnownedRepoByURI takes the repo with field uri as given uri added before there were users.
+// It returns empty repo if it is not in db.
+func (db *MongoDB) FindUnownedRepoByURI(uri string) (repo RepoModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindUnownedRepoByURI", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindUnownedRepoByURI", packageName)
+
+	return db.findRepo(bson.M{"uri": uri, "owner": bson.M{"$exists": false}})
+}
+
+// findRepo takes the repo matching query.
 // It returns empty repo if it is not in db.
-func (db *MongoDB) findRepoByURI(uri string) (repo RepoModel, err error) {
-	util.TypeLogger.Debug("%s: Call for findRepoByURI", packageName)
-	defer util.TypeLogger.Debug("%s: Ended Call for findRepoByURI", packageName)
+func (db *MongoDB) findRepo(query bson.M) (repo RepoModel, err error) {
+	util.TypeLogger.Debug("%s: Call for findRepo", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for findRepo", packageName)
 
 	session, err := mgo.Dial(db.DatabaseURL)
 	if err != nil {
@@ -106,7 +161,7 @@ func (db *MongoDB) findRepoByURI(uri string) (repo RepoModel, err error) {
 	defer session.Close()
 
 	// Find any match in the database
-	err = session.DB(db.DatabaseName).C(db.RepoColl).Find(bson.M{"uri": uri}).One(&repo)
+	err = session.DB(db.DatabaseName).C(db.RepoColl).Find(query).One(&repo)
 
 	// Return empty repo and error if error is not trivial
 	if err != nil && err.Error() != "not found" {
@@ -217,3 +272,291 @@ func (db *MongoDB) Update(rm *RepoModel) error {
 
 	return nil
 }
+
+// UpdateConfig updates the api configuration of the repo model matching rm.
+func (db *MongoDB) UpdateConfig(rm *RepoModel) error {
+	util.TypeLogger.Debug("%s: Call for UpdateConfig", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for UpdateConfig", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	// Files of a checkpoint may have been parsed with another configuration
+	if rm.Config == nil {
+		return session.DB(db.DatabaseName).C(db.RepoColl).UpdateId(rm.ID, bson.M{"$unset": bson.M{"config": "", "checkpoint": ""}})
+	}
+
+	return session.DB(db.DatabaseName).C(db.RepoColl).UpdateId(rm.ID, bson.M{"$set": bson.M{"config": rm.Config}, "$unset": bson.M{"checkpoint": ""}})
+}
+
+// UpdateCheckpoint updates the parse checkpoint of the repo model matching rm, removing it if it is nil.
+func (db *MongoDB) UpdateCheckpoint(rm *RepoModel) error {
+	util.TypeLogger.Debug("%s: Call for UpdateCheckpoint", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for UpdateCheckpoint", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	if rm.Checkpoint == nil {
+		return session.DB(db.DatabaseName).C(db.RepoColl).UpdateId(rm.ID, bson.M{"$unset": bson.M{"checkpoint": ""}})
+	}
+
+	return session.DB(db.DatabaseName).C(db.RepoColl).UpdateId(rm.ID, bson.M{"$set": bson.M{"checkpoint": rm.Checkpoint}})
+}
+
+// FindAllURIForUser finds and returns the repos userID owns or is a member of.
+func (db *MongoDB) FindAllURIForUser(userID bson.ObjectId) (repos []bson.M, err error) {
+	util.TypeLogger.Debug("%s: Call for FindAllURIForUser", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindAllURIForUser", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"$or": []bson.M{{"owner": userID}, {"members.user_id": userID}}}},
+		{"$group": bson.M{"_id": "$_id", "uri": bson.M{"$first": "$uri"}}},
+	}
+
+	if err = session.DB(db.DatabaseName).C(db.RepoColl).Pipe(pipeline).All(&repos); err != nil && err.Error() != "not found" {
+		return []bson.M{}, err
+	}
+
+	return repos, nil
+}
+
+// UpdateMembers updates the owner and members of the repo model matching rm.
+func (db *MongoDB) UpdateMembers(rm *RepoModel) error {
+	util.TypeLogger.Debug("%s: Call for UpdateMembers", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for UpdateMembers", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	return session.DB(db.DatabaseName).C(db.RepoColl).UpdateId(rm.ID, bson.M{"$set": bson.M{"owner": rm.Owner, "members": rm.Members}})
+}
+
+// Remove deletes the repo model matching rm.
+func (db *MongoDB) Remove(rm *RepoModel) error {
+	util.TypeLogger.Debug("%s: Call for Remove", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for Remove", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	return session.DB(db.DatabaseName).C(db.RepoColl).RemoveId(rm.ID)
+}
+
+// AddUser adds user to db, failing with ErrUserExists if the name is taken.
+func (db *MongoDB) AddUser(user *UserModel) error {
+	util.TypeLogger.Debug("%s: Call for AddUser", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for AddUser", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	user.ID = bson.NewObjectId()
+
+	err = session.DB(db.DatabaseName).C(db.UserColl).Insert(user)
+	if mgo.IsDup(err) {
+		return ErrUserExists
+	}
+
+	return err
+}
+
+// FindUserBySubject takes the user logging in as subject at the provider issuer.
+// It returns empty user if it is not in db.
+func (db *MongoDB) FindUserBySubject(issuer string, subject string) (user UserModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindUserBySubject", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindUserBySubject", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	if err = session.DB(db.DatabaseName).C(db.UserColl).Find(bson.M{"issuer": issuer, "subject": subject}).One(&user); err != nil && err != mgo.ErrNotFound {
+		return UserModel{}, err
+	}
+
+	return user, nil
+}
+
+// UpdateUserEmail updates the email of the user model matching user.
+func (db *MongoDB) UpdateUserEmail(user *UserModel) error {
+	util.TypeLogger.Debug("%s: Call for UpdateUserEmail", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for UpdateUserEmail", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	return session.DB(db.DatabaseName).C(db.UserColl).UpdateId(user.ID, bson.M{"$set": bson.M{"email": user.Email}})
+}
+
+// FindUserByName takes the user with field name as given name.
+// It returns empty user if it is not in db.
+func (db *MongoDB) FindUserByName(name string) (user UserModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindUserByName", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindUserByName", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	if err = session.DB(db.DatabaseName).C(db.UserColl).Find(bson.M{"name": name}).One(&user); err != nil && err != mgo.ErrNotFound {
+		return UserModel{}, err
+	}
+
+	return user, nil
+}
+
+// FindUserByID takes the user with field id as given id.
+// It returns empty user if it is not in db.
+func (db *MongoDB) FindUserByID(id bson.ObjectId) (user UserModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindUserByID", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindUserByID", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	if err = session.DB(db.DatabaseName).C(db.UserColl).FindId(id).One(&user); err != nil && err != mgo.ErrNotFound {
+		return UserModel{}, err
+	}
+
+	return user, nil
+}
+
+// AddToken adds token to db.
+func (db *MongoDB) AddToken(token *AccessTokenModel) error {
+	util.TypeLogger.Debug("%s: Call for AddToken", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for AddToken", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	token.ID = bson.NewObjectId()
+
+	return session.DB(db.DatabaseName).C(db.TokenColl).Insert(token)
+}
+
+// FindTokenByHash takes the token with field hash as given hash.
+// It returns empty token if it is not in db.
+func (db *MongoDB) FindTokenByHash(hash string) (token AccessTokenModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindTokenByHash", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindTokenByHash", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	if err = session.DB(db.DatabaseName).C(db.TokenColl).Find(bson.M{"hash": hash}).One(&token); err != nil && err != mgo.ErrNotFound {
+		return AccessTokenModel{}, err
+	}
+
+	return token, nil
+}
+
+// FindTokensByUser finds the tokens of kind belonging to userID.
+func (db *MongoDB) FindTokensByUser(userID bson.ObjectId, kind string) (tokens []AccessTokenModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindTokensByUser", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindTokensByUser", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	tokens = []AccessTokenModel{}
+	if err = session.DB(db.DatabaseName).C(db.TokenColl).Find(bson.M{"user_id": userID, "kind": kind}).Sort("created").All(&tokens); err != nil {
+		return []AccessTokenModel{}, err
+	}
+
+	return tokens, nil
+}
+
+// DeleteToken deletes the token with id belonging to userID, failing with ErrTokenNotFound if there is none.
+func (db *MongoDB) DeleteToken(id bson.ObjectId, userID bson.ObjectId) error {
+	util.TypeLogger.Debug("%s: Call for DeleteToken", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for DeleteToken", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	err = session.DB(db.DatabaseName).C(db.TokenColl).Remove(bson.M{"_id": id, "user_id": userID})
+	if err == mgo.ErrNotFound {
+		return ErrTokenNotFound
+	}
+
+	return err
+}
+
+// AddAudit adds entry to the audit log.
+func (db *MongoDB) AddAudit(entry *AuditModel) error {
+	util.TypeLogger.Debug("%s: Call for AddAudit", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for AddAudit", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	entry.ID = bson.NewObjectId()
+
+	return session.DB(db.DatabaseName).C(db.AuditColl).Insert(entry)
+}
+
+// FindAudit finds the newest entries of the audit log matching filter, at most limit of them.
+func (db *MongoDB) FindAudit(filter bson.M, limit int) (entries []AuditModel, err error) {
+	util.TypeLogger.Debug("%s: Call for FindAudit", packageName)
+	defer util.TypeLogger.Debug("%s: Ended Call for FindAudit", packageName)
+
+	session, err := mgo.Dial(db.DatabaseURL)
+	if err != nil {
+		util.TypeLogger.Fatal("%s: Failed to connect to database", packageName)
+	}
+	defer session.Close()
+
+	entries = []AuditModel{}
+	if err = session.DB(db.DatabaseName).C(db.AuditColl).Find(filter).Sort("-time").Limit(limit).All(&entries); err != nil {
+		return []AuditModel{}, err
+	}
+
+	return entries, nil
+}
@@ -102,7 +102,7 @@ func TestMongoDB_Add(t *testing.T) {
 	}
 }
 
-func TestMongoDB_findRepoByURI(t *testing.T) {
+func TestMongoDB_FindUnownedRepoByURI(t *testing.T) {
 	db := setupDB(t)
 	defer db.DropDB()
 
@@ -155,13 +155,13 @@ func TestMongoDB_findRepoByURI(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			db.Add(&tt.addRepo)
-			gotRepo, err := db.findRepoByURI(tt.args.uri)
+			gotRepo, err := db.FindUnownedRepoByURI(tt.args.uri)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("MongoDB.findRepoByURI() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("MongoDB.FindUnownedRepoByURI() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if gotRepo.ID != tt.wantRepo.ID && gotRepo.URI != tt.wantRepo.URI {
-				t.Errorf("MongoDB.findRepoByURI() = %v, want %v", gotRepo, tt.wantRepo)
+				t.Errorf("MongoDB.FindUnownedRepoByURI() = %v, want %v", gotRepo, tt.wantRepo)
 			}
 		})
 	}
@@ -2,5 +2,17 @@ package model
 
 // ProjectModel represent the codebase in a repository
 type ProjectModel struct {
-	Files []FileModel `json:"files"`
+	SchemaVersion int         `json:"schema_version" bson:"schema_version" schema:"required"` // Version of the schema the files were stored with
+	Files         []FileModel `json:"files"`
+}
+
+// FindFile returns the parsed file with the given name.
+func (project ProjectModel) FindFile(fileName string) (FileModel, bool) {
+	for _, file := range project.Files {
+		if file.FileName == fileName {
+			return file, true
+		}
+	}
+
+	return FileModel{}, false
 }
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/yaml.v2"
+)
+
+// RepoConfigFile is the name of the optional analysis configuration in the root of a repository.
+const RepoConfigFile = ".codevis.yml"
+
+// Languages the java parser supports as target.
+const (
+	LanguageCpp  = "cpp"
+	LanguageJava = "java"
+)
+
+// RepoConfig represents the per repository analysis configuration.
+// It is read from RepoConfigFile and may be overridden through the api.
+type RepoConfig struct {
+	Languages    map[string]string `json:"languages,omitempty" yaml:"languages"`         // File extension to parser target, e.g. ".h": "cpp"
+	Include      []string          `json:"include,omitempty" yaml:"include"`             // Globs a file must match to be collected
+	Exclude      []string          `json:"exclude,omitempty" yaml:"exclude"`             // Globs for files and directories never collected
+	IncludeRoots []string          `json:"include_roots,omitempty" yaml:"include_roots"` // Directories searched when resolving C++ includes
+	EntryPoints  []string          `json:"entry_points,omitempty" yaml:"entry_points"`   // Functions and classes the code is used from
+	Thresholds   MetricThresholds  `json:"thresholds" yaml:"thresholds"`                 // Limits used by analysis rules
+	Rules        map[string]string `json:"rules,omitempty" yaml:"rules"`                 // Rule id to severity, or SeverityOff to disable the rule
+}
+
+// MetricThresholds holds the limits analysis rules compare metrics against. Zero means default.
+type MetricThresholds struct {
+	FunctionLines  int `json:"function_lines,omitempty" yaml:"function_lines"`
+	Parameters     int `json:"parameters,omitempty" yaml:"parameters"`
+	PublicMembers  int `json:"public_members,omitempty" yaml:"public_members"`
+	NamespaceDepth int `json:"namespace_depth,omitempty" yaml:"namespace_depth"`
+}
+
+// defaultRepoConfig is used for anything not set by the repository or the api.
+var defaultRepoConfig = RepoConfig{
+	Languages: map[string]string{
+		".cpp":  LanguageCpp,
+		".hpp":  LanguageCpp,
+		".java": LanguageJava,
+	},
+	EntryPoints: []string{"main"},
+	Rules: map[string]string{
+		RuleFunctionLines:  SeverityWarning,
+		RuleParameters:     SeverityWarning,
+		RulePublicMembers:  SeverityInfo,
+		RuleNamespaceDepth: SeverityWarning,
+	},
+	Thresholds: MetricThresholds{
+		FunctionLines:  60,
+		Parameters:     5,
+		PublicMembers:  20,
+		NamespaceDepth: 4,
+	},
+}
+
+// LoadRepoConfig reads RepoConfigFile from root.
+// A missing file gives an empty configuration.
+func LoadRepoConfig(root string) (config RepoConfig, err error) {
+	util.TypeLogger.Debug("%s: Call to LoadRepoConfig", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to LoadRepoConfig", packageName)
+
+	content, err := ioutil.ReadFile(filepath.Join(root, RepoConfigFile))
+	if os.IsNotExist(err) {
+		return RepoConfig{}, nil
+	}
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to read %s: %s", packageName, RepoConfigFile, err.Error())
+		return RepoConfig{}, err
+	}
+
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		util.TypeLogger.Error("%s: Failed to decode %s: %s", packageName, RepoConfigFile, err.Error())
+		return RepoConfig{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		util.TypeLogger.Error("%s: Invalid %s: %s", packageName, RepoConfigFile, err.Error())
+		return RepoConfig{}, err
+	}
+
+	return config, nil
+}
+
+// Validate checks that the configuration only refers to supported languages and sane values.
+func (config RepoConfig) Validate() error {
+	for extension, language := range config.Languages {
+		if !strings.HasPrefix(extension, ".") {
+			return errors.New("Language extension must start with '.': " + extension)
+		}
+		if language != LanguageCpp && language != LanguageJava {
+			return errors.New("Unsupported language: " + language)
+		}
+	}
+
+	for _, root := range config.IncludeRoots {
+		if filepath.IsAbs(root) || strings.HasPrefix(filepath.Clean(root), "..") {
+			return errors.New("Include root must be relative to the repository: " + root)
+		}
+	}
+
+	thresholds := config.Thresholds
+	if thresholds.FunctionLines < 0 || thresholds.Parameters < 0 || thresholds.PublicMembers < 0 || thresholds.NamespaceDepth < 0 {
+		return errors.New("Thresholds can not be negative")
+	}
+
+	for id, severity := range config.Rules {
+		if _, ok := findRule(id); !ok {
+			return errors.New("Unknown rule: " + id)
+		}
+		if _, ok := severityRanks[severity]; !ok && severity != SeverityOff {
+			return errors.New("Unknown severity: " + severity)
+		}
+	}
+
+	return nil
+}
+
+// Merge returns config with every field set in override replacing its counterpart.
+// Languages are merged per extension and rules per id.
+func (config RepoConfig) Merge(override RepoConfig) RepoConfig {
+	merged := config
+
+	if len(override.Languages) > 0 {
+		merged.Languages = make(map[string]string)
+		for extension, language := range config.Languages {
+			merged.Languages[extension] = language
+		}
+		for extension, language := range override.Languages {
+			merged.Languages[extension] = language
+		}
+	}
+	if len(override.Rules) > 0 {
+		merged.Rules = make(map[string]string)
+		for id, severity := range config.Rules {
+			merged.Rules[id] = severity
+		}
+		for id, severity := range override.Rules {
+			merged.Rules[id] = severity
+		}
+	}
+	if len(override.Include) > 0 {
+		merged.Include = override.Include
+	}
+	if len(override.Exclude) > 0 {
+		merged.Exclude = override.Exclude
+	}
+	if len(override.IncludeRoots) > 0 {
+		merged.IncludeRoots = override.IncludeRoots
+	}
+	if len(override.EntryPoints) > 0 {
+		merged.EntryPoints = override.EntryPoints
+	}
+	if override.Thresholds.FunctionLines > 0 {
+		merged.Thresholds.FunctionLines = override.Thresholds.FunctionLines
+	}
+	if override.Thresholds.Parameters > 0 {
+		merged.Thresholds.Parameters = override.Thresholds.Parameters
+	}
+	if override.Thresholds.PublicMembers > 0 {
+		merged.Thresholds.PublicMembers = override.Thresholds.PublicMembers
+	}
+	if override.Thresholds.NamespaceDepth > 0 {
+		merged.Thresholds.NamespaceDepth = override.Thresholds.NamespaceDepth
+	}
+
+	return merged
+}
+
+// WithDefaults fills anything not configured with the server defaults.
+func (config RepoConfig) WithDefaults() RepoConfig {
+	return defaultRepoConfig.Merge(config)
+}
+
+// LanguageOf returns the parser target for filename and whether it is supported.
+func (config RepoConfig) LanguageOf(filename string) (language string, ok bool) {
+	language, ok = config.Languages[filepath.Ext(filename)]
+	return language, ok
+}
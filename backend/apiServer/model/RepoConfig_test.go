@@ -0,0 +1,159 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadRepoConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    RepoConfig
+		wantErr bool
+	}{
+		{
+			name:    "Valid_config",
+			content: "languages:\n  .h: cpp\nexclude:\n  - third_party\ninclude_roots:\n  - include\nthresholds:\n  parameters: 3\n",
+			want: RepoConfig{
+				Languages:    map[string]string{".h": "cpp"},
+				Exclude:      []string{"third_party"},
+				IncludeRoots: []string{"include"},
+				Thresholds:   MetricThresholds{Parameters: 3},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid_unknown_field",
+			content: "exclud:\n  - build\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid_language",
+			content: "languages:\n  .py: python\n",
+			wantErr: true,
+		},
+		{
+			name:    "Valid_rule_severity",
+			content: "rules:\n  parameters: error\n  function-lines: off\n",
+			want: RepoConfig{
+				Rules: map[string]string{RuleParameters: SeverityError, RuleFunctionLines: SeverityOff},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid_rule",
+			content: "rules:\n  line-length: error\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid_severity",
+			content: "rules:\n  parameters: fatal\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid_include_root_outside_repository",
+			content: "include_roots:\n  - ../other\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := setupWalkerRepo(t, map[string]string{RepoConfigFile: tt.content})
+			defer os.RemoveAll(root)
+
+			got, err := LoadRepoConfig(root)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadRepoConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadRepoConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoConfig_WithDefaults(t *testing.T) {
+	fileConfig := RepoConfig{
+		Languages:   map[string]string{".h": LanguageCpp},
+		EntryPoints: []string{"App::run"},
+		Thresholds:  MetricThresholds{FunctionLines: 100},
+	}
+	apiConfig := RepoConfig{
+		Languages:  map[string]string{".cpp": LanguageJava},
+		Thresholds: MetricThresholds{Parameters: 2},
+	}
+
+	config := fileConfig.Merge(apiConfig).WithDefaults()
+
+	wantLanguages := map[string]string{".h": LanguageCpp, ".cpp": LanguageJava, ".hpp": LanguageCpp, ".java": LanguageJava}
+	if !reflect.DeepEqual(config.Languages, wantLanguages) {
+		t.Errorf("Languages = %v, want %v", config.Languages, wantLanguages)
+	}
+
+	wantThresholds := MetricThresholds{FunctionLines: 100, Parameters: 2, PublicMembers: 20, NamespaceDepth: 4}
+	if config.Thresholds != wantThresholds {
+		t.Errorf("Thresholds = %+v, want %+v", config.Thresholds, wantThresholds)
+	}
+
+	if !reflect.DeepEqual(config.EntryPoints, []string{"App::run"}) {
+		t.Errorf("EntryPoints = %v, want [App::run]", config.EntryPoints)
+	}
+
+	if language, ok := config.LanguageOf("src/main.rs"); ok {
+		t.Errorf("LanguageOf() = %v, want unsupported", language)
+	}
+}
+
+func Test_resolveIncludes(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{
+		"src/main.cpp":        "#include \"util.hpp\"\n#include <lib/api.hpp>\n#include <vector>\n",
+		"src/util.hpp":        "",
+		"include/lib/api.hpp": "",
+	})
+	defer os.RemoveAll(root)
+
+	mainFile := filepath.Join(root, "src", "main.cpp")
+	files := map[string]bool{
+		mainFile:                               true,
+		filepath.Join(root, "src", "util.hpp"): true,
+		filepath.Join(root, "include", "lib", "api.hpp"): true,
+	}
+
+	includes, err := scanIncludes(mainFile)
+	if err != nil {
+		t.Fatalf("scanIncludes() error = %v", err)
+	}
+	if !reflect.DeepEqual(includes, []string{"util.hpp", "lib/api.hpp", "vector"}) {
+		t.Errorf("scanIncludes() = %v", includes)
+	}
+
+	got := resolveIncludes(root, mainFile, includes, []string{"include"}, files)
+	want := []string{filepath.Join(root, "src", "util.hpp"), filepath.Join(root, "include", "lib", "api.hpp")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveIncludes() = %v, want %v", got, want)
+	}
+}
+
+func TestFindIncludeCycles(t *testing.T) {
+	project := ProjectModel{Files: []FileModel{
+		{FileName: "repo/main.cpp", IncludedFiles: []string{"repo/world.hpp"}},
+		{FileName: "repo/world.hpp", IncludedFiles: []string{"repo/entity.hpp"}},
+		{FileName: "repo/entity.hpp", IncludedFiles: []string{"repo/system.hpp", "repo/world.hpp"}},
+		{FileName: "repo/system.hpp", IncludedFiles: []string{"repo/world.hpp"}},
+		{FileName: "repo/self.hpp", IncludedFiles: []string{"repo/self.hpp"}},
+		{FileName: "repo/util.hpp"},
+	}}
+
+	want := []IncludeCycleModel{
+		{Files: []string{"repo/entity.hpp", "repo/world.hpp"}},
+		{Files: []string{"repo/self.hpp"}},
+	}
+
+	if got := FindIncludeCycles(project); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindIncludeCycles() = %v, want %v", got, want)
+	}
+}
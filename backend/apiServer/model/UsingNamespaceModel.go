@@ -2,6 +2,6 @@ package model
 
 // UsingNamespaceModel represents the use of namespace.
 type UsingNamespaceModel struct {
-	Name   string `json:"name"`
-	LineNr int    `json:"lineNr"`
+	Name   string `json:"name" schema:"required"`
+	LineNr int    `json:"line_number" schema:"required"`
 }
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditAdd     = "add"     // A repository was added
+	AuditParse   = "parse"   // A repository was parsed the first time
+	AuditReparse = "reparse" // A repository was parsed again
+	AuditExport  = "export"  // A repository was exported
+	AuditDelete  = "delete"  // A repository was deleted
+	AuditAccess  = "access"  // A request was denied by the role of the user
+)
+
+// Outcomes of audited actions.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// Limits of audit log queries.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// ErrInvalidAuditQuery is returned for queries with unknown actions or outcomes.
+var ErrInvalidAuditQuery = errors.New("Invalid audit query")
+
+// AuditModel is an entry of the audit log.
+type AuditModel struct {
+	ID       bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	Time     time.Time     `json:"time" bson:"time"`                           // When the action ended
+	UserID   bson.ObjectId `json:"-" bson:"user_id,omitempty"`                 // Who did it
+	UserName string        `json:"user,omitempty" bson:"user,omitempty"`       // Name of the user at the time
+	ClientIP string        `json:"client_ip" bson:"client_ip"`                 // Address the request came from
+	Action   string        `json:"action" bson:"action"`                       // One of the audit actions
+	RepoID   string        `json:"repo_id,omitempty" bson:"repo_id,omitempty"` // Repository acted on
+	Outcome  string        `json:"outcome" bson:"outcome"`                     // One of the outcomes
+	Detail   string        `json:"detail,omitempty" bson:"detail,omitempty"`   // What was done or why it failed
+}
+
+// AuditQuery filters the audit log. Empty fields match everything.
+type AuditQuery struct {
+	UserName string
+	RepoID   string
+	Action   string
+	Outcome  string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// auditActions and auditOutcomes are the valid values of queries.
+var (
+	auditActions  = map[string]bool{AuditAdd: true, AuditParse: true, AuditReparse: true, AuditExport: true, AuditDelete: true, AuditAccess: true}
+	auditOutcomes = map[string]bool{OutcomeSuccess: true, OutcomeFailure: true, OutcomeDenied: true}
+)
+
+// IsAdmin tells if the id of the user is one of adminUsers, the users allowed to read the whole audit log.
+// Admins are told apart by id, as anyone may register or log in with OIDC under the name of an admin.
+func (user UserModel) IsAdmin(adminUsers []string) bool {
+	for _, id := range adminUsers {
+		if user.ID.Valid() && id == user.ID.Hex() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Record stores the entry in the audit log, setting its time.
+func (entry AuditModel) Record() error {
+	util.TypeLogger.Debug("%s: Call to Record", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to Record", packageName)
+
+	entry.Time = time.Now().UTC()
+
+	if err := DB.AddAudit(&entry); err != nil {
+		util.TypeLogger.Error("%s: Failed to record audit entry: %s", packageName, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// FindAudit returns the newest entries of the audit log matching query.
+func FindAudit(query AuditQuery) ([]AuditModel, error) {
+	util.TypeLogger.Debug("%s: Call to FindAudit", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FindAudit", packageName)
+
+	filter, err := query.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	return DB.FindAudit(filter, query.limit())
+}
+
+// filter makes the database filter of the query.
+func (query AuditQuery) filter() (bson.M, error) {
+	filter := bson.M{}
+
+	if len(query.UserName) > 0 {
+		filter["user"] = query.UserName
+	}
+	if len(query.RepoID) > 0 {
+		filter["repo_id"] = query.RepoID
+	}
+	if len(query.Action) > 0 {
+		if !auditActions[query.Action] {
+			return nil, ErrInvalidAuditQuery
+		}
+		filter["action"] = query.Action
+	}
+	if len(query.Outcome) > 0 {
+		if !auditOutcomes[query.Outcome] {
+			return nil, ErrInvalidAuditQuery
+		}
+		filter["outcome"] = query.Outcome
+	}
+
+	period := bson.M{}
+	if !query.Since.IsZero() {
+		period["$gte"] = query.Since
+	}
+	if !query.Until.IsZero() {
+		period["$lt"] = query.Until
+	}
+	if len(period) > 0 {
+		filter["time"] = period
+	}
+
+	return filter, nil
+}
+
+// limit returns how many entries to return at most.
+func (query AuditQuery) limit() int {
+	if query.Limit <= 0 {
+		return defaultAuditLimit
+	}
+	if query.Limit > maxAuditLimit {
+		return maxAuditLimit
+	}
+
+	return query.Limit
+}
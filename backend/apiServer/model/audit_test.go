@@ -0,0 +1,97 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestAuditQueryFilter(t *testing.T) {
+	since := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   AuditQuery
+		want    bson.M
+		wantErr error
+	}{
+		{
+			name:  "Everything",
+			query: AuditQuery{},
+			want:  bson.M{},
+		},
+		{
+			name:  "All filters",
+			query: AuditQuery{UserName: "bob", RepoID: "5c62d1904122c760dafe9341", Action: AuditExport, Outcome: OutcomeSuccess, Since: since, Until: until},
+			want: bson.M{
+				"user":    "bob",
+				"repo_id": "5c62d1904122c760dafe9341",
+				"action":  AuditExport,
+				"outcome": OutcomeSuccess,
+				"time":    bson.M{"$gte": since, "$lt": until},
+			},
+		},
+		{
+			name:  "Since only",
+			query: AuditQuery{Since: since},
+			want:  bson.M{"time": bson.M{"$gte": since}},
+		},
+		{
+			name:    "Unknown action",
+			query:   AuditQuery{Action: "clone"},
+			wantErr: ErrInvalidAuditQuery,
+		},
+		{
+			name:    "Unknown outcome",
+			query:   AuditQuery{Outcome: "ok"},
+			wantErr: ErrInvalidAuditQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.filter()
+			if err != tt.wantErr {
+				t.Fatalf("filter() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditQueryLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: defaultAuditLimit},
+		{limit: 10, want: 10},
+		{limit: maxAuditLimit + 1, want: maxAuditLimit},
+	}
+
+	for _, tt := range tests {
+		if got := (AuditQuery{Limit: tt.limit}).limit(); got != tt.want {
+			t.Errorf("limit() with %d = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	alice := UserModel{ID: bson.NewObjectId(), Name: "alice"}
+	adminUsers := []string{alice.ID.Hex(), "root"}
+
+	if !alice.IsAdmin(adminUsers) {
+		t.Errorf("IsAdmin() = false for alice")
+	}
+	if (UserModel{ID: bson.NewObjectId(), Name: "alice"}).IsAdmin(adminUsers) {
+		t.Errorf("IsAdmin() = true for another user named alice")
+	}
+	if (UserModel{ID: bson.NewObjectId(), Name: "root"}).IsAdmin(adminUsers) {
+		t.Errorf("IsAdmin() = true for a user named like an admin id")
+	}
+}
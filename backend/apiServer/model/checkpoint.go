@@ -0,0 +1,95 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// ErrInterrupted is sent by ParseDataFromFiles when parsing stopped for a shutdown of the server.
+var ErrInterrupted = errors.New("Parsing was interrupted")
+
+// ParseCheckpoint is the progress of an interrupted parse, picked up by the next parse of the repository.
+type ParseCheckpoint struct {
+	Time  time.Time   `bson:"time"`  // When parsing was interrupted
+	Files []FileModel `bson:"files"` // Files parsed or skipped so far, with the names they were parsed with
+}
+
+// Running parses, stopped by StopParsing.
+var (
+	parsesMutex   sync.Mutex
+	parsesStopped bool
+	stopParses    = make(chan struct{})
+	runningParses sync.WaitGroup
+)
+
+// StopParsing makes running parses stop after their current file and store a checkpoint,
+// and refuses new ones. It returns once every parse is checkpointed, or with the error of ctx.
+func StopParsing(ctx context.Context) error {
+	util.TypeLogger.Debug("%s: Call to StopParsing", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to StopParsing", packageName)
+
+	parsesMutex.Lock()
+	if !parsesStopped {
+		parsesStopped = true
+		close(stopParses)
+	}
+	parsesMutex.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		runningParses.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// beginParse registers a running parse, it returns false once parsing is stopped.
+func beginParse() bool {
+	parsesMutex.Lock()
+	defer parsesMutex.Unlock()
+
+	if parsesStopped {
+		return false
+	}
+
+	runningParses.Add(1)
+
+	return true
+}
+
+// parsedFiles returns the files of the checkpoint by name.
+func (checkpoint *ParseCheckpoint) parsedFiles() map[string]FileModel {
+	files := make(map[string]FileModel)
+	if checkpoint == nil {
+		return files
+	}
+
+	for _, file := range checkpoint.Files {
+		files[file.FileName] = file
+	}
+
+	return files
+}
+
+// UpdateCheckpoint stores the checkpoint of the repository, removing it if it is nil.
+func (repo RepoModel) UpdateCheckpoint() error {
+	util.TypeLogger.Debug("%s: Call to UpdateCheckpoint", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to UpdateCheckpoint", packageName)
+
+	if err := DB.UpdateCheckpoint(&repo); err != nil {
+		util.TypeLogger.Error("%s: Failed to update checkpoint in database: %s", packageName, err.Error())
+		return err
+	}
+
+	return nil
+}
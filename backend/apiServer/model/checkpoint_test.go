@@ -0,0 +1,80 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_parseFiles(t *testing.T) {
+	files := []string{"/repo/a.cpp", "/repo/b.txt", "/repo/c.txt"}
+	checkpoint := &ParseCheckpoint{Files: []FileModel{{FileName: "/repo/a.cpp", Parsed: true}}}
+
+	t.Run("Resume from checkpoint", func(t *testing.T) {
+		projectModel, response, interrupted := parseFiles("", files, RepoConfig{}, checkpoint.parsedFiles(), nil, func(ParseResponse) {})
+		if interrupted {
+			t.Fatal("parseFiles() interrupted without stop")
+		}
+		if len(projectModel.Files) != 3 || !projectModel.Files[0].Parsed {
+			t.Errorf("parseFiles() files = %+v, want checkpointed a.cpp and 2 skipped files", projectModel.Files)
+		}
+		if response.ParsedFileCount != 1 || response.SkippedFileCount != 2 || response.FileCount != 3 {
+			t.Errorf("parseFiles() counts = %d parsed, %d skipped of %d, want 1, 2 of 3",
+				response.ParsedFileCount, response.SkippedFileCount, response.FileCount)
+		}
+	})
+
+	t.Run("Resume with failed file", func(t *testing.T) {
+		config := RepoConfig{Languages: map[string]string{".cpp": LanguageCpp}}
+		failed := &ParseCheckpoint{Files: []FileModel{
+			{FileName: "/repo/a.cpp", Parsed: true},
+			{FileName: "/repo/b.cpp", Parsed: false},
+		}}
+		_, response, _ := parseFiles("", []string{"/repo/a.cpp", "/repo/b.cpp", "/repo/c.txt"}, config, failed.parsedFiles(), nil, func(ParseResponse) {})
+
+		// A fresh parse counts the failed b.cpp as parsed, like every file in a mapped language
+		if response.ParsedFileCount != 2 || response.SkippedFileCount != 1 {
+			t.Errorf("parseFiles() counts = %d parsed, %d skipped, want 2, 1 like a fresh parse",
+				response.ParsedFileCount, response.SkippedFileCount)
+		}
+	})
+
+	t.Run("Stop after current file", func(t *testing.T) {
+		stop := make(chan struct{})
+		projectModel, _, interrupted := parseFiles("", files, RepoConfig{}, nil, stop, func(ParseResponse) {
+			close(stop)
+		})
+		if !interrupted {
+			t.Fatal("parseFiles() not interrupted")
+		}
+		if len(projectModel.Files) != 1 || projectModel.Files[0].FileName != "/repo/a.cpp" {
+			t.Errorf("parseFiles() files = %+v, want only a.cpp", projectModel.Files)
+		}
+	})
+}
+
+func TestStopParsing(t *testing.T) {
+	defer func() {
+		parsesStopped = false
+		stopParses = make(chan struct{})
+	}()
+
+	if !beginParse() {
+		t.Fatal("beginParse() = false before stopping")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := StopParsing(ctx); err != context.DeadlineExceeded {
+		t.Errorf("StopParsing() with running parse = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	runningParses.Done()
+	if err := StopParsing(context.Background()); err != nil {
+		t.Errorf("StopParsing() = %v, want nil", err)
+	}
+
+	if beginParse() {
+		t.Error("beginParse() = true after stopping")
+	}
+}
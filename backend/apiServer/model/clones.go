@@ -0,0 +1,409 @@
+package model
+
+import (
+	"errors"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Kinds of clone groups, from most to least alike.
+const (
+	CloneExact   = "exact"   // Function bodies with the same tokens
+	CloneRenamed = "renamed" // Function bodies with the same tokens after renaming identifiers and literals
+	CloneNear    = "near"    // Function bodies sharing most of their normalized token sequences
+)
+
+// Defaults and bounds of clone detection.
+const (
+	DefaultCloneMinTokens  = 50
+	DefaultCloneSimilarity = 0.8
+	cloneGramSize          = 5  // Tokens per hashed k-gram
+	cloneWindowSize        = 4  // K-grams per winnowing window
+	maxClonePostings       = 64 // Fingerprints shared by more functions are too common to find candidates with
+)
+
+// ErrInvalidCloneOptions is returned for clone options out of range.
+var ErrInvalidCloneOptions = errors.New("Invalid clone detection options")
+
+// CloneOptions tunes clone detection.
+type CloneOptions struct {
+	MinTokens  int     // Functions with fewer normalized tokens are ignored, DefaultCloneMinTokens if zero
+	Similarity float64 // Minimum similarity between 0 and 1 of near clones, DefaultCloneSimilarity if zero
+}
+
+// CloneMemberModel represents a function in a clone group.
+type CloneMemberModel struct {
+	Name          string `json:"name"`           // Identifier of the function
+	QualifiedName string `json:"qualified_name"` // Name prefixed by enclosing namespaces and classes
+	FileName      string `json:"file_name"`      // File the function is found in
+	StartLine     int    `json:"start_line"`     // First line of the function
+	EndLine       int    `json:"end_line"`       // Last line of the function
+	Tokens        int    `json:"tokens"`         // Number of tokens without whitespace and comments
+}
+
+// CloneGroupModel represents functions duplicating each other.
+type CloneGroupModel struct {
+	Kind       string             `json:"kind"`       // One of the Clone kinds
+	Similarity float64            `json:"similarity"` // Lowest similarity between 0 and 1 linking the members
+	Members    []CloneMemberModel `json:"members"`    // Functions in the group ordered by file and line
+}
+
+// CloneReport lists the clone groups of a project.
+type CloneReport struct {
+	Functions int               `json:"functions"` // Number of functions compared
+	Groups    []CloneGroupModel `json:"groups"`    // Groups ordered by size of the functions, largest first
+}
+
+// cloneCandidate is a function prepared for comparison.
+type cloneCandidate struct {
+	member       CloneMemberModel
+	exact        uint64          // Hash of the token texts
+	normalized   uint64          // Hash of the normalized tokens
+	fingerprints map[uint64]bool // Winnowed hashes of normalized k-grams
+}
+
+// Validate checks that the options are in range.
+func (options CloneOptions) Validate() error {
+	if options.MinTokens < 0 || options.Similarity < 0 || options.Similarity > 1 {
+		return ErrInvalidCloneOptions
+	}
+
+	return nil
+}
+
+// FindClones finds groups of duplicated functions in project, within and across files.
+// Function bodies are read below root from StartLine to EndLine and compared by their tokens from the first brace,
+// with identifiers and literals normalized so renamed copies are found as well.
+func FindClones(project ProjectModel, config RepoConfig, root string, options CloneOptions) (CloneReport, error) {
+	util.TypeLogger.Debug("%s: Call to FindClones", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FindClones", packageName)
+
+	if err := options.Validate(); err != nil {
+		return CloneReport{}, err
+	}
+	if options.MinTokens == 0 {
+		options.MinTokens = DefaultCloneMinTokens
+	}
+	if options.Similarity == 0 {
+		options.Similarity = DefaultCloneSimilarity
+	}
+
+	candidates := collectCloneCandidates(project, config, root, options.MinTokens)
+	report := CloneReport{Functions: len(candidates), Groups: []CloneGroupModel{}}
+
+	groups := newUnionFind(len(candidates))
+	links := make(map[int]float64) // Lowest similarity linking a group, by root
+
+	link := func(a int, b int, similarity float64) {
+		rootA, rootB := groups.find(a), groups.find(b)
+		if rootA == rootB {
+			return
+		}
+
+		lowest := similarity
+		for _, root := range []int{rootA, rootB} {
+			if existing, ok := links[root]; ok && existing < lowest {
+				lowest = existing
+			}
+		}
+		delete(links, rootA)
+		delete(links, rootB)
+		links[groups.union(rootA, rootB)] = lowest
+	}
+
+	// Bodies equal after normalizing are linked directly.
+	byNormalized := make(map[uint64]int)
+	for index, candidate := range candidates {
+		if first, ok := byNormalized[candidate.normalized]; ok {
+			link(first, index, 1)
+			continue
+		}
+		byNormalized[candidate.normalized] = index
+	}
+
+	// Near clones are searched for between functions sharing uncommon fingerprints.
+	postings := make(map[uint64][]int)
+	for index, candidate := range candidates {
+		for fingerprint := range candidate.fingerprints {
+			postings[fingerprint] = append(postings[fingerprint], index)
+		}
+	}
+
+	compared := make(map[[2]int]bool)
+	for _, functions := range postings {
+		if len(functions) > maxClonePostings {
+			continue
+		}
+		for i := 0; i < len(functions); i++ {
+			for j := i + 1; j < len(functions); j++ {
+				pair := [2]int{functions[i], functions[j]}
+				if compared[pair] || groups.find(pair[0]) == groups.find(pair[1]) {
+					continue
+				}
+				compared[pair] = true
+
+				if similarity := jaccard(candidates[pair[0]].fingerprints, candidates[pair[1]].fingerprints); similarity >= options.Similarity {
+					link(pair[0], pair[1], similarity)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for index := range candidates {
+		root := groups.find(index)
+		members[root] = append(members[root], index)
+	}
+
+	for root, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		group := CloneGroupModel{Kind: CloneExact, Similarity: links[root]}
+		for _, index := range indexes {
+			candidate := candidates[index]
+			first := candidates[indexes[0]]
+
+			if candidate.normalized != first.normalized {
+				group.Kind = CloneNear
+			} else if candidate.exact != first.exact && group.Kind == CloneExact {
+				group.Kind = CloneRenamed
+			}
+
+			group.Members = append(group.Members, candidate.member)
+		}
+
+		sort.Slice(group.Members, func(i, j int) bool {
+			if group.Members[i].FileName != group.Members[j].FileName {
+				return group.Members[i].FileName < group.Members[j].FileName
+			}
+			return group.Members[i].StartLine < group.Members[j].StartLine
+		})
+
+		report.Groups = append(report.Groups, group)
+	}
+
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		first, second := report.Groups[i].Members[0], report.Groups[j].Members[0]
+		if first.Tokens != second.Tokens {
+			return first.Tokens > second.Tokens
+		}
+		if first.FileName != second.FileName {
+			return first.FileName < second.FileName
+		}
+		return first.StartLine < second.StartLine
+	})
+
+	return report, nil
+}
+
+// collectCloneCandidates tokenizes the body of every function of project with at least minTokens tokens.
+func collectCloneCandidates(project ProjectModel, config RepoConfig, root string, minTokens int) (candidates []cloneCandidate) {
+	for _, file := range project.Files {
+		var tokens []TokenModel
+		read := false
+
+		for _, symbol := range file.Symbols() {
+			if symbol.Kind != SymbolFunction || symbol.StartLine < 1 || symbol.EndLine < symbol.StartLine {
+				continue
+			}
+
+			// Files are only read when they have functions to compare.
+			if !read {
+				read = true
+				tokens = readCloneTokens(file.FileName, config, root)
+			}
+
+			// Only the body is compared, so copies with another name or signature are still exact clones.
+			var texts, normalized []string
+			inBody := false
+			for _, token := range tokens {
+				if token.Line < symbol.StartLine || token.Line > symbol.EndLine {
+					continue
+				}
+				if token.Kind == TokenWhitespace || token.Kind == TokenComment {
+					continue
+				}
+				if !inBody && token.Text != "{" {
+					continue
+				}
+				inBody = true
+
+				texts = append(texts, token.Text)
+				normalized = append(normalized, normalizeCloneToken(token))
+			}
+
+			if len(normalized) < minTokens {
+				continue
+			}
+
+			candidates = append(candidates, cloneCandidate{
+				member: CloneMemberModel{
+					Name:          symbol.Name,
+					QualifiedName: symbol.QualifiedName,
+					FileName:      symbol.FileName,
+					StartLine:     symbol.StartLine,
+					EndLine:       symbol.EndLine,
+					Tokens:        len(normalized),
+				},
+				exact:        hashTokens(texts),
+				normalized:   hashTokens(normalized),
+				fingerprints: winnow(normalized, cloneGramSize, cloneWindowSize),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// readCloneTokens tokenizes a file below root, nil if it can not be read or is too large.
+func readCloneTokens(fileName string, config RepoConfig, root string) []TokenModel {
+	filename := filepath.Join(root, filepath.FromSlash(fileName))
+	info, err := os.Stat(filename)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxIndexedFileBytes {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		util.TypeLogger.Warn("%s: Skipping file in clone detection: %s", packageName, fileName)
+		return nil
+	}
+
+	language, _ := config.LanguageOf(fileName)
+	return Tokenize(strings.Replace(string(content), "\r\n", "\n", -1), language)
+}
+
+// normalizeCloneToken replaces identifiers and literals by their kind so renamed copies compare equal.
+func normalizeCloneToken(token TokenModel) string {
+	switch token.Kind {
+	case TokenIdentifier, TokenString, TokenChar, TokenNumber:
+		return "$" + token.Kind
+	}
+
+	return token.Text
+}
+
+// hashTokens hashes a token sequence.
+func hashTokens(tokens []string) uint64 {
+	hash := fnv.New64a()
+	for _, token := range tokens {
+		hash.Write([]byte(token))
+		hash.Write([]byte{0})
+	}
+
+	return hash.Sum64()
+}
+
+// winnow selects fingerprints of tokens: k-grams are hashed with a rolling hash and the smallest hash
+// of every window of consecutive k-grams is kept. Sequences shorter than one k-gram give their full hash.
+func winnow(tokens []string, gramSize int, windowSize int) map[uint64]bool {
+	fingerprints := make(map[uint64]bool)
+	if len(tokens) < gramSize {
+		fingerprints[hashTokens(tokens)] = true
+		return fingerprints
+	}
+
+	const base = 1099511628211
+
+	tokenHashes := make([]uint64, len(tokens))
+	for index, token := range tokens {
+		tokenHashes[index] = hashTokens([]string{token})
+	}
+
+	// power is base raised to gramSize-1, used to remove the token leaving the k-gram.
+	power := uint64(1)
+	for i := 1; i < gramSize; i++ {
+		power *= base
+	}
+
+	grams := make([]uint64, 0, len(tokens)-gramSize+1)
+	var rolling uint64
+	for index, tokenHash := range tokenHashes {
+		if index >= gramSize {
+			rolling -= tokenHashes[index-gramSize] * power
+		}
+		rolling = rolling*base + tokenHash
+		if index >= gramSize-1 {
+			grams = append(grams, rolling)
+		}
+	}
+
+	if len(grams) <= windowSize {
+		windowSize = len(grams)
+	}
+
+	selected := -1
+	for start := 0; start+windowSize <= len(grams); start++ {
+		minimum := start
+		for index := start; index < start+windowSize; index++ {
+			if grams[index] <= grams[minimum] {
+				minimum = index
+			}
+		}
+		if minimum != selected {
+			selected = minimum
+			fingerprints[grams[minimum]] = true
+		}
+	}
+
+	return fingerprints
+}
+
+// jaccard returns the size of the intersection of a and b divided by the size of their union.
+func jaccard(a map[uint64]bool, b map[uint64]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for fingerprint := range a {
+		if b[fingerprint] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// unionFind keeps disjoint sets of indexes.
+type unionFind struct {
+	parents []int
+}
+
+// newUnionFind creates size sets with one index each.
+func newUnionFind(size int) *unionFind {
+	sets := &unionFind{parents: make([]int, size)}
+	for index := range sets.parents {
+		sets.parents[index] = index
+	}
+
+	return sets
+}
+
+// find returns the representative of the set containing index.
+func (sets *unionFind) find(index int) int {
+	for sets.parents[index] != index {
+		sets.parents[index] = sets.parents[sets.parents[index]]
+		index = sets.parents[index]
+	}
+
+	return index
+}
+
+// union merges the sets represented by a and b and returns the new representative.
+func (sets *unionFind) union(a int, b int) int {
+	a, b = sets.find(a), sets.find(b)
+	if a != b {
+		sets.parents[b] = a
+	}
+
+	return a
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFindClones(t *testing.T) {
+	body := "{\n  int total = 0;\n  for (int i = 0; i < count; i++) {\n    total += values[i] * 2;\n  }\n  if (total > 100) {\n    total = 100;\n  }\n  return total;\n}\n"
+	renamed := "{\n  int sum = 0;\n  for (int j = 0; j < size; j++) {\n    sum += items[j] * 3;\n  }\n  if (sum > 100) {\n    sum = 100;\n  }\n  return sum;\n}\n"
+	near := "{\n  int sum = 0;\n  for (int j = 0; j < size; j++) {\n    sum += items[j] * 3;\n  }\n  if (sum > 100) {\n    sum = 100;\n  }\n  log(sum);\n  return sum;\n}\n"
+	other := "{\n  std::cout << \"Hello\" << std::endl;\n  while (running) { poll(); }\n}\n"
+
+	root := setupWalkerRepo(t, map[string]string{
+		"repo/a.cpp": "int first(int* values, int count)\n" + body + "int second(int* values, int count)\n" + body,
+		"repo/b.cpp": "int third(int* items, int size)\n" + renamed + "int fourth(int* items, int size)\n" + near + "void fifth()\n" + other,
+	})
+	defer os.RemoveAll(root)
+
+	function := func(name string, start int, end int) FunctionModel {
+		return FunctionModel{Name: "int " + name + "()", StartLine: start, EndLine: end}
+	}
+
+	project := ProjectModel{Files: []FileModel{
+		{FileName: "repo/a.cpp", Functions: []FunctionModel{function("first", 1, 11), function("second", 12, 22)}},
+		{FileName: "repo/b.cpp", Functions: []FunctionModel{function("third", 1, 11), function("fourth", 12, 23), function("fifth", 24, 28)}},
+	}}
+
+	tests := []struct {
+		name       string
+		options    CloneOptions
+		wantGroups [][]string
+		wantKinds  []string
+		wantErr    bool
+	}{
+		{
+			name:       "Valid_exact_and_renamed_in_one_group",
+			options:    CloneOptions{MinTokens: 10, Similarity: 0.99},
+			wantGroups: [][]string{{"first", "second", "third"}},
+			wantKinds:  []string{CloneRenamed},
+		},
+		{
+			name:       "Valid_near_clone_joins_group",
+			options:    CloneOptions{MinTokens: 10, Similarity: 0.6},
+			wantGroups: [][]string{{"first", "second", "third", "fourth"}},
+			wantKinds:  []string{CloneNear},
+		},
+		{
+			name:       "Valid_functions_below_min_tokens_ignored",
+			options:    CloneOptions{MinTokens: 1000},
+			wantGroups: [][]string{},
+		},
+		{
+			name:    "Invalid_similarity",
+			options: CloneOptions{Similarity: 2},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := FindClones(project, defaultRepoConfig, root, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindClones() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(report.Groups) != len(tt.wantGroups) {
+				t.Fatalf("FindClones() groups = %+v, want %v", report.Groups, tt.wantGroups)
+			}
+
+			for index, group := range report.Groups {
+				var names []string
+				for _, member := range group.Members {
+					names = append(names, member.Name)
+				}
+				if len(names) != len(tt.wantGroups[index]) {
+					t.Errorf("FindClones() members = %v, want %v", names, tt.wantGroups[index])
+					continue
+				}
+				for position := range names {
+					if names[position] != tt.wantGroups[index][position] {
+						t.Errorf("FindClones() members = %v, want %v", names, tt.wantGroups[index])
+						break
+					}
+				}
+				if group.Kind != tt.wantKinds[index] {
+					t.Errorf("FindClones() kind = %s, want %s", group.Kind, tt.wantKinds[index])
+				}
+			}
+		})
+	}
+}
+
+func TestWinnow(t *testing.T) {
+	tokens := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	first := winnow(tokens, 3, 2)
+	second := winnow(append([]string{}, tokens...), 3, 2)
+	if jaccard(first, second) != 1 {
+		t.Errorf("winnow() is not deterministic")
+	}
+
+	if len(winnow([]string{"a"}, 3, 2)) != 1 {
+		t.Errorf("winnow() of short sequence should give one fingerprint")
+	}
+
+	changed := winnow([]string{"x", "y", "z", "w", "v", "u", "s", "r"}, 3, 2)
+	if jaccard(first, changed) != 0 {
+		t.Errorf("winnow() of different tokens share fingerprints")
+	}
+}
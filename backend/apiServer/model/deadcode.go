@@ -0,0 +1,526 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Confidence that code reported as dead is never used.
+const (
+	ConfidenceHigh   = "high"   // Nothing refers to the code
+	ConfidenceMedium = "medium" // The code may be reached through a virtual call
+	ConfidenceLow    = "low"    // The code is referred to without a call, possibly through reflection or a function pointer
+)
+
+// DeadCodeModel represents a function or class not reachable from any entry point.
+type DeadCodeModel struct {
+	Kind          string `json:"kind"`                 // SymbolFunction or SymbolClass
+	Name          string `json:"name"`                 // Identifier as written in code
+	QualifiedName string `json:"qualified_name"`       // Name prefixed by enclosing namespaces and classes
+	FileName      string `json:"file_name"`            // File the code is declared in
+	StartLine     int    `json:"start_line,omitempty"` // First line of the code when known
+	EndLine       int    `json:"end_line,omitempty"`   // Last line of the code when known
+	Confidence    string `json:"confidence"`           // One of the Confidence levels
+	Reason        string `json:"reason"`               // Why the code is believed to be dead with this confidence
+}
+
+// DeadCodeReport lists the code not reachable from the configured entry points.
+type DeadCodeReport struct {
+	EntryPoints           []string        `json:"entry_points"`                      // Entry points from the configuration
+	UnresolvedEntryPoints []string        `json:"unresolved_entry_points,omitempty"` // Entry points matching no function or class
+	ReachableFunctions    int             `json:"reachable_functions"`               // Number of functions reached from the entry points
+	Dead                  []DeadCodeModel `json:"dead"`                              // Unreachable functions and classes
+}
+
+// callNode is a function in the call graph of a project.
+type callNode struct {
+	function  *FunctionModel
+	symbol    SymbolModel
+	className string // Simple name of the class the function is a member of, empty for free functions
+	public    bool   // Declared outside of classes or in a public access specifier
+	reached   bool
+}
+
+// classNode is a class in the call graph of a project.
+type classNode struct {
+	symbol  SymbolModel
+	parents []string // Simple names of the classes inherited from
+}
+
+// callGraph resolves calls between the functions of a project.
+type callGraph struct {
+	nodes     []*callNode
+	byName    map[string][]*callNode
+	classes   []*classNode
+	byClass   map[string][]*classNode
+	children  map[string][]string // Simple class name to the simple names of classes inheriting from it
+	usedTypes map[string]bool     // Words of types used by reached functions
+}
+
+// FindDeadCode reports the functions and classes of project not reachable from the entry points of config.
+// Files are read below root to find names used without a call or in strings, lowering the confidence.
+// Functions of project are marked with the result, so the report can be shown as an overlay.
+func FindDeadCode(project ProjectModel, config RepoConfig, root string) DeadCodeReport {
+	util.TypeLogger.Debug("%s: Call to FindDeadCode", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FindDeadCode", packageName)
+
+	graph := newCallGraph(project)
+	report := DeadCodeReport{EntryPoints: config.EntryPoints, Dead: []DeadCodeModel{}}
+
+	for _, entryPoint := range config.EntryPoints {
+		roots := graph.entryPoint(entryPoint)
+		if len(roots) == 0 {
+			report.UnresolvedEntryPoints = append(report.UnresolvedEntryPoints, entryPoint)
+		}
+		graph.reach(roots)
+	}
+
+	uncalled, inStrings := scanUncalledNames(project, config, root)
+
+	for _, node := range graph.nodes {
+		node.function.Dead = !node.reached
+		node.function.DeadConfidence = ""
+
+		if node.reached {
+			report.ReachableFunctions++
+			continue
+		}
+
+		confidence, reason := ConfidenceHigh, "Not reachable from any entry point"
+		switch {
+		case inStrings[node.symbol.Name]:
+			confidence, reason = ConfidenceLow, "Name is used in a string, possibly through reflection"
+		case uncalled[node.symbol.Name]:
+			confidence, reason = ConfidenceLow, "Referred to without a call, possibly as a function pointer"
+		case graph.isVirtual(node):
+			confidence, reason = ConfidenceMedium, "May override or be overridden, and be called through a virtual call"
+		}
+
+		node.function.DeadConfidence = confidence
+		report.Dead = append(report.Dead, deadCode(node.symbol, confidence, reason))
+	}
+
+	for _, class := range graph.classes {
+		if graph.isClassUsed(class, config.EntryPoints) {
+			continue
+		}
+
+		confidence, reason := ConfidenceHigh, "No member is reachable and the type is not used by reachable code"
+		if inStrings[class.symbol.Name] {
+			confidence, reason = ConfidenceLow, "Name is used in a string, possibly through reflection"
+		}
+
+		report.Dead = append(report.Dead, deadCode(class.symbol, confidence, reason))
+	}
+
+	sort.SliceStable(report.Dead, func(i, j int) bool {
+		if report.Dead[i].FileName != report.Dead[j].FileName {
+			return report.Dead[i].FileName < report.Dead[j].FileName
+		}
+		return report.Dead[i].StartLine < report.Dead[j].StartLine
+	})
+
+	return report
+}
+
+// deadCode creates the report entry for symbol.
+func deadCode(symbol SymbolModel, confidence string, reason string) DeadCodeModel {
+	return DeadCodeModel{
+		Kind:          symbol.Kind,
+		Name:          symbol.Name,
+		QualifiedName: symbol.QualifiedName,
+		FileName:      symbol.FileName,
+		StartLine:     symbol.StartLine,
+		EndLine:       symbol.EndLine,
+		Confidence:    confidence,
+		Reason:        reason,
+	}
+}
+
+// newCallGraph collects the functions and classes of project.
+// Nodes point into project so the functions can be marked.
+func newCallGraph(project ProjectModel) *callGraph {
+	graph := &callGraph{
+		byName:    make(map[string][]*callNode),
+		byClass:   make(map[string][]*classNode),
+		children:  make(map[string][]string),
+		usedTypes: make(map[string]bool),
+	}
+
+	for index := range project.Files {
+		file := &project.Files[index]
+		graph.addFunctions(file.FileName, "", "", true, file.Functions)
+		graph.addNamespaces(file.FileName, "", file.Namespaces)
+		graph.addClasses(file.FileName, "", file.Classes)
+	}
+
+	for _, class := range graph.classes {
+		for _, parent := range class.parents {
+			graph.children[parent] = append(graph.children[parent], class.symbol.Name)
+		}
+	}
+
+	return graph
+}
+
+// addFunctions adds functions declared in scope, as members of className when not empty.
+func (graph *callGraph) addFunctions(fileName string, scope string, className string, public bool, functions []FunctionModel) {
+	for index := range functions {
+		function := &functions[index]
+		name := function.Identifier()
+		if len(name) == 0 {
+			continue
+		}
+
+		qualifiedName := qualify(scope, name)
+		memberOf := className
+		if qualifier := function.Qualifier(); len(qualifier) > 0 {
+			qualifiedName = qualify(scope, qualify(qualifier, name))
+			memberOf = lastScopeElement(qualifier)
+		}
+
+		node := &callNode{
+			function: function,
+			symbol: SymbolModel{
+				Kind:          SymbolFunction,
+				Name:          name,
+				QualifiedName: qualifiedName,
+				FileName:      fileName,
+				StartLine:     function.StartLine,
+				EndLine:       function.EndLine,
+			},
+			className: memberOf,
+			public:    public,
+		}
+
+		graph.nodes = append(graph.nodes, node)
+		graph.byName[name] = append(graph.byName[name], node)
+	}
+}
+
+// addNamespaces adds everything declared in namespaces.
+func (graph *callGraph) addNamespaces(fileName string, scope string, namespaces []NamespaceModel) {
+	for index := range namespaces {
+		namespace := &namespaces[index]
+		qualifiedName := qualify(scope, namespace.NamespaceName)
+
+		graph.addFunctions(fileName, qualifiedName, "", true, namespace.Functions)
+		graph.addNamespaces(fileName, qualifiedName, namespace.Namespaces)
+		graph.addClasses(fileName, qualifiedName, namespace.Classes)
+	}
+}
+
+// addClasses adds classes declared in scope and their member functions.
+func (graph *callGraph) addClasses(fileName string, scope string, classes []ClassModel) {
+	for index := range classes {
+		class := &classes[index]
+		qualifiedName := qualify(scope, class.Name)
+		startLine, endLine := class.LineRange()
+
+		node := &classNode{
+			symbol: SymbolModel{
+				Kind:          SymbolClass,
+				Name:          class.Name,
+				QualifiedName: qualifiedName,
+				FileName:      fileName,
+				StartLine:     startLine,
+				EndLine:       endLine,
+			},
+		}
+		for _, parent := range class.Parents {
+			node.parents = append(node.parents, lastScopeElement(identifierBeforeParenthesis(parent)))
+		}
+
+		graph.classes = append(graph.classes, node)
+		graph.byClass[class.Name] = append(graph.byClass[class.Name], node)
+
+		for specifier := range class.AccessSpecifierModels {
+			accessSpecifier := &class.AccessSpecifierModels[specifier]
+			public := accessSpecifier.Name == "public"
+
+			graph.addFunctions(fileName, qualifiedName, class.Name, public, accessSpecifier.Functions)
+			graph.addClasses(fileName, qualifiedName, accessSpecifier.Classes)
+		}
+	}
+}
+
+// entryPoint returns the functions named entryPoint, or the public functions of classes named entryPoint.
+// Names may be qualified, like "app::Server".
+func (graph *callGraph) entryPoint(entryPoint string) (roots []*callNode) {
+	name := lastScopeElement(entryPoint)
+
+	for _, node := range graph.byName[name] {
+		if entryPoint == name || node.symbol.QualifiedName == entryPoint {
+			roots = append(roots, node)
+		}
+	}
+
+	for _, class := range graph.byClass[name] {
+		if entryPoint != name && class.symbol.QualifiedName != entryPoint {
+			continue
+		}
+
+		for _, node := range graph.nodes {
+			if node.className == class.symbol.Name && node.public {
+				roots = append(roots, node)
+			}
+		}
+	}
+
+	return roots
+}
+
+// reach marks every function reachable from roots.
+func (graph *callGraph) reach(roots []*callNode) {
+	queue := append([]*callNode{}, roots...)
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if node.reached {
+			continue
+		}
+		node.reached = true
+
+		graph.useTypes(node.function)
+
+		for _, call := range node.function.FunctionBody.Calls {
+			for _, target := range graph.targets(node, call) {
+				if !target.reached {
+					queue = append(queue, target)
+				}
+			}
+		}
+	}
+}
+
+// useTypes records the words of the types used by function.
+func (graph *callGraph) useTypes(function *FunctionModel) {
+	types := []string{function.ReturnType, function.Qualifier()}
+	for _, parameter := range function.Parameters {
+		types = append(types, parameter.Type)
+	}
+	for _, variable := range function.FunctionBody.Variables {
+		types = append(types, variable.Type)
+	}
+	for _, call := range function.FunctionBody.Calls {
+		types = append(types, call.ScopeType(), call.Identifier)
+	}
+
+	for _, typeName := range types {
+		for _, word := range identifierWords(typeName) {
+			graph.usedTypes[word] = true
+		}
+	}
+}
+
+// targets resolves call from caller to the functions it may invoke.
+// Calls on a known type or qualified calls go to that class and the classes it inherits from,
+// plain calls from a member prefer members of the callers class. If that gives nothing every
+// function with the called name is a target. Overrides in derived classes are always included.
+func (graph *callGraph) targets(caller *callNode, call CallModel) []*callNode {
+	name := call.FunctionName()
+	candidates := graph.byName[name]
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	className := call.ScopeType()
+	if qualifier := identifierBeforeParenthesis(call.Identifier); strings.Contains(qualifier, "::") {
+		className = lastScopeElement(qualifier[:strings.LastIndex(qualifier, "::")])
+	}
+	if len(className) == 0 {
+		className = caller.className
+	}
+
+	var targets []*callNode
+	if len(className) > 0 {
+		hierarchy := graph.ancestors(className)
+		for _, candidate := range candidates {
+			if hierarchy[candidate.className] {
+				targets = append(targets, candidate)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return candidates
+	}
+
+	// Virtual calls may end up in any override below the resolved classes.
+	for _, target := range targets {
+		for descendant := range graph.descendants(target.className) {
+			for _, candidate := range candidates {
+				if candidate.className == descendant {
+					targets = append(targets, candidate)
+				}
+			}
+		}
+	}
+
+	return targets
+}
+
+// ancestors returns className and every class it inherits from, directly or not.
+func (graph *callGraph) ancestors(className string) map[string]bool {
+	found := map[string]bool{className: true}
+	queue := []string{className}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, class := range graph.byClass[current] {
+			for _, parent := range class.parents {
+				if !found[parent] {
+					found[parent] = true
+					queue = append(queue, parent)
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// descendants returns every class inheriting from className, directly or not.
+func (graph *callGraph) descendants(className string) map[string]bool {
+	found := make(map[string]bool)
+	queue := []string{className}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, child := range graph.children[current] {
+			if !found[child] {
+				found[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return found
+}
+
+// isVirtual checks if a member function may override, or be overridden by, a function of a related class.
+// Members of classes inheriting from classes outside of the project may override those.
+func (graph *callGraph) isVirtual(node *callNode) bool {
+	if len(node.className) == 0 {
+		return false
+	}
+
+	related := graph.ancestors(node.className)
+	for descendant := range graph.descendants(node.className) {
+		related[descendant] = true
+	}
+	delete(related, node.className)
+
+	for className := range related {
+		if len(graph.byClass[className]) == 0 {
+			return true
+		}
+	}
+
+	for _, candidate := range graph.byName[node.symbol.Name] {
+		if candidate != node && related[candidate.className] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isClassUsed checks if a class is an entry point, has a reachable member, is used as type by
+// reachable code or is inherited from by a used class.
+func (graph *callGraph) isClassUsed(class *classNode, entryPoints []string) bool {
+	return graph.isClassUsedFrom(class, entryPoints, make(map[*classNode]bool))
+}
+
+// isClassUsedFrom checks if class is used, skipping classes in visited to stop at cyclic inheritance.
+func (graph *callGraph) isClassUsedFrom(class *classNode, entryPoints []string, visited map[*classNode]bool) bool {
+	if visited[class] {
+		return false
+	}
+	visited[class] = true
+
+	for _, entryPoint := range entryPoints {
+		if entryPoint == class.symbol.Name || entryPoint == class.symbol.QualifiedName {
+			return true
+		}
+	}
+
+	if graph.usedTypes[class.symbol.Name] {
+		return true
+	}
+
+	for _, node := range graph.nodes {
+		if node.reached && node.className == class.symbol.Name {
+			return true
+		}
+	}
+
+	for _, child := range graph.children[class.symbol.Name] {
+		for _, childClass := range graph.byClass[child] {
+			if graph.isClassUsedFrom(childClass, entryPoints, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// scanUncalledNames reads the files of project below root and collects identifiers used without
+// being called, and words used in strings. Files that can not be read are skipped.
+func scanUncalledNames(project ProjectModel, config RepoConfig, root string) (uncalled map[string]bool, inStrings map[string]bool) {
+	uncalled = make(map[string]bool)
+	inStrings = make(map[string]bool)
+
+	for _, file := range project.Files {
+		filename := filepath.Join(root, filepath.FromSlash(file.FileName))
+		info, err := os.Stat(filename)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxIndexedFileBytes {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			continue
+		}
+
+		language, _ := config.LanguageOf(file.FileName)
+		tokens := Tokenize(string(content), language)
+
+		for index, token := range tokens {
+			switch token.Kind {
+			case TokenString:
+				for _, word := range identifierWords(token.Text) {
+					inStrings[word] = true
+				}
+
+			case TokenIdentifier:
+				// Functions are referred to without a call by taking their address or as method reference.
+				previous := previousSignificant(tokens, index)
+				if nextSignificant(tokens, index) != "(" && previous >= 0 && (tokens[previous].Text == "&" || tokens[previous].Text == "::") {
+					uncalled[token.Text] = true
+				}
+			}
+		}
+	}
+
+	return uncalled, inStrings
+}
+
+// identifierWords splits text into the identifiers it contains.
+func identifierWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !isIdentifierPart(r)
+	})
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+// newDeadCodeProject parses nothing but describes a small C++ project with a file on disk for scanning.
+func newDeadCodeProject(t *testing.T) (ProjectModel, string) {
+	root := setupWalkerRepo(t, map[string]string{
+		"repo/main.cpp": "int main() {\n  run();\n  shape.draw();\n  register(&onClick);\n  invoke(\"byName\");\n}\n",
+	})
+
+	function := func(name string, start int, calls ...CallModel) FunctionModel {
+		return FunctionModel{Name: "void " + name + "()", StartLine: start, EndLine: start + 1, FunctionBody: FunctionBodyModel{Calls: calls}}
+	}
+	class := func(name string, parents []string, functions ...FunctionModel) ClassModel {
+		return ClassModel{Name: name, Parents: parents, AccessSpecifierModels: []AccessSpecifierModel{{Name: "public", Functions: functions}}}
+	}
+
+	project := ProjectModel{Files: []FileModel{{
+		Parsed:   true,
+		FileName: "repo/main.cpp",
+		Functions: []FunctionModel{
+			function("main", 1,
+				CallModel{Identifier: "run"},
+				CallModel{Identifier: "draw", Scope: []ScopeModel{{Identifier: "shape", Type: "Shape &"}}},
+			),
+			function("run", 10),
+			function("helper", 20),
+			function("onClick", 30),
+			function("byName", 40),
+		},
+		Classes: []ClassModel{
+			class("Shape", nil, function("draw", 50)),
+			class("Circle", []string{"Shape"}, function("draw", 60)),
+			class("Legacy", nil, function("legacy", 70)),
+			class("Widget", []string{"ExternalBase"}, function("paint", 80)),
+		},
+	}}}
+
+	return project, root
+}
+
+func TestFindDeadCode(t *testing.T) {
+	project, root := newDeadCodeProject(t)
+	defer os.RemoveAll(root)
+
+	config := defaultRepoConfig
+	report := FindDeadCode(project, config, root)
+
+	want := map[string]string{
+		"helper":         ConfidenceHigh,
+		"onClick":        ConfidenceLow,
+		"byName":         ConfidenceLow,
+		"Legacy::legacy": ConfidenceHigh,
+		"Legacy":         ConfidenceHigh,
+		"Widget::paint":  ConfidenceMedium,
+		"Widget":         ConfidenceHigh,
+	}
+
+	got := make(map[string]string)
+	for _, dead := range report.Dead {
+		got[dead.QualifiedName] = dead.Confidence
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("FindDeadCode() dead = %v, want %v", got, want)
+	}
+	for name, confidence := range want {
+		if got[name] != confidence {
+			t.Errorf("FindDeadCode() confidence of %s = %q, want %q", name, got[name], confidence)
+		}
+	}
+
+	if report.ReachableFunctions != 4 {
+		t.Errorf("FindDeadCode() reachable = %d, want 4", report.ReachableFunctions)
+	}
+
+	// The report is also set on the functions.
+	circleDraw := project.Files[0].Classes[1].AccessSpecifierModels[0].Functions[0]
+	helper := project.Files[0].Functions[2]
+	if circleDraw.Dead || !helper.Dead || helper.DeadConfidence != ConfidenceHigh {
+		t.Errorf("FindDeadCode() overlay Circle::draw = %v, helper = %v %q", circleDraw.Dead, helper.Dead, helper.DeadConfidence)
+	}
+}
+
+func TestFindDeadCode_EntryPoints(t *testing.T) {
+	project, root := newDeadCodeProject(t)
+	defer os.RemoveAll(root)
+
+	config := defaultRepoConfig
+	config.EntryPoints = []string{"Legacy", "missing"}
+	report := FindDeadCode(project, config, root)
+
+	if len(report.UnresolvedEntryPoints) != 1 || report.UnresolvedEntryPoints[0] != "missing" {
+		t.Errorf("FindDeadCode() unresolved = %v, want [missing]", report.UnresolvedEntryPoints)
+	}
+
+	for _, dead := range report.Dead {
+		if dead.QualifiedName == "Legacy" || dead.QualifiedName == "Legacy::legacy" {
+			t.Errorf("FindDeadCode() reported entry point %s as dead", dead.QualifiedName)
+		}
+	}
+}
@@ -0,0 +1,514 @@
+package model
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Kinds of edges in an exported graph.
+const (
+	EdgeContains = "contains" // File, namespace or class declaring the target
+	EdgeCalls    = "calls"    // Function calling the target function
+	EdgeIncludes = "includes" // File including the target file
+	EdgeInherits = "inherits" // Class inheriting from the target class
+)
+
+// Formats a project can be exported in.
+const (
+	ExportGraphML = "graphml"
+	ExportDOT     = "dot"
+	ExportGEXF    = "gexf"
+	ExportCSV     = "csv"
+)
+
+// ExportFormatModel describes a format a project can be exported in.
+type ExportFormatModel struct {
+	ContentType string
+	Extension   string
+	write       func(graph ExportGraph, out io.Writer) error
+}
+
+// ExportFormats maps the name of each export format to its description.
+var ExportFormats = map[string]ExportFormatModel{
+	ExportGraphML: {ContentType: "application/graphml+xml", Extension: ".graphml", write: ExportGraph.WriteGraphML},
+	ExportDOT:     {ContentType: "text/vnd.graphviz", Extension: ".dot", write: ExportGraph.WriteDOT},
+	ExportGEXF:    {ContentType: "application/gexf+xml", Extension: ".gexf", write: ExportGraph.WriteGEXF},
+	ExportCSV:     {ContentType: "text/csv", Extension: ".csv", write: ExportGraph.WriteCSV},
+}
+
+// ExportNodeModel is a file, namespace, class or function of an exported graph.
+type ExportNodeModel struct {
+	ID        string // Unique id of the node in the graph
+	Kind      string // "file" or one of the Symbol kinds
+	Label     string // File name or qualified name
+	FileName  string // File the node is declared in
+	StartLine int    // First line of the declaration when known
+}
+
+// ExportEdgeModel links two nodes of an exported graph.
+type ExportEdgeModel struct {
+	Source string // Id of the source node
+	Target string // Id of the target node
+	Kind   string // One of the Edge kinds
+}
+
+// ExportGraph is the structure of a project with its calls, includes and inheritance.
+type ExportGraph struct {
+	Nodes []ExportNodeModel
+	Edges []ExportEdgeModel
+}
+
+// exportFileKind is the kind of file nodes.
+const exportFileKind = "file"
+
+// exportSymbolKey identifies a class declaration across the export and the call graph.
+type exportSymbolKey struct {
+	fileName      string
+	qualifiedName string
+	startLine     int
+}
+
+// exportBuilder collects the nodes and edges of an export.
+type exportBuilder struct {
+	graph     ExportGraph
+	files     map[string]string         // File name to node id
+	functions map[*FunctionModel]string // Function to node id
+	classes   map[exportSymbolKey]string
+	edges     map[ExportEdgeModel]bool
+}
+
+// BuildExportGraph creates the graph of files, namespaces, classes and functions of project,
+// linked by what declares them and by calls, includes and inheritance.
+func BuildExportGraph(project ProjectModel) ExportGraph {
+	util.TypeLogger.Debug("%s: Call to BuildExportGraph", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to BuildExportGraph", packageName)
+
+	builder := exportBuilder{
+		graph:     ExportGraph{Nodes: []ExportNodeModel{}, Edges: []ExportEdgeModel{}},
+		files:     make(map[string]string),
+		functions: make(map[*FunctionModel]string),
+		classes:   make(map[exportSymbolKey]string),
+		edges:     make(map[ExportEdgeModel]bool),
+	}
+
+	for index := range project.Files {
+		file := &project.Files[index]
+		if _, ok := builder.files[file.FileName]; !ok {
+			builder.files[file.FileName] = builder.node(exportFileKind, file.FileName, file.FileName, 0)
+		}
+	}
+
+	for index := range project.Files {
+		file := &project.Files[index]
+		id := builder.files[file.FileName]
+
+		builder.addFunctions(id, file.FileName, "", file.Functions)
+		builder.addNamespaces(id, file.FileName, "", file.Namespaces)
+		builder.addClasses(id, file.FileName, "", file.Classes)
+
+		for _, includedFile := range file.IncludedFiles {
+			if target, ok := builder.files[includedFile]; ok {
+				builder.edge(id, target, EdgeIncludes)
+			}
+		}
+	}
+
+	calls := newCallGraph(project)
+
+	for _, node := range calls.nodes {
+		source, ok := builder.functions[node.function]
+		if !ok {
+			continue
+		}
+
+		for _, call := range node.function.FunctionBody.Calls {
+			for _, target := range calls.targets(node, call) {
+				if id, ok := builder.functions[target.function]; ok {
+					builder.edge(source, id, EdgeCalls)
+				}
+			}
+		}
+	}
+
+	for _, class := range calls.classes {
+		source, ok := builder.classes[exportSymbolKey{class.symbol.FileName, class.symbol.QualifiedName, class.symbol.StartLine}]
+		if !ok {
+			continue
+		}
+
+		for _, parent := range class.parents {
+			for _, target := range calls.byClass[parent] {
+				if id, ok := builder.classes[exportSymbolKey{target.symbol.FileName, target.symbol.QualifiedName, target.symbol.StartLine}]; ok && id != source {
+					builder.edge(source, id, EdgeInherits)
+				}
+			}
+		}
+	}
+
+	return builder.graph
+}
+
+// Write writes graph to out in format, one of the Export formats.
+func (graph ExportGraph) Write(format string, out io.Writer) error {
+	exportFormat, ok := ExportFormats[format]
+	if !ok {
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+
+	return exportFormat.write(graph, out)
+}
+
+// node adds a node and returns its id.
+func (builder *exportBuilder) node(kind string, label string, fileName string, startLine int) string {
+	id := "n" + strconv.Itoa(len(builder.graph.Nodes))
+	builder.graph.Nodes = append(builder.graph.Nodes, ExportNodeModel{
+		ID:        id,
+		Kind:      kind,
+		Label:     label,
+		FileName:  fileName,
+		StartLine: startLine,
+	})
+
+	return id
+}
+
+// edge adds an edge, once for each source, target and kind.
+func (builder *exportBuilder) edge(source string, target string, kind string) {
+	edge := ExportEdgeModel{Source: source, Target: target, Kind: kind}
+	if builder.edges[edge] {
+		return
+	}
+	builder.edges[edge] = true
+
+	builder.graph.Edges = append(builder.graph.Edges, edge)
+}
+
+// addFunctions adds functions declared in scope inside the node parent.
+func (builder *exportBuilder) addFunctions(parent string, fileName string, scope string, functions []FunctionModel) {
+	for index := range functions {
+		function := &functions[index]
+
+		qualifiedName := qualify(scope, function.Identifier())
+		if qualifier := function.Qualifier(); len(qualifier) > 0 {
+			qualifiedName = qualify(scope, qualify(qualifier, function.Identifier()))
+		}
+		if len(function.Identifier()) == 0 {
+			qualifiedName = qualify(scope, function.Name)
+		}
+
+		id := builder.node(SymbolFunction, qualifiedName, fileName, function.StartLine)
+		builder.functions[function] = id
+		builder.edge(parent, id, EdgeContains)
+	}
+}
+
+// addNamespaces adds namespaces declared in scope inside the node parent, with everything they declare.
+func (builder *exportBuilder) addNamespaces(parent string, fileName string, scope string, namespaces []NamespaceModel) {
+	for index := range namespaces {
+		namespace := &namespaces[index]
+		qualifiedName := qualify(scope, namespace.NamespaceName)
+
+		id := builder.node(SymbolNamespace, qualifiedName, fileName, 0)
+		builder.edge(parent, id, EdgeContains)
+
+		builder.addFunctions(id, fileName, qualifiedName, namespace.Functions)
+		builder.addNamespaces(id, fileName, qualifiedName, namespace.Namespaces)
+		builder.addClasses(id, fileName, qualifiedName, namespace.Classes)
+	}
+}
+
+// addClasses adds classes declared in scope inside the node parent, with their members.
+func (builder *exportBuilder) addClasses(parent string, fileName string, scope string, classes []ClassModel) {
+	for index := range classes {
+		class := &classes[index]
+		qualifiedName := qualify(scope, class.Name)
+		startLine, _ := class.LineRange()
+
+		id := builder.node(SymbolClass, qualifiedName, fileName, startLine)
+		builder.classes[exportSymbolKey{fileName, qualifiedName, startLine}] = id
+		builder.edge(parent, id, EdgeContains)
+
+		for specifier := range class.AccessSpecifierModels {
+			accessSpecifier := &class.AccessSpecifierModels[specifier]
+
+			builder.addFunctions(id, fileName, qualifiedName, accessSpecifier.Functions)
+			builder.addClasses(id, fileName, qualifiedName, accessSpecifier.Classes)
+		}
+	}
+}
+
+// graphMLDocument is the root of a GraphML file.
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey declares an attribute of nodes or edges.
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+// graphMLGraph holds the nodes and edges of a GraphML file.
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+// graphMLNode is a node with its attribute values.
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+// graphMLEdge is an edge with its attribute values.
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphMLData is the value of an attribute.
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes graph as GraphML, readable by yEd and Gephi.
+func (graph ExportGraph) WriteGraphML(out io.Writer) error {
+	document := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "file", For: "node", Name: "file", Type: "string"},
+			{ID: "line", For: "node", Name: "line", Type: "int"},
+			{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "project", EdgeDefault: "directed"},
+	}
+
+	for _, node := range graph.Nodes {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: node.Label},
+				{Key: "kind", Value: node.Kind},
+				{Key: "file", Value: node.FileName},
+				{Key: "line", Value: strconv.Itoa(node.StartLine)},
+			},
+		})
+	}
+
+	for _, edge := range graph.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data:   []graphMLData{{Key: "edge_kind", Value: edge.Kind}},
+		})
+	}
+
+	return writeXML(out, document)
+}
+
+// gexfDocument is the root of a GEXF file.
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+// gexfGraph holds the attributes, nodes and edges of a GEXF file.
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+// gexfAttributes declares the attributes of nodes or edges.
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+// gexfAttribute declares an attribute.
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+// gexfNode is a node with its attribute values.
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+// gexfEdge is an edge with its attribute values.
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+// gexfAttValue is the value of an attribute.
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// WriteGEXF writes graph as GEXF 1.3, readable by Gephi.
+func (graph ExportGraph) WriteGEXF(out io.Writer) error {
+	document := gexfDocument{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{
+					{ID: "kind", Title: "kind", Type: "string"},
+					{ID: "file", Title: "file", Type: "string"},
+					{ID: "line", Title: "line", Type: "integer"},
+				}},
+				{Class: "edge", Attributes: []gexfAttribute{
+					{ID: "kind", Title: "kind", Type: "string"},
+				}},
+			},
+		},
+	}
+
+	for _, node := range graph.Nodes {
+		document.Graph.Nodes = append(document.Graph.Nodes, gexfNode{
+			ID:    node.ID,
+			Label: node.Label,
+			AttValues: []gexfAttValue{
+				{For: "kind", Value: node.Kind},
+				{For: "file", Value: node.FileName},
+				{For: "line", Value: strconv.Itoa(node.StartLine)},
+			},
+		})
+	}
+
+	for index, edge := range graph.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, gexfEdge{
+			ID:        "e" + strconv.Itoa(index),
+			Source:    edge.Source,
+			Target:    edge.Target,
+			Label:     edge.Kind,
+			AttValues: []gexfAttValue{{For: "kind", Value: edge.Kind}},
+		})
+	}
+
+	return writeXML(out, document)
+}
+
+// dotNodeShapes maps node kinds to Graphviz shapes.
+var dotNodeShapes = map[string]string{
+	exportFileKind:  "folder",
+	SymbolNamespace: "tab",
+	SymbolClass:     "box",
+	SymbolFunction:  "ellipse",
+}
+
+// dotEdgeStyles maps edge kinds to Graphviz edge attributes.
+var dotEdgeStyles = map[string]string{
+	EdgeContains: "style=dotted, arrowhead=none",
+	EdgeCalls:    "style=solid",
+	EdgeIncludes: "style=dashed",
+	EdgeInherits: "style=solid, arrowhead=empty",
+}
+
+// WriteDOT writes graph in the Graphviz DOT language.
+func (graph ExportGraph) WriteDOT(out io.Writer) error {
+	var dot strings.Builder
+
+	dot.WriteString("digraph project {\n")
+	dot.WriteString("\trankdir=LR;\n")
+
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&dot, "\t%s [label=%s, shape=%s, kind=%s];\n",
+			node.ID, dotQuote(node.Label), dotNodeShapes[node.Kind], dotQuote(node.Kind))
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&dot, "\t%s -> %s [%s, kind=%s];\n",
+			edge.Source, edge.Target, dotEdgeStyles[edge.Kind], dotQuote(edge.Kind))
+	}
+
+	dot.WriteString("}\n")
+
+	_, err := io.WriteString(out, dot.String())
+	return err
+}
+
+// WriteCSV writes the edges of graph as a table with the kinds and labels of their nodes,
+// which spreadsheets and the Gephi edge list import read.
+func (graph ExportGraph) WriteCSV(out io.Writer) error {
+	nodes := make(map[string]ExportNodeModel)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+
+	writer := csv.NewWriter(out)
+	if err := writer.Write([]string{"Source", "Target", "Kind", "Source Kind", "Target Kind", "Source File", "Target File"}); err != nil {
+		return err
+	}
+
+	for _, edge := range graph.Edges {
+		source, target := nodes[edge.Source], nodes[edge.Target]
+		if err := writer.Write([]string{
+			source.Label,
+			target.Label,
+			edge.Kind,
+			source.Kind,
+			target.Kind,
+			source.FileName,
+			target.FileName,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeXML writes document with an XML header.
+func writeXML(out io.Writer, document interface{}) error {
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// dotQuote quotes text as a DOT string.
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func exportTestProject() ProjectModel {
+	return ProjectModel{Files: []FileModel{
+		{
+			FileName:      "repo/main.cpp",
+			IncludedFiles: []string{"repo/world.hpp"},
+			Functions: []FunctionModel{{
+				Name:         "int main()",
+				StartLine:    3,
+				FunctionBody: FunctionBodyModel{Calls: []CallModel{{Identifier: "update()"}, {Identifier: "update()"}}},
+			}},
+		},
+		{
+			FileName: "repo/world.hpp",
+			Namespaces: []NamespaceModel{{
+				NamespaceName: "game",
+				Classes: []ClassModel{
+					{Name: "System"},
+					{Name: "World", Parents: []string{"System"}, AccessSpecifierModels: []AccessSpecifierModel{
+						{Name: "public", Functions: []FunctionModel{{Name: "void update()", StartLine: 12}}},
+					}},
+				},
+			}},
+		},
+	}}
+}
+
+func TestBuildExportGraph(t *testing.T) {
+	graph := BuildExportGraph(exportTestProject())
+
+	labels := make(map[string]string)
+	for _, node := range graph.Nodes {
+		labels[node.ID] = node.Kind + " " + node.Label
+	}
+
+	var got []string
+	for _, edge := range graph.Edges {
+		got = append(got, labels[edge.Source]+" "+edge.Kind+" "+labels[edge.Target])
+	}
+	sort.Strings(got)
+
+	want := []string{
+		"class game::World contains function game::World::update",
+		"class game::World inherits class game::System",
+		"file repo/main.cpp contains function main",
+		"file repo/main.cpp includes file repo/world.hpp",
+		"file repo/world.hpp contains namespace game",
+		"function main calls function game::World::update",
+		"namespace game contains class game::System",
+		"namespace game contains class game::World",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildExportGraph() edges = %v, want %v", got, want)
+	}
+}
+
+func TestExportGraph_Write(t *testing.T) {
+	graph := BuildExportGraph(exportTestProject())
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{ExportGraphML, []string{`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`, `<data key="label">game::World::update</data>`, `<data key="edge_kind">inherits</data>`}},
+		{ExportGEXF, []string{`<gexf xmlns="http://gexf.net/1.3" version="1.3">`, `label="game::World"`, `label="calls"`}},
+		{ExportDOT, []string{"digraph project {", `[label="game::World", shape=box, kind="class"]`, `[style=dashed, kind="includes"]`}},
+		{ExportCSV, []string{"Source,Target,Kind,Source Kind,Target Kind,Source File,Target File\n", "main,game::World::update,calls,function,function,repo/main.cpp,repo/world.hpp\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := graph.Write(tt.format, &out); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Write() = %s, want it to contain %s", out.String(), want)
+				}
+			}
+
+			if tt.format == ExportGraphML || tt.format == ExportGEXF {
+				if err := xml.Unmarshal(out.Bytes(), new(interface{})); err != nil {
+					t.Errorf("Write() is not valid xml: %v", err)
+				}
+			}
+		})
+	}
+
+	if err := graph.Write("svg", &bytes.Buffer{}); err == nil {
+		t.Errorf("Write() of unknown format succeeded")
+	}
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// FileFilter holds include and exclude globs used when collecting files from a repository.
+// Globs without "/" match the name of a file or directory at any depth,
+// other globs are matched against the path relative to the repository root and may use "**".
+type FileFilter struct {
+	Include []string // A file must match at least one of these globs if any are given
+	Exclude []string // Files and directories matching any of these globs are skipped
+}
+
+// fileWalker holds the rules used to decide which files under a root are collected.
+type fileWalker struct {
+	ignores  map[string][]ignoreRule // Rules from .gitignore files keyed by slash separated directory relative to root
+	includes [][]ignoreRule          // Each list must match a file for it to be collected
+	excludes []ignoreRule
+}
+
+// CollectFiles walks root and returns the path, prefixed by root, of all regular files that are not
+// ignored by .gitignore files, .git/info/exclude or any of the given filters.
+// The ".git" directory is always skipped.
+func CollectFiles(root string, filters ...FileFilter) (files []string, err error) {
+	util.TypeLogger.Debug("%s: Call to CollectFiles", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to CollectFiles", packageName)
+
+	walker := fileWalker{ignores: make(map[string][]ignoreRule)}
+
+	for _, filter := range filters {
+		if includes := parseIgnoreRules(filter.Include); len(includes) > 0 {
+			walker.includes = append(walker.includes, includes)
+		}
+		walker.excludes = append(walker.excludes, parseIgnoreRules(filter.Exclude)...)
+	}
+
+	// Repository local excludes apply like a .gitignore in the root.
+	infoExclude, err := readIgnoreFile(filepath.Join(root, ".git", "info", "exclude"))
+	if err != nil {
+		util.TypeLogger.Warn("%s: Failed to read .git/info/exclude: %s", packageName, err.Error())
+	}
+	walker.ignores["."] = infoExclude
+
+	err = filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
+		// Unreadable entries are skipped like unreadable .gitignore files, only an unreadable root fails.
+		if err != nil && current != root {
+			util.TypeLogger.Warn("%s: Skipping unreadable %s: %s", packageName, current, err.Error())
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry.IsDir() {
+			if rel != "." && (entry.Name() == ".git" || walker.isExcluded(rel, true)) {
+				return filepath.SkipDir
+			}
+			return walker.loadIgnoreFile(rel, current)
+		}
+
+		if !entry.Type().IsRegular() || walker.isExcluded(rel, false) || !walker.isIncluded(rel) {
+			return nil
+		}
+
+		files = append(files, current)
+		return nil
+	})
+
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to walk repository %s: %s", packageName, root, err.Error())
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// loadIgnoreFile reads the .gitignore file in dir and stores its rules for dir.
+func (walker fileWalker) loadIgnoreFile(rel string, dir string) error {
+	rules, err := readIgnoreFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		util.TypeLogger.Warn("%s: Failed to read .gitignore in %s: %s", packageName, rel, err.Error())
+		return nil
+	}
+
+	walker.ignores[rel] = append(walker.ignores[rel], rules...)
+	return nil
+}
+
+// isExcluded checks rel against the exclude globs and all .gitignore files from the root down to its parent.
+// Following git, the last matching .gitignore rule decides and deeper files take precedence.
+func (walker fileWalker) isExcluded(rel string, isDir bool) bool {
+	for _, rule := range walker.excludes {
+		if rule.match(rel, isDir) {
+			return true
+		}
+	}
+
+	ignored := false
+	for _, dir := range parentDirs(rel) {
+		relToDir := rel
+		if dir != "." {
+			relToDir = rel[len(dir)+1:]
+		}
+
+		for _, rule := range walker.ignores[dir] {
+			if rule.match(relToDir, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+// isIncluded checks that rel matches every non-empty list of include globs.
+func (walker fileWalker) isIncluded(rel string) bool {
+	for _, includes := range walker.includes {
+		if !matchesAny(includes, rel) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parentDirs lists the directories containing rel ordered from the root ".".
+func parentDirs(rel string) (dirs []string) {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	return append([]string{"."}, dirs...)
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupWalkerRepo creates a temporary repository with the given files and content.
+func setupWalkerRepo(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "walker")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %s", err.Error())
+	}
+
+	for name, content := range files {
+		filename := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+			t.Fatalf("Could not create directory: %s", err.Error())
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatalf("Could not write file: %s", err.Error())
+		}
+	}
+
+	return root
+}
+
+func TestCollectFiles(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{
+		".git/HEAD":                 "ref: refs/heads/master",
+		".git/info/exclude":         "local.txt\n",
+		".gitignore":                "# build output\nbuild/\n*.o\n!keep.o\n/generated.cpp\n",
+		"local.txt":                 "",
+		"main.cpp":                  "",
+		"main.o":                    "",
+		"keep.o":                    "",
+		"generated.cpp":             "",
+		"build/out.cpp":             "",
+		"src/generated.cpp":         "",
+		"src/util.hpp":              "",
+		"src/.gitignore":            "*.tmp\n",
+		"src/scratch.tmp":           "",
+		"scratch.tmp":               "",
+		"third_party/lib/lib.cpp":   "",
+		"docs/guide/index.java":     "",
+		"docs/guide/deep/more.java": "",
+	})
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		name    string
+		filters []FileFilter
+		want    []string
+	}{
+		{
+			name:    "gitignore_only",
+			filters: nil,
+			want: []string{
+				".gitignore",
+				"docs/guide/deep/more.java",
+				"docs/guide/index.java",
+				"keep.o",
+				"main.cpp",
+				"scratch.tmp",
+				"src/.gitignore",
+				"src/generated.cpp",
+				"src/util.hpp",
+				"third_party/lib/lib.cpp",
+			},
+		},
+		{
+			name:    "exclude_directory_by_name",
+			filters: []FileFilter{{Exclude: []string{"third_party", ".gitignore"}}},
+			want: []string{
+				"docs/guide/deep/more.java",
+				"docs/guide/index.java",
+				"keep.o",
+				"main.cpp",
+				"scratch.tmp",
+				"src/generated.cpp",
+				"src/util.hpp",
+			},
+		},
+		{
+			name:    "include_double_star",
+			filters: []FileFilter{{Include: []string{"docs/**/*.java"}}},
+			want: []string{
+				"docs/guide/deep/more.java",
+				"docs/guide/index.java",
+			},
+		},
+		{
+			name: "config_and_request_filters_combine",
+			filters: []FileFilter{
+				{Include: []string{"src", "*.cpp"}},
+				{Include: []string{"*.hpp", "*.cpp"}, Exclude: []string{"generated.cpp"}},
+			},
+			want: []string{
+				"main.cpp",
+				"src/util.hpp",
+				"third_party/lib/lib.cpp",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := CollectFiles(root, tt.filters...)
+			if err != nil {
+				t.Fatalf("CollectFiles() error = %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(root, file)
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CollectFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectFiles_unreadableDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root reads every directory")
+	}
+
+	root := setupWalkerRepo(t, map[string]string{
+		"main.cpp":           "int main() {}",
+		"private/secret.cpp": "void secret() {}",
+	})
+	defer os.RemoveAll(root)
+
+	private := filepath.Join(root, "private")
+	if err := os.Chmod(private, 0); err != nil {
+		t.Fatalf("Could not make directory unreadable: %s", err.Error())
+	}
+	defer os.Chmod(private, os.ModePerm)
+
+	files, err := CollectFiles(root)
+	if err != nil {
+		t.Fatalf("CollectFiles() error = %v, want unreadable directories skipped", err)
+	}
+	if want := []string{filepath.Join(root, "main.cpp")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("CollectFiles() = %v, want %v", files, want)
+	}
+}
+
+func Test_globMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.cpp", name: "main.cpp", want: true},
+		{pattern: "*.cpp", name: "src/main.cpp", want: false},
+		{pattern: "src/**", name: "src/a/b.cpp", want: true},
+		{pattern: "**/test", name: "a/b/test", want: true},
+		{pattern: "**/test", name: "test", want: true},
+		{pattern: "a/**/b", name: "a/x/y/b", want: true},
+		{pattern: "a/**/b", name: "a/x/y/c", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.name, func(t *testing.T) {
+			if got := globMatch(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
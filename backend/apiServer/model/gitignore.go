@@ -0,0 +1,151 @@
+package model
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+// ignoreRule represents a single line of a .gitignore file or a user supplied glob.
+type ignoreRule struct {
+	pattern  string // Glob pattern without leading "!", leading "/" and trailing "/"
+	negate   bool   // Pattern started with "!" and re-includes matching paths
+	dirOnly  bool   // Pattern ended with "/" and only matches directories
+	anchored bool   // Pattern contains "/" and is matched against the full relative path
+}
+
+// parseIgnoreRule converts a gitignore formated line into a rule.
+// Returns false if the line is empty or a comment.
+func parseIgnoreRule(line string) (rule ignoreRule, ok bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+
+	// Escaped leading characters are part of the pattern.
+	if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+		line = line[1:]
+	} else if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	if len(line) == 0 {
+		return rule, false
+	}
+
+	rule.pattern = line
+	return rule, true
+}
+
+// parseIgnoreRules converts a list of globs into rules, skipping empty entries.
+func parseIgnoreRules(patterns []string) (rules []ignoreRule) {
+	for _, pattern := range patterns {
+		if rule, ok := parseIgnoreRule(pattern); ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// readIgnoreFile reads all rules from a gitignore formated file.
+// A missing file is not an error and returns no rules.
+func readIgnoreFile(filename string) (rules []ignoreRule, err error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, scanner.Err()
+}
+
+// match checks if rel, a slash separated path relative to the rules base directory, matches the rule.
+func (rule ignoreRule) match(rel string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	if rule.anchored {
+		return globMatch(rule.pattern, rel)
+	}
+
+	return globMatch(rule.pattern, path.Base(rel))
+}
+
+// matchesAny checks if rel or any of its parent directories matches one of the rules.
+// Negated rules are ignored as the function is used for include and exclude lists.
+func matchesAny(rules []ignoreRule, rel string) bool {
+	isDir := false
+	for current := rel; current != "." && current != "/"; current = path.Dir(current) {
+		for _, rule := range rules {
+			if !rule.negate && rule.match(current, isDir) {
+				return true
+			}
+		}
+		isDir = true
+	}
+
+	return false
+}
+
+// globMatch matches a slash separated path against a pattern where "**" matches any number of directories.
+func globMatch(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments recursively matches pattern segments against path segments.
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated "**" and try every possible remaining tail.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
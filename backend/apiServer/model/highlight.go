@@ -0,0 +1,49 @@
+package model
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// HighlightHTML renders the tokens from startLine through endLine as html.
+// Every token except whitespace is wrapped in a span with the class "tok-<kind>",
+// identifiers referring to a parsed symbol carry its location in data attributes.
+// Lines are separated by "\n" so the result can be placed in a pre element.
+func HighlightHTML(tokens []TokenModel, startLine int, endLine int) string {
+	var builder strings.Builder
+
+	line := startLine
+	for _, token := range tokens {
+		if token.Line < startLine || token.Line > endLine {
+			continue
+		}
+
+		for ; line < token.Line; line++ {
+			builder.WriteString("\n")
+		}
+
+		if token.Kind == TokenWhitespace {
+			builder.WriteString(html.EscapeString(token.Text))
+			continue
+		}
+
+		builder.WriteString(`<span class="tok-` + token.Kind + `"`)
+		if token.Ref != nil {
+			builder.WriteString(` data-kind="` + html.EscapeString(token.Ref.Kind) + `"`)
+			builder.WriteString(` data-name="` + html.EscapeString(token.Ref.QualifiedName) + `"`)
+			builder.WriteString(` data-file="` + html.EscapeString(token.Ref.FileName) + `"`)
+			if token.Ref.StartLine > 0 {
+				builder.WriteString(` data-start-line="` + strconv.Itoa(token.Ref.StartLine) + `"`)
+				builder.WriteString(` data-end-line="` + strconv.Itoa(token.Ref.EndLine) + `"`)
+			}
+		}
+		builder.WriteString(">" + html.EscapeString(token.Text) + "</span>")
+	}
+
+	for ; line < endLine; line++ {
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
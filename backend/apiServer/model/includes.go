@@ -0,0 +1,175 @@
+package model
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// includePattern matches C and C++ include directives, capturing the included path.
+var includePattern = regexp.MustCompile(`^\s*#\s*include\s*[<"]([^>"]+)[>"]`)
+
+// scanIncludes reads the include directives of a C++ file.
+func scanIncludes(filename string) (includes []string, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if match := includePattern.FindStringSubmatch(scanner.Text()); match != nil {
+			includes = append(includes, match[1])
+		}
+	}
+
+	return includes, scanner.Err()
+}
+
+// resolveIncludes finds the files in the repository that the includes of file refer to.
+// The directory of file is searched first followed by includeRoots relative to root.
+// Includes that can not be found among files, e.g. system headers, are left out.
+func resolveIncludes(root string, file string, includes []string, includeRoots []string, files map[string]bool) (resolved []string) {
+	searchDirs := []string{filepath.Dir(file)}
+	for _, includeRoot := range includeRoots {
+		searchDirs = append(searchDirs, filepath.Join(root, includeRoot))
+	}
+
+	for _, include := range includes {
+		for _, dir := range searchDirs {
+			candidate := filepath.Join(dir, filepath.FromSlash(include))
+			if files[candidate] {
+				resolved = append(resolved, candidate)
+				break
+			}
+		}
+	}
+
+	return resolved
+}
+
+// IncludeCycleModel represents files including each other in a cycle.
+type IncludeCycleModel struct {
+	Files []string `json:"files"` // Files in include order, the last includes the first
+}
+
+// FindIncludeCycles reports a cycle for each group of files of project that include each other,
+// directly or through other files. Each cycle starts at the file of the group sorting first.
+func FindIncludeCycles(project ProjectModel) []IncludeCycleModel {
+	util.TypeLogger.Debug("%s: Call to FindIncludeCycles", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FindIncludeCycles", packageName)
+
+	graph := make(map[string][]string)
+	var files []string
+	for _, file := range project.Files {
+		if _, ok := graph[file.FileName]; !ok {
+			files = append(files, file.FileName)
+		}
+		graph[file.FileName] = append(graph[file.FileName], file.IncludedFiles...)
+	}
+	sort.Strings(files)
+
+	cycles := []IncludeCycleModel{}
+	for _, component := range stronglyConnected(files, graph) {
+		sort.Strings(component)
+
+		members := make(map[string]bool)
+		for _, file := range component {
+			members[file] = true
+		}
+
+		if cycle := shortestCycle(component[0], graph, members); cycle != nil {
+			cycles = append(cycles, IncludeCycleModel{Files: cycle})
+		}
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool {
+		return cycles[i].Files[0] < cycles[j].Files[0]
+	})
+
+	return cycles
+}
+
+// stronglyConnected splits the nodes of graph into groups reachable from each other with Tarjan's algorithm.
+func stronglyConnected(nodes []string, graph map[string][]string) (components [][]string) {
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range graph[node] {
+			if _, visited := index[next]; !visited {
+				visit(next)
+				if lowLink[next] < lowLink[node] {
+					lowLink[node] = lowLink[next]
+				}
+			} else if onStack[next] && index[next] < lowLink[node] {
+				lowLink[node] = index[next]
+			}
+		}
+
+		if lowLink[node] != index[node] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			visit(node)
+		}
+	}
+
+	return components
+}
+
+// shortestCycle finds the shortest path from start back to itself through members of graph, nil if there is none.
+func shortestCycle(start string, graph map[string][]string, members map[string]bool) []string {
+	previous := make(map[string]string)
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, next := range graph[node] {
+			if next == start {
+				cycle := []string{node}
+				for cycle[0] != start {
+					cycle = append([]string{previous[cycle[0]]}, cycle...)
+				}
+				return cycle
+			}
+
+			if _, seen := previous[next]; !seen && members[next] {
+				previous[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
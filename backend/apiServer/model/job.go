@@ -0,0 +1,132 @@
+package model
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Kinds of jobs.
+const (
+	JobAdd   = "add"   // Clones a repository and parses it
+	JobParse = "parse" // Parses a repository again
+)
+
+// Statuses of jobs, the same as sent over the websockets.
+const (
+	JobQueued      = "Queued"      // Waiting for a clone or parse slot
+	JobCloning     = "Cloning"     // Cloning the repository
+	JobParsing     = "Parsing"     // Parsing the repository
+	JobDone        = "Done"        // Finished, the project of the repository is available
+	JobFailed      = "Failed"      // Stopped with Error
+	JobInterrupted = "Interrupted" // Stopped by a shutdown of the server, parse again to continue
+)
+
+// jobRetention is how long finished jobs are kept.
+const jobRetention = time.Hour
+
+// ErrJobNotFound is returned for jobs that do not exist or expired.
+var ErrJobNotFound = errors.New("Job not found")
+
+// JobModel is a clone or parse running in the background for the versioned api.
+type JobModel struct {
+	ID               string        `json:"id"`                    // Id of the job
+	Kind             string        `json:"kind"`                  // JobAdd or JobParse
+	RepoID           string        `json:"repoId,omitempty"`      // Repository worked on, empty until it is added
+	Owner            bson.ObjectId `json:"-"`                     // User who started the job
+	Status           string        `json:"status"`                // One of the job statuses
+	Position         int           `json:"position,omitempty"`    // Place in the queue when it was queued
+	CurrentFile      string        `json:"currentFile,omitempty"` // File last parsed
+	ParsedFileCount  int           `json:"parsedFileCount"`       // Files parsed so far
+	SkippedFileCount int           `json:"skippedFileCount"`      // Files considered but not parsed
+	FileCount        int           `json:"fileCount"`             // Files in the repository being considered
+	Error            *ErrorModel   `json:"error,omitempty"`       // Why the job failed or was interrupted
+	Created          time.Time     `json:"created"`               // When the job was started
+	Updated          time.Time     `json:"updated"`               // When the job last changed
+}
+
+// Jobs of the server, kept in memory until jobRetention after they finished.
+var (
+	jobsMutex sync.Mutex
+	jobs      = make(map[string]*JobModel)
+)
+
+// NewJob registers a job of kind on the repository with repoID for owner.
+func NewJob(kind string, repoID string, owner bson.ObjectId) JobModel {
+	util.TypeLogger.Debug("%s: Call to NewJob", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to NewJob", packageName)
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	now := time.Now().UTC()
+	pruneJobs(now)
+
+	job := &JobModel{
+		ID:      bson.NewObjectId().Hex(),
+		Kind:    kind,
+		RepoID:  repoID,
+		Owner:   owner,
+		Status:  JobQueued,
+		Created: now,
+		Updated: now,
+	}
+	jobs[job.ID] = job
+
+	return *job
+}
+
+// GetJob returns the job with id.
+func GetJob(id string) (JobModel, error) {
+	util.TypeLogger.Debug("%s: Call to GetJob", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to GetJob", packageName)
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	job, ok := jobs[id]
+	if !ok {
+		return JobModel{}, ErrJobNotFound
+	}
+
+	return *job, nil
+}
+
+// UpdateJob changes the job with id by update.
+func UpdateJob(id string, update func(job *JobModel)) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	job, ok := jobs[id]
+	if !ok {
+		return
+	}
+
+	update(job)
+	job.Updated = time.Now().UTC()
+}
+
+// Finished tells if the job stopped running.
+func (job JobModel) Finished() bool {
+	return job.Status == JobDone || job.Status == JobFailed || job.Status == JobInterrupted
+}
+
+// Progress copies the counts of a parse response into the job.
+func (job *JobModel) Progress(response ParseResponse) {
+	job.CurrentFile = response.CurrentFile
+	job.ParsedFileCount = response.ParsedFileCount
+	job.SkippedFileCount = response.SkippedFileCount
+	job.FileCount = response.FileCount
+}
+
+// pruneJobs removes the jobs finished jobRetention before now.
+func pruneJobs(now time.Time) {
+	for id, job := range jobs {
+		if job.Finished() && now.Sub(job.Updated) > jobRetention {
+			delete(jobs, id)
+		}
+	}
+}
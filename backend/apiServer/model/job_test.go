@@ -0,0 +1,57 @@
+package model
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestJobs(t *testing.T) {
+	owner := bson.NewObjectId()
+	job := NewJob(JobAdd, "", owner)
+
+	if job.Status != JobQueued || job.Owner != owner || len(job.ID) == 0 {
+		t.Fatalf("NewJob() = %+v, want queued job of owner", job)
+	}
+
+	UpdateJob(job.ID, func(job *JobModel) {
+		job.RepoID = "5c62d1904122c760dafe9341"
+		job.Status = JobParsing
+		job.Progress(ParseResponse{CurrentFile: "main.cpp", ParsedFileCount: 1, SkippedFileCount: 2, FileCount: 5})
+	})
+
+	got, err := GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if got.Status != JobParsing || got.RepoID != "5c62d1904122c760dafe9341" || got.ParsedFileCount != 1 || got.FileCount != 5 {
+		t.Errorf("GetJob() = %+v, want updated job", got)
+	}
+	if got.Finished() {
+		t.Errorf("Finished() = true for a parsing job")
+	}
+
+	if _, err := GetJob(bson.NewObjectId().Hex()); err != ErrJobNotFound {
+		t.Errorf("GetJob() of unknown job error = %v, want %v", err, ErrJobNotFound)
+	}
+
+	// Finished jobs expire, running ones are kept
+	UpdateJob(job.ID, func(job *JobModel) {
+		job.Status = JobFailed
+		job.Error = NewError(http.StatusConflict, "Repository already exists")
+	})
+	running := NewJob(JobParse, "5c62d1904122c760dafe9341", owner)
+
+	jobsMutex.Lock()
+	pruneJobs(time.Now().UTC().Add(2 * jobRetention))
+	jobsMutex.Unlock()
+
+	if _, err := GetJob(job.ID); err != ErrJobNotFound {
+		t.Errorf("GetJob() of expired job error = %v, want %v", err, ErrJobNotFound)
+	}
+	if _, err := GetJob(running.ID); err != nil {
+		t.Errorf("GetJob() of running job error = %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxCachedLineIndexes limits how many files line offsets are kept for.
+const maxCachedLineIndexes = 1024
+
+// ErrLineOutOfRange is returned when a requested start line is past the end of a file.
+var ErrLineOutOfRange = errors.New("StartLine out of range")
+
+// lineIndex holds the byte offset where each line of a file starts.
+type lineIndex struct {
+	modTime time.Time
+	size    int64
+	offsets []int64 // offsets[n] is the start of line n+1, the last entry is the size of the file
+}
+
+// lineIndexCache caches line indexes by filename, rebuilding them when the file changes,
+// and drops the least recently used index beyond max indexes.
+type lineIndexCache struct {
+	mutex   sync.Mutex
+	max     int
+	indexes map[string]*list.Element // Elements of recent by filename
+	recent  *list.List               // Cached indexes from most to least recently used
+}
+
+// cachedLineIndex is an element of lineIndexCache.recent.
+type cachedLineIndex struct {
+	filename string
+	index    *lineIndex
+}
+
+// lineIndexes is the cache shared by all reads of repository files.
+var lineIndexes = newLineIndexCache(maxCachedLineIndexes)
+
+// newLineIndexCache creates a cache keeping at most max indexes.
+func newLineIndexCache(max int) *lineIndexCache {
+	return &lineIndexCache{max: max, indexes: make(map[string]*list.Element), recent: list.New()}
+}
+
+// lines returns the number of lines in the indexed file.
+// A last line without a line break is counted.
+func (index *lineIndex) lines() int {
+	return len(index.offsets) - 1
+}
+
+// get returns the line index of filename, building it if it is missing or outdated.
+func (cache *lineIndexCache) get(filename string, info os.FileInfo) (*lineIndex, error) {
+	cache.mutex.Lock()
+	if element, ok := cache.indexes[filename]; ok {
+		index := element.Value.(*cachedLineIndex).index
+		if index.size == info.Size() && index.modTime.Equal(info.ModTime()) {
+			cache.recent.MoveToFront(element)
+			cache.mutex.Unlock()
+			return index, nil
+		}
+	}
+	cache.mutex.Unlock()
+
+	index, err := buildLineIndex(filename, info)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// A changed file replaces its own index without dropping others.
+	if element, ok := cache.indexes[filename]; ok {
+		element.Value.(*cachedLineIndex).index = index
+		cache.recent.MoveToFront(element)
+		return index, nil
+	}
+
+	cache.indexes[filename] = cache.recent.PushFront(&cachedLineIndex{filename: filename, index: index})
+
+	// Drop the least recently used index to stay within bounds.
+	if cache.recent.Len() > cache.max {
+		oldest := cache.recent.Back()
+		cache.recent.Remove(oldest)
+		delete(cache.indexes, oldest.Value.(*cachedLineIndex).filename)
+	}
+
+	return index, nil
+}
+
+// buildLineIndex reads filename once and records the offset of every line.
+func buildLineIndex(filename string, info os.FileInfo) (*lineIndex, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	index := &lineIndex{modTime: info.ModTime(), size: info.Size(), offsets: []int64{0}}
+
+	reader := bufio.NewReader(file)
+	buffer := make([]byte, 32*1024)
+	var offset int64
+
+	for {
+		n, err := reader.Read(buffer)
+		chunk := buffer[:n]
+		for {
+			newline := bytes.IndexByte(chunk, '\n')
+			if newline < 0 {
+				break
+			}
+			offset += int64(newline + 1)
+			index.offsets = append(index.offsets, offset)
+			chunk = chunk[newline+1:]
+		}
+		offset += int64(len(chunk))
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Count a last line that is not terminated by a line break.
+	if offset > index.offsets[len(index.offsets)-1] {
+		index.offsets = append(index.offsets, offset)
+	}
+	index.size = offset
+
+	return index, nil
+}
+
+// openLineIndex stats filename and returns its line index.
+func openLineIndex(filename string) (*lineIndex, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return lineIndexes.get(filename, info)
+}
+
+// CountLines returns the number of lines in filename.
+func CountLines(filename string) (int, error) {
+	index, err := openLineIndex(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	return index.lines(), nil
+}
+
+// ReadLines returns line startLine through endLine, both included and starting at 1, from filename.
+// endLine is clamped to the end of the file. Windows line endings are returned as "\n".
+func ReadLines(filename string, startLine int, endLine int) (string, error) {
+	index, err := openLineIndex(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if startLine > index.lines() {
+		return "", ErrLineOutOfRange
+	}
+	if endLine > index.lines() {
+		endLine = index.lines()
+	}
+	if endLine < startLine {
+		return "", nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	start := index.offsets[startLine-1]
+	content := make([]byte, index.offsets[endLine]-start)
+	if _, err := file.ReadAt(content, start); err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return string(bytes.Replace(content, []byte("\r\n"), []byte("\n"), -1)), nil
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{
+		"unix.txt":             "one\ntwo\nthree\n",
+		"windows.txt":          "one\r\ntwo\r\nthree",
+		"empty.txt":            "",
+		"odd name (1) 'x'.txt": "only\n",
+	})
+	defer os.RemoveAll(root)
+
+	tests := []struct {
+		name      string
+		file      string
+		startLine int
+		endLine   int
+		want      string
+		wantLines int
+		wantErr   error
+	}{
+		{name: "Valid_whole_file", file: "unix.txt", startLine: 1, endLine: 3, want: "one\ntwo\nthree\n", wantLines: 3},
+		{name: "Valid_interval", file: "unix.txt", startLine: 2, endLine: 2, want: "two\n", wantLines: 3},
+		{name: "Valid_end_past_EOF", file: "unix.txt", startLine: 2, endLine: 100, want: "two\nthree\n", wantLines: 3},
+		{name: "Valid_crlf_without_last_break", file: "windows.txt", startLine: 2, endLine: 3, want: "two\nthree", wantLines: 3},
+		{name: "Valid_odd_file_name", file: "odd name (1) 'x'.txt", startLine: 1, endLine: 1, want: "only\n", wantLines: 1},
+		{name: "Invalid_start_past_EOF", file: "unix.txt", startLine: 4, endLine: 5, wantLines: 3, wantErr: ErrLineOutOfRange},
+		{name: "Invalid_empty_file", file: "empty.txt", startLine: 1, endLine: 1, wantLines: 0, wantErr: ErrLineOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(root, tt.file)
+
+			got, err := ReadLines(filename, tt.startLine, tt.endLine)
+			if err != tt.wantErr {
+				t.Fatalf("ReadLines() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadLines() = %q, want %q", got, tt.want)
+			}
+
+			lines, err := CountLines(filename)
+			if err != nil || lines != tt.wantLines {
+				t.Errorf("CountLines() = %d, %v, want %d", lines, err, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestReadLines_fileChanged(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{"file.txt": "a\nb\n"})
+	defer os.RemoveAll(root)
+	filename := filepath.Join(root, "file.txt")
+
+	if got, _ := ReadLines(filename, 2, 2); got != "b\n" {
+		t.Fatalf("ReadLines() = %q, want %q", got, "b\n")
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("a\nlonger line\nc\n"), 0644); err != nil {
+		t.Fatalf("Could not rewrite file: %s", err.Error())
+	}
+
+	if got, _ := ReadLines(filename, 2, 3); got != "longer line\nc\n" {
+		t.Errorf("ReadLines() after change = %q, want %q", got, "longer line\nc\n")
+	}
+}
+
+func TestReadLines_concurrent(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{"file.txt": "a\nb\nc\nd\n"})
+	defer os.RemoveAll(root)
+	filename := filepath.Join(root, "file.txt")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := ReadLines(filename, 3, 4); err != nil || got != "c\nd\n" {
+				t.Errorf("ReadLines() = %q, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLineIndexCache(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n", "c.txt": "c\n"})
+	defer os.RemoveAll(root)
+	cache := newLineIndexCache(2)
+
+	get := func(name string) *lineIndex {
+		filename := filepath.Join(root, name)
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatalf("Could not stat %s: %s", name, err.Error())
+		}
+		index, err := cache.get(filename, info)
+		if err != nil {
+			t.Fatalf("get(%s) error = %v", name, err)
+		}
+		return index
+	}
+	cached := func(name string) bool {
+		_, ok := cache.indexes[filepath.Join(root, name)]
+		return ok
+	}
+
+	get("a.txt")
+	b := get("b.txt")
+
+	// Rebuilding a changed file keeps the other index
+	if err := ioutil.WriteFile(filepath.Join(root, "a.txt"), []byte("a\nchanged\n"), 0644); err != nil {
+		t.Fatalf("Could not rewrite file: %s", err.Error())
+	}
+	if index := get("a.txt"); index.lines() != 2 {
+		t.Errorf("lines() = %d after change, want 2", index.lines())
+	}
+	if !cached("b.txt") || cache.recent.Len() != 2 {
+		t.Fatalf("refreshing a.txt dropped b.txt")
+	}
+
+	// b.txt is the least recently used once read before a.txt
+	if get("b.txt") != b {
+		t.Errorf("get(b.txt) rebuilt an unchanged file")
+	}
+	get("a.txt")
+	get("c.txt")
+	if cached("b.txt") || !cached("a.txt") || !cached("c.txt") {
+		t.Errorf("cached a %t, b %t, c %t, want b.txt dropped", cached("a.txt"), cached("b.txt"), cached("c.txt"))
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+// ProjectMetrics summarizes the size of a parsed project.
+type ProjectMetrics struct {
+	Files            int `json:"files"`              // Files collected
+	ParsedFiles      int `json:"parsed_files"`       // Files parsed successfully
+	Lines            int `json:"lines"`              // Lines in parsed files
+	Functions        int `json:"functions"`          // Functions declared
+	Classes          int `json:"classes"`            // Classes declared
+	Namespaces       int `json:"namespaces"`         // Namespaces declared
+	MaxFunctionLines int `json:"max_function_lines"` // Lines of the longest function
+	MaxParameters    int `json:"max_parameters"`     // Parameters of the function with the most parameters
+}
+
+// ComputeMetrics counts files, lines and declarations of project.
+func ComputeMetrics(project ProjectModel) ProjectMetrics {
+	metrics := ProjectMetrics{Files: len(project.Files)}
+
+	for _, file := range project.Files {
+		if file.Parsed {
+			metrics.ParsedFiles++
+			metrics.Lines += file.LinesInFile
+		}
+
+		for _, symbol := range file.Symbols() {
+			switch symbol.Kind {
+			case SymbolClass:
+				metrics.Classes++
+			case SymbolNamespace:
+				metrics.Namespaces++
+			}
+		}
+
+		for _, function := range fileFunctions(file) {
+			metrics.Functions++
+
+			if lines := function.EndLine - function.StartLine + 1; function.StartLine > 0 && lines > metrics.MaxFunctionLines {
+				metrics.MaxFunctionLines = lines
+			}
+			if len(function.Parameters) > metrics.MaxParameters {
+				metrics.MaxParameters = len(function.Parameters)
+			}
+		}
+	}
+
+	return metrics
+}
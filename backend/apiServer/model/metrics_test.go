@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	project := ProjectModel{Files: []FileModel{
+		{
+			FileName:    "repo/world.cpp",
+			Parsed:      true,
+			LinesInFile: 120,
+			Functions: []FunctionModel{
+				{Name: "int main(int argc, char** argv)", Parameters: []ParameterModel{{Name: "argc"}, {Name: "argv"}}, StartLine: 1, EndLine: 30},
+			},
+			Namespaces: []NamespaceModel{{
+				NamespaceName: "game",
+				Classes: []ClassModel{{
+					Name: "World",
+					AccessSpecifierModels: []AccessSpecifierModel{
+						{Name: "public", Functions: []FunctionModel{{Name: "void update()", StartLine: 40, EndLine: 45}}},
+					},
+				}},
+			}},
+		},
+		{FileName: "repo/README.md"},
+	}}
+
+	want := ProjectMetrics{
+		Files:            2,
+		ParsedFiles:      1,
+		Lines:            120,
+		Functions:        2,
+		Classes:          1,
+		Namespaces:       1,
+		MaxFunctionLines: 30,
+		MaxParameters:    2,
+	}
+
+	if got := ComputeMetrics(project); got != want {
+		t.Errorf("ComputeMetrics() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// SchemaVersion is the version of the parse result schema this server writes.
+// Stored projects with an older version are migrated when read. Versions:
+//  1. Projects stored before the version was recorded, it is missing from their documents.
+//  2. File names are relative to the repository path with forward slashes, starting with the repository folder.
+const SchemaVersion = 2
+
+// ErrSchemaTooNew is returned for projects stored by a newer server than this one.
+var ErrSchemaTooNew = errors.New("Stored schema version is newer than supported")
+
+// migrations upgrade a stored project to the version they are keyed by from the version before it.
+var migrations = map[int]func(project *ProjectModel, repoPath string){
+	2: migrateRelativeFileNames,
+}
+
+// MigrateProject upgrades project, cloned into repoPath, to SchemaVersion, reporting if anything was changed.
+// Projects without files have nothing to migrate and are only given the current version.
+func MigrateProject(project *ProjectModel, repoPath string) (migrated bool, err error) {
+	util.TypeLogger.Debug("%s: Call to MigrateProject", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to MigrateProject", packageName)
+
+	version := project.SchemaVersion
+	if version == 0 {
+		version = 1
+	}
+
+	if version > SchemaVersion {
+		return false, ErrSchemaTooNew
+	}
+
+	if len(project.Files) == 0 {
+		project.SchemaVersion = SchemaVersion
+		return false, nil
+	}
+
+	for version < SchemaVersion {
+		version++
+		if migration, ok := migrations[version]; ok {
+			util.TypeLogger.Info("%s: Migrating stored project to schema version %d", packageName, version)
+			migration(project, repoPath)
+		}
+		migrated = true
+	}
+
+	project.SchemaVersion = SchemaVersion
+
+	return migrated, nil
+}
+
+// migrateRelativeFileNames removes repoPath left in file names of version 1, which only trimmed it
+// when it matched exactly, and makes the names use forward slashes.
+func migrateRelativeFileNames(project *ProjectModel, repoPath string) {
+	clean := func(fileName string) string {
+		fileName = filepath.ToSlash(fileName)
+		if len(repoPath) > 0 {
+			fileName = strings.TrimPrefix(fileName, strings.TrimSuffix(filepath.ToSlash(repoPath), "/")+"/")
+		}
+		return strings.TrimPrefix(fileName, "./")
+	}
+
+	for index := range project.Files {
+		file := &project.Files[index]
+		file.FileName = clean(file.FileName)
+		for include := range file.IncludedFiles {
+			file.IncludedFiles[include] = clean(file.IncludedFiles[include])
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Kinds of references found by FindReferences.
+const (
+	ReferenceDefinition = "definition"
+	ReferenceCall       = "call"
+	ReferenceUse        = "reference"
+)
+
+// ErrNoSymbol is returned when there is no identifier of a parsed symbol at a position.
+var ErrNoSymbol = errors.New("No symbol at position")
+
+// ReferenceModel represents an identifier in code referring to a symbol.
+type ReferenceModel struct {
+	Kind     string `json:"kind"`      // One of the Reference kinds
+	FileName string `json:"file_name"` // File the reference is found in
+	Line     int    `json:"line"`      // Line of the identifier starting at 1
+	Column   int    `json:"column"`    // Column of the first character of the identifier starting at 1
+	Snippet  string `json:"snippet"`   // The line the reference is found on
+}
+
+// navigator resolves identifiers in the files of a parsed repository.
+type navigator struct {
+	root    string // Folder repositories are cloned into
+	repoID  string
+	project ProjectModel
+	config  RepoConfig
+	globals []SymbolModel // Symbols of all files that are visible outside of their function
+}
+
+// newNavigator collects the symbols of project for resolving identifiers in repository repoID cloned into root.
+func newNavigator(root string, repoID string, project ProjectModel, config RepoConfig) navigator {
+	nav := navigator{root: root, repoID: repoID, project: project, config: config}
+
+	for _, file := range project.Files {
+		for _, symbol := range file.Symbols() {
+			if !symbol.Local {
+				nav.globals = append(nav.globals, symbol)
+			}
+		}
+	}
+
+	return nav
+}
+
+// FindDefinition returns the symbol the identifier at line and column of filePath refers to.
+// filePath is given as in the parsed file names, line and column start at 1.
+func (repo RepoModel) FindDefinition(storage config.Storage, filePath string, line int, column int) (SymbolModel, error) {
+	util.TypeLogger.Debug("%s: Call to FindDefinition", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FindDefinition", packageName)
+
+	config, err := repo.GetConfig(storage)
+	if err != nil {
+		return SymbolModel{}, err
+	}
+
+	return newNavigator(storage.RepoPath, repo.ID.Hex(), repo.ParsedRepo, config).definitionAt(filePath, line, column)
+}
+
+// FindReferences returns the symbol at line and column of filePath and every identifier in the repository referring to it.
+func (repo RepoModel) FindReferences(storage config.Storage, filePath string, line int, column int) (SymbolModel, []ReferenceModel, error) {
+	util.TypeLogger.Debug("%s: Call to FindReferences", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FindReferences", packageName)
+
+	config, err := repo.GetConfig(storage)
+	if err != nil {
+		return SymbolModel{}, nil, err
+	}
+
+	nav := newNavigator(storage.RepoPath, repo.ID.Hex(), repo.ParsedRepo, config)
+
+	symbol, err := nav.definitionAt(filePath, line, column)
+	if err != nil {
+		return SymbolModel{}, nil, err
+	}
+
+	return symbol, nav.references(symbol), nil
+}
+
+// definitionAt resolves the identifier at line and column of filePath.
+func (nav navigator) definitionAt(filePath string, line int, column int) (SymbolModel, error) {
+	file, ok := nav.project.FindFile(filePath)
+	if !ok {
+		return SymbolModel{}, ErrFileNotFound
+	}
+
+	tokens, _, err := nav.tokenize(filePath)
+	if err != nil {
+		return SymbolModel{}, err
+	}
+
+	for index, token := range tokens {
+		if token.Line != line || column < token.Column || column >= token.Column+len([]rune(token.Text)) {
+			continue
+		}
+
+		if token.Kind != TokenIdentifier {
+			break
+		}
+
+		if symbol := nav.resolve(file, tokens, index); symbol != nil {
+			return *symbol, nil
+		}
+		break
+	}
+
+	return SymbolModel{}, ErrNoSymbol
+}
+
+// references finds the identifiers in all parsed files resolving to symbol.
+// Locals are only searched for in the file they are declared in.
+func (nav navigator) references(symbol SymbolModel) []ReferenceModel {
+	references := []ReferenceModel{}
+
+	for _, file := range nav.project.Files {
+		if symbol.Local && file.FileName != symbol.FileName {
+			continue
+		}
+
+		tokens, lines, err := nav.tokenize(file.FileName)
+		if err != nil {
+			util.TypeLogger.Warn("%s: Skipping file when finding references: %s", packageName, file.FileName)
+			continue
+		}
+
+		for index, token := range tokens {
+			if token.Kind != TokenIdentifier || token.Text != symbol.Name {
+				continue
+			}
+
+			resolved := nav.resolve(file, tokens, index)
+			if resolved == nil || !sameSymbol(*resolved, symbol) {
+				continue
+			}
+
+			kind := ReferenceUse
+			switch {
+			case file.FileName == symbol.FileName && token.Line == symbol.StartLine && !symbol.Local:
+				kind = ReferenceDefinition
+			case symbol.Kind == SymbolFunction && nextSignificant(tokens, index) == "(":
+				kind = ReferenceCall
+			}
+
+			references = append(references, ReferenceModel{
+				Kind:     kind,
+				FileName: file.FileName,
+				Line:     token.Line,
+				Column:   token.Column,
+				Snippet:  strings.TrimSpace(lines[token.Line-1]),
+			})
+		}
+	}
+
+	return references
+}
+
+// tokenize reads a file of the repository and splits it into tokens and lines.
+func (nav navigator) tokenize(filePath string) ([]TokenModel, []string, error) {
+	filename, err := ResolveRepoFile(nav.root, nav.repoID, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source := strings.Replace(string(content), "\r\n", "\n", -1)
+	language, _ := nav.config.LanguageOf(filePath)
+
+	return Tokenize(source, language), strings.Split(source, "\n"), nil
+}
+
+// resolve finds the symbol the identifier token at index of file refers to.
+// Symbols of file itself are preferred, unless the identifier is qualified or accessed
+// as a member, then the qualifier or the type from the parsed call decides.
+func (nav navigator) resolve(file FileModel, tokens []TokenModel, index int) *SymbolModel {
+	token := tokens[index]
+	qualifier, member := qualifierBefore(tokens, index)
+
+	if len(qualifier) == 0 && !member {
+		if symbol := resolveSymbol(file.Symbols(), token.Text, token.Line); symbol != nil {
+			return symbol
+		}
+	}
+
+	if member {
+		qualifier = callQualifier(file, token.Text, token.Line)
+	}
+
+	included := make(map[string]bool)
+	for _, includedFile := range file.IncludedFiles {
+		included[includedFile] = true
+	}
+
+	var best *SymbolModel
+	bestRank := 0
+
+	for candidate := range nav.globals {
+		symbol := &nav.globals[candidate]
+		if symbol.Name != token.Text || (member && symbol.Kind != SymbolFunction && symbol.Kind != SymbolVariable) {
+			continue
+		}
+
+		rank := 1
+		if len(qualifier) > 0 && qualifiedBy(symbol.QualifiedName, qualifier) {
+			rank += 4
+		}
+		if symbol.FileName == file.FileName || included[symbol.FileName] {
+			rank += 2
+		}
+
+		if rank > bestRank {
+			best, bestRank = symbol, rank
+		}
+	}
+
+	return best
+}
+
+// qualifierBefore returns the scope written before the identifier at index, like "ns::Class" for "ns::Class::run",
+// and whether the identifier is accessed as a member with "." or "->".
+func qualifierBefore(tokens []TokenModel, index int) (qualifier string, member bool) {
+	var scopes []string
+
+	for {
+		operator := previousSignificant(tokens, index)
+		if operator < 0 {
+			break
+		}
+
+		switch tokens[operator].Text {
+		case ".", "->":
+			if len(scopes) == 0 {
+				return "", true
+			}
+		case "::":
+			scope := previousSignificant(tokens, operator)
+			if scope >= 0 && tokens[scope].Kind == TokenIdentifier {
+				scopes = append([]string{tokens[scope].Text}, scopes...)
+				index = scope
+				continue
+			}
+		}
+		break
+	}
+
+	return strings.Join(scopes, "::"), false
+}
+
+// callQualifier returns the type a function named name is called on at line, from the parsed calls of file.
+func callQualifier(file FileModel, name string, line int) string {
+	for _, function := range fileFunctions(file) {
+		if line < function.StartLine || line > function.EndLine {
+			continue
+		}
+
+		for _, call := range function.FunctionBody.Calls {
+			if call.FunctionName() != name {
+				continue
+			}
+
+			if typeName := call.ScopeType(); len(typeName) > 0 {
+				return typeName
+			}
+		}
+	}
+
+	return ""
+}
+
+// fileFunctions lists the functions of file, including those in namespaces and classes.
+func fileFunctions(file FileModel) (functions []FunctionModel) {
+	var classFunctions func(classes []ClassModel)
+	classFunctions = func(classes []ClassModel) {
+		for _, class := range classes {
+			for _, accessSpecifier := range class.AccessSpecifierModels {
+				functions = append(functions, accessSpecifier.Functions...)
+				classFunctions(accessSpecifier.Classes)
+			}
+		}
+	}
+
+	var namespaceFunctions func(namespaces []NamespaceModel)
+	namespaceFunctions = func(namespaces []NamespaceModel) {
+		for _, namespace := range namespaces {
+			functions = append(functions, namespace.Functions...)
+			classFunctions(namespace.Classes)
+			namespaceFunctions(namespace.Namespaces)
+		}
+	}
+
+	functions = append(functions, file.Functions...)
+	classFunctions(file.Classes)
+	namespaceFunctions(file.Namespaces)
+
+	return functions
+}
+
+// qualifiedBy checks if qualifiedName is a name declared in scope qualifier, e.g. "gfx::Mesh::draw" in "Mesh".
+func qualifiedBy(qualifiedName string, qualifier string) bool {
+	scope := qualifiedName
+	if index := strings.LastIndex(scope, "::"); index >= 0 {
+		scope = scope[:index]
+	} else {
+		return false
+	}
+
+	return scope == qualifier || strings.HasSuffix(scope, "::"+qualifier)
+}
+
+// sameSymbol checks if a and b are the same declaration.
+func sameSymbol(a SymbolModel, b SymbolModel) bool {
+	return a.Kind == b.Kind && a.QualifiedName == b.QualifiedName && a.FileName == b.FileName && a.StartLine == b.StartLine
+}
+
+// previousSignificant returns the index of the closest token before index that is not whitespace or a comment, -1 if none.
+func previousSignificant(tokens []TokenModel, index int) int {
+	for index--; index >= 0; index-- {
+		if tokens[index].Kind != TokenWhitespace && tokens[index].Kind != TokenComment {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// nextSignificant returns the text of the closest token after index that is not whitespace or a comment.
+func nextSignificant(tokens []TokenModel, index int) string {
+	for index++; index < len(tokens); index++ {
+		if tokens[index].Kind != TokenWhitespace && tokens[index].Kind != TokenComment {
+			return tokens[index].Text
+		}
+	}
+
+	return ""
+}
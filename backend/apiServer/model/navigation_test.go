@@ -0,0 +1,138 @@
+package model
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// newTestNavigator sets up a repository with a class in one file used from another.
+func newTestNavigator(t *testing.T) (navigator, func()) {
+	repoID := "5c62d1904122c760dafe9341"
+	root := setupWalkerRepo(t, map[string]string{
+		repoID + "/Component.cpp": "std::string Component::getName(bool removeDigits)\n{\n  return name;\n}\n",
+		repoID + "/main.cpp":      "int main()\n{\n  Component component;\n  int count = 1;\n  std::cout << component.getName(count);\n  return count;\n}\n",
+	})
+
+	project := ProjectModel{Files: []FileModel{
+		{
+			Parsed:   true,
+			FileName: repoID + "/Component.cpp",
+			Functions: []FunctionModel{{
+				Name:       "std::string Component::getName(bool removeDigits)",
+				Parameters: []ParameterModel{{Name: "removeDigits", Type: "bool"}},
+				StartLine:  1,
+				EndLine:    4,
+			}},
+		},
+		{
+			Parsed:   true,
+			FileName: repoID + "/main.cpp",
+			Functions: []FunctionModel{{
+				Name: "int main()",
+				FunctionBody: FunctionBodyModel{
+					Calls:     []CallModel{{Identifier: "getName", Scope: []ScopeModel{{Identifier: "component", Type: "Component"}}}},
+					Variables: []VariableModel{{Name: "component", Type: "Component"}, {Name: "count", Type: "int"}},
+				},
+				StartLine: 1,
+				EndLine:   7,
+			}},
+		},
+	}}
+
+	cleanup := func() {
+		os.RemoveAll(root)
+	}
+
+	return newNavigator(root, repoID, project, defaultRepoConfig), cleanup
+}
+
+func TestNavigator_definitionAt(t *testing.T) {
+	nav, cleanup := newTestNavigator(t)
+	defer cleanup()
+
+	tests := []struct {
+		name     string
+		filePath string
+		line     int
+		column   int
+		want     SymbolModel
+		wantErr  error
+	}{
+		{
+			name:     "Valid_member_call_in_other_file",
+			filePath: nav.repoID + "/main.cpp",
+			line:     5,
+			column:   28,
+			want:     SymbolModel{Kind: SymbolFunction, Name: "getName", QualifiedName: "Component::getName", FileName: nav.repoID + "/Component.cpp", StartLine: 1, EndLine: 4},
+		},
+		{
+			name:     "Valid_local_variable",
+			filePath: nav.repoID + "/main.cpp",
+			line:     6,
+			column:   10,
+			want:     SymbolModel{Kind: SymbolVariable, Name: "count", QualifiedName: "main::count", FileName: nav.repoID + "/main.cpp", StartLine: 1, EndLine: 7, Local: true},
+		},
+		{
+			name:     "Valid_qualified_definition",
+			filePath: nav.repoID + "/Component.cpp",
+			line:     1,
+			column:   24,
+			want:     SymbolModel{Kind: SymbolFunction, Name: "getName", QualifiedName: "Component::getName", FileName: nav.repoID + "/Component.cpp", StartLine: 1, EndLine: 4},
+		},
+		{
+			name:     "Invalid_keyword",
+			filePath: nav.repoID + "/main.cpp",
+			line:     6,
+			column:   3,
+			wantErr:  ErrNoSymbol,
+		},
+		{
+			name:     "Invalid_unparsed_file",
+			filePath: nav.repoID + "/missing.cpp",
+			line:     1,
+			column:   1,
+			wantErr:  ErrFileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nav.definitionAt(tt.filePath, tt.line, tt.column)
+			if err != tt.wantErr {
+				t.Fatalf("definitionAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("definitionAt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNavigator_references(t *testing.T) {
+	nav, cleanup := newTestNavigator(t)
+	defer cleanup()
+
+	symbol, err := nav.definitionAt(nav.repoID+"/Component.cpp", 1, 24)
+	if err != nil {
+		t.Fatalf("definitionAt() error = %v", err)
+	}
+
+	want := []ReferenceModel{
+		{Kind: ReferenceDefinition, FileName: nav.repoID + "/Component.cpp", Line: 1, Column: 24, Snippet: "std::string Component::getName(bool removeDigits)"},
+		{Kind: ReferenceCall, FileName: nav.repoID + "/main.cpp", Line: 5, Column: 26, Snippet: "std::cout << component.getName(count);"},
+	}
+
+	if got := nav.references(symbol); !reflect.DeepEqual(got, want) {
+		t.Errorf("references() = %+v, want %+v", got, want)
+	}
+
+	local, err := nav.definitionAt(nav.repoID+"/main.cpp", 4, 7)
+	if err != nil {
+		t.Fatalf("definitionAt() error = %v", err)
+	}
+
+	if got := nav.references(local); len(got) != 3 {
+		t.Errorf("references() of local = %+v, want 3 references", got)
+	}
+}
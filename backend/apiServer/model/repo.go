@@ -4,26 +4,27 @@ package model
 
 import (
 	"encoding/json"
+	"errors"
+	"os"
 	"os/exec"
 	"path"
-	"strconv"
-	"strings"
+	"path/filepath"
+	"time"
 
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
 	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
 	"gopkg.in/mgo.v2/bson"
 )
 
-// RepoPath is the path where git repositories are stored.
-var RepoPath string
-
-// JavaParserPath is the path where is java parser is stored.
-var JavaParserPath string
-
 // RepoModel represents metadata for a git repository.
 type RepoModel struct {
-	URI        string        `json:"uri"`                     // Where the repository was found
-	ID         bson.ObjectId `json:"id" bson:"_id,omitempty"` // Folder name where repo is stored
-	ParsedRepo ProjectModel  `json:"parsedrepo,omitempty"`    // Parsed repository in json format
+	URI        string           `json:"uri"`                                        // Where the repository was found
+	ID         bson.ObjectId    `json:"id" bson:"_id,omitempty"`                    // Folder name where repo is stored
+	ParsedRepo ProjectModel     `json:"parsedrepo,omitempty"`                       // Parsed repository in json format
+	Config     *RepoConfig      `json:"config,omitempty" bson:"config,omitempty"`   // Analysis configuration set through the api
+	Owner      bson.ObjectId    `json:"owner,omitempty" bson:"owner,omitempty"`     // User who added the repository
+	Members    []MemberModel    `json:"members,omitempty" bson:"members,omitempty"` // Other users with a role in the repository
+	Checkpoint *ParseCheckpoint `json:"-" bson:"checkpoint,omitempty"`              // Progress of an interrupted parse
 }
 
 // SaveResponse is used by save function to update channel used by go routine to indicate
@@ -47,7 +48,8 @@ type ParseResponse struct {
 }
 
 // Save is expected to run as a go rutine writing to a c.
-func (repo RepoModel) Save(c chan SaveResponse) {
+// The repository is cloned into the repository folder of storage.
+func (repo RepoModel) Save(storage config.Storage, c chan SaveResponse) {
 	util.TypeLogger.Debug("%s: Call to Save", packageName)
 	defer util.TypeLogger.Debug("%s: Ended call to Save", packageName)
 
@@ -63,7 +65,7 @@ func (repo RepoModel) Save(c chan SaveResponse) {
 	c <- SaveResponse{ID: repo.ID.Hex(), StatusText: "Cloning", Err: nil}
 
 	// Clone repository into storage location with name given by database
-	cmd := exec.Command("git", "-C", RepoPath, "clone", repo.URI, repo.ID.Hex())
+	cmd := exec.Command("git", "-C", storage.RepoPath, "clone", repo.URI, repo.ID.Hex())
 	_, err = cmd.Output() // TODO: Validate that git clone went well and prevent request for rsa password
 
 	c <- SaveResponse{ID: repo.ID.Hex(), StatusText: "Done", Err: err}
@@ -71,35 +73,24 @@ func (repo RepoModel) Save(c chan SaveResponse) {
 	return
 }
 
-// Load loads java application to parse a specified file.
-func (repo RepoModel) Load(file string, target string) (data FileModel, err error) {
+// Load loads the java parser in the folder parserPath to parse a specified file.
+func (repo RepoModel) Load(parserPath string, file string, target string) (data FileModel, err error) {
 	util.TypeLogger.Debug("%s: Call to Load", packageName)
 	defer util.TypeLogger.Debug("%s: Ended call to Load", packageName)
 
 	data.Parsed = false
 	data.FileName = file
 
-	// Ready  word count command and execute it.
-	cmd := exec.Command("wc", "-l", file)
-	output, err := cmd.CombinedOutput()
+	linesOfCode, err := CountLines(file)
 
 	if err != nil {
 		util.TypeLogger.Error("%s: Failed to count lines in file: %s", packageName, err.Error())
 		return data, err
 	}
 
-	// Split the output on space and grab the first entry (lines in file)
-	splitWCOutput := strings.Split(string(output), " ")
-	linesOfCode, err := strconv.Atoi(splitWCOutput[0])
-
-	if err != nil {
-		util.TypeLogger.Error("%s: Failed to convert string to int: %s", packageName, err.Error())
-		return data, err
-	}
-
 	// Setup the command to parse the file.
-	cmd = exec.Command("java", "me.codvis.ast.Main", "-f", file, "-t", target, "-c", "Initial")
-	cmd.Dir = JavaParserPath
+	cmd := exec.Command("java", "me.codvis.ast.Main", "-f", file, "-t", target, "-c", "Initial")
+	cmd.Dir = parserPath
 	stdout, err := cmd.StdoutPipe()
 
 	if err != nil {
@@ -112,26 +103,34 @@ func (repo RepoModel) Load(file string, target string) (data FileModel, err erro
 		return data, err
 	}
 
-	fileWrapper := FileWrapperModel{File: data}
+	var output json.RawMessage
 
-	if err := json.NewDecoder(stdout).Decode(&fileWrapper); err != nil {
+	if err := json.NewDecoder(stdout).Decode(&output); err != nil {
 		util.TypeLogger.Error("%s: Failed to decode json: %s", packageName, err.Error())
-		return fileWrapper.File, err
+		cmd.Wait()
+		return data, err
 	}
 
 	if err := cmd.Wait(); err != nil {
 		util.TypeLogger.Error("%s: Failed to exit the command for java parser : %s", packageName, err.Error())
-		return fileWrapper.File, err
+		return data, err
 	}
 
-	fileWrapper.File.LinesInFile = linesOfCode
-	fileWrapper.File.Parsed = true
+	parsed, err := DecodeParseResult(output)
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to validate json from java parser: %s", packageName, err.Error())
+		return data, err
+	}
+
+	parsed.LinesInFile = linesOfCode
+	parsed.Parsed = true
 
-	return fileWrapper.File, nil
+	return parsed, nil
 }
 
 // GetRepoByID finds repo in database and returns.
-func (repo RepoModel) GetRepoByID(id string) (rep RepoModel, err error) {
+// Projects stored with an older schema are migrated, storage tells where they were cloned to.
+func (repo RepoModel) GetRepoByID(storage config.Storage, id string) (rep RepoModel, err error) {
 	util.TypeLogger.Debug("%s: Call to GetRepoID", packageName)
 	defer util.TypeLogger.Debug("%s: Ended call to GetRepoID", packageName)
 
@@ -142,25 +141,82 @@ func (repo RepoModel) GetRepoByID(id string) (rep RepoModel, err error) {
 		return RepoModel{}, err
 	}
 
+	// Upgrade projects stored with an older schema and store the result.
+	migrated, err := MigrateProject(&exstRepo.ParsedRepo, storage.RepoPath)
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to migrate stored project: %s", packageName, err.Error())
+		return RepoModel{}, err
+	}
+	if migrated && exstRepo.ID.Valid() {
+		exstRepo.UpdateRepo()
+	}
+
 	return exstRepo, nil
 }
 
 // GetRepoFiles finds and returns all files stored in repository directory.
-// Excludes directories them selfs (as files) and anything from ".git" folder
-func (repo RepoModel) GetRepoFiles() (files string, err error) {
+// Excludes anything from ".git" folder, files ignored by ".gitignore",
+// files filtered by the repository configuration and files filtered by filter.
+func (repo RepoModel) GetRepoFiles(storage config.Storage, filter FileFilter) (files []string, err error) {
 	util.TypeLogger.Debug("%s: Call to  GetRepoFiles", packageName)
 	defer util.TypeLogger.Debug("%s: Ended call to  GetRepoFiles", packageName)
 
-	cmd := exec.Command("find", RepoPath+"/"+repo.ID.Hex(), "-type", "f", "-not", "-path", "*/.git/*")
-	cmd.Dir = JavaParserPath
-	bytes, err := cmd.CombinedOutput()
+	config, err := repo.GetConfig(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err = CollectFiles(repo.Root(storage), FileFilter{Include: config.Include, Exclude: config.Exclude}, filter)
+	if err != nil {
+		util.TypeLogger.Error("%s: Failed to collect repository files: %s", packageName, err.Error())
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Root returns the directory the repository is cloned to in storage.
+func (repo RepoModel) Root(storage config.Storage) string {
+	return filepath.Join(storage.RepoPath, repo.ID.Hex())
+}
+
+// GetConfig returns the analysis configuration of the repository.
+// The configuration set through the api overrides RepoConfigFile which overrides the server defaults.
+func (repo RepoModel) GetConfig(storage config.Storage) (config RepoConfig, err error) {
+	util.TypeLogger.Debug("%s: Call to GetConfig", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to GetConfig", packageName)
 
+	config, err = LoadRepoConfig(repo.Root(storage))
 	if err != nil {
-		util.TypeLogger.Error("%s: Error executing find %s", packageName, err.Error())
-		return "", err
+		util.TypeLogger.Error("%s: Failed to load repository configuration: %s", packageName, err.Error())
+		return RepoConfig{}, err
+	}
+
+	if repo.Config != nil {
+		config = config.Merge(*repo.Config)
+	}
+
+	return config.WithDefaults(), nil
+}
+
+// UpdateConfig stores the api configuration of the repo in db.
+func (repo RepoModel) UpdateConfig() error {
+	util.TypeLogger.Debug("%s: Call to UpdateConfig", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to UpdateConfig", packageName)
+
+	if repo.Config != nil {
+		if err := repo.Config.Validate(); err != nil {
+			util.TypeLogger.Warn("%s: Received invalid configuration: %s", packageName, err.Error())
+			return err
+		}
+	}
+
+	if err := DB.UpdateConfig(&repo); err != nil {
+		util.TypeLogger.Error("%s: Failed to update configuration in database: %s", packageName, err.Error())
+		return err
 	}
 
-	return string(bytes), nil
+	return nil
 }
 
 // UpdateRepo updates the repo model with repo in db.
@@ -178,49 +234,138 @@ func (repo RepoModel) UpdateRepo() error {
 	return nil
 }
 
-// SanitizeFilePaths removes the repopath from the filepaths.
-func (repo RepoModel) SanitizeFilePaths(projectModel ProjectModel) {
+// SanitizeFilePaths removes the repopath of storage from the filepaths.
+func (repo RepoModel) SanitizeFilePaths(storage config.Storage, projectModel ProjectModel) {
 	util.TypeLogger.Debug("%s: Call to SanitizeFilePaths", packageName)
 	defer util.TypeLogger.Debug("%s: Ended call to SanitizeFilePaths", packageName)
 
+	RelativeFileNames(projectModel, storage.RepoPath)
+}
+
+// RelativeFileNames makes the file names of projectModel, and the files they include, relative to base.
+func RelativeFileNames(projectModel ProjectModel, base string) {
 	for index, file := range projectModel.Files {
-		projectModel.Files[index].FileName = strings.Replace(file.FileName, RepoPath+"/", "", -1)
+		projectModel.Files[index].FileName = relativePath(base, file.FileName)
+		for includeIndex, include := range file.IncludedFiles {
+			projectModel.Files[index].IncludedFiles[includeIndex] = relativePath(base, include)
+		}
 	}
 }
 
+// relativePath makes filename relative to base with forward slashes, keeping filename if it is not below base.
+func relativePath(base string, filename string) string {
+	rel, err := filepath.Rel(filepath.Clean(base), filename)
+	if err != nil {
+		return filename
+	}
+
+	return filepath.ToSlash(rel)
+}
+
 // ParseDataFromFiles fetch all functions from gives files set.
-func (repo RepoModel) ParseDataFromFiles(files string, responsePerNFiles int, c chan ParseResponse) {
+// Files in the checkpoint of an interrupted parse are taken from it instead of parsed again.
+// If parsing is stopped by StopParsing, the progress is stored as checkpoint and ErrInterrupted sent.
+func (repo RepoModel) ParseDataFromFiles(storage config.Storage, filesList []string, responsePerNFiles int, c chan ParseResponse) {
 	util.TypeLogger.Debug("%s: Call to  ParseDataFromFiles", packageName)
 	defer util.TypeLogger.Debug("%s: Ended call to  ParseDataFromFiles", packageName)
 
-	response := ParseResponse{StatusText: "Parsing"}
-	var projectModel ProjectModel
+	if !beginParse() {
+		c <- ParseResponse{StatusText: "Parsing", Err: ErrInterrupted}
+		return
+	}
+	defer runningParses.Done()
 
-	filesList := strings.Split(strings.TrimSuffix(files, "\n"), "\n")
+	config, err := repo.GetConfig(storage)
+	if err != nil {
+		c <- ParseResponse{StatusText: "Parsing", Err: err}
+		return
+	}
+
+	projectModel, response, interrupted := parseFiles(storage.JavaParserPath, filesList, config, repo.Checkpoint.parsedFiles(), stopParses, func(response ParseResponse) {
+		if (response.ParsedFileCount+response.SkippedFileCount-1)%responsePerNFiles == 0 {
+			c <- response
+		}
+	})
+
+	if interrupted {
+		util.TypeLogger.Info("%s: Checkpointing parse of %s after %d of %d files", packageName, repo.ID.Hex(), len(projectModel.Files), len(filesList))
+		repo.Checkpoint = &ParseCheckpoint{Time: time.Now().UTC(), Files: projectModel.Files}
+		repo.UpdateCheckpoint()
+
+		response.Err = ErrInterrupted
+		c <- response
+		return
+	}
+
+	repo.ResolveIncludes(storage, projectModel, config)
+
+	repo.SanitizeFilePaths(storage, projectModel)
+
+	// Mark unreachable functions for the visualization.
+	FindDeadCode(projectModel, config, storage.RepoPath)
+
+	repo.ParsedRepo = projectModel
+	repo.UpdateRepo()
+	InvalidateSearchIndex(repo.ID.Hex())
+
+	if repo.Checkpoint != nil {
+		repo.Checkpoint = nil
+		repo.UpdateCheckpoint()
+	}
+
+	response.StatusText = "Done"
+	response.Result = projectModel
+
+	c <- response
+
+	return
+}
+
+// ParseFiles parses every file of filesList in a language mapped by config with the java parser in parserPath.
+// Other files, and files that fail to parse, are added without being parsed. progress is called
+// after each file with the counts so far. File names are kept as given.
+func ParseFiles(parserPath string, filesList []string, config RepoConfig, progress func(response ParseResponse)) (ProjectModel, ParseResponse) {
+	util.TypeLogger.Debug("%s: Call to ParseFiles", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to ParseFiles", packageName)
+
+	projectModel, response, _ := parseFiles(parserPath, filesList, config, nil, nil, progress)
+
+	return projectModel, response
+}
+
+// parseFiles works like ParseFiles, taking the files in parsed instead of parsing them again.
+// Once stop is closed it returns after the current file, with interrupted set and the files so far.
+func parseFiles(parserPath string, filesList []string, config RepoConfig, parsed map[string]FileModel, stop <-chan struct{}, progress func(response ParseResponse)) (projectModel ProjectModel, response ParseResponse, interrupted bool) {
+	response = ParseResponse{StatusText: "Parsing"}
+	projectModel = ProjectModel{SchemaVersion: SchemaVersion}
 
 	response.FileCount = len(filesList)
 
-	for n, sourceFile := range filesList {
-		// Search for cpp files
+	for _, sourceFile := range filesList {
+		select {
+		case <-stop:
+			return projectModel, response, true
+		default:
+		}
+
 		var err error
 		var data FileModel
 
 		response.CurrentFile = path.Base(sourceFile)
 
-		switch fileExtention := path.Ext(sourceFile); fileExtention {
-		case ".cpp":
-			data, err = repo.Load(sourceFile, "cpp") // Fetch function names from the file.
+		// Take files from the checkpoint, parse files in languages mapped by the configuration and skip the rest.
+		// Checkpointed files are counted like a fresh parse counts them, which counts failed parses as parsed.
+		if file, ok := parsed[sourceFile]; ok {
+			data = file
+			if _, mapped := config.LanguageOf(sourceFile); file.Parsed || mapped {
+				response.ParsedFileCount++
+			} else {
+				response.SkippedFileCount++
+			}
+		} else if language, ok := config.LanguageOf(sourceFile); ok {
+			data, err = RepoModel{}.Load(parserPath, sourceFile, language)
 			response.ParsedFileCount++
-
-		case ".hpp":
-			data, err = repo.Load(sourceFile, "cpp")
-			response.ParsedFileCount++
-
-		case ".java":
-			data, err = repo.Load(sourceFile, "java")
-			response.ParsedFileCount++
-
-		default:
+		} else {
 			data = FileModel{Parsed: false, FileName: sourceFile}
 			response.SkippedFileCount++
 		}
@@ -231,23 +376,128 @@ func (repo RepoModel) ParseDataFromFiles(files string, responsePerNFiles int, c
 
 		}
 		projectModel.Files = append(projectModel.Files, data)
-		if n % responsePerNFiles == 0 {
-			c <- response
+		progress(response)
+	}
+
+	return projectModel, response, false
+}
+
+// ResolveIncludes links parsed C++ files to the repository files they include.
+// Include directives are read from the file when the parser did not report any.
+func (repo RepoModel) ResolveIncludes(storage config.Storage, projectModel ProjectModel, config RepoConfig) {
+	util.TypeLogger.Debug("%s: Call to ResolveIncludes", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to ResolveIncludes", packageName)
+
+	ResolveProjectIncludes(repo.Root(storage), projectModel, config)
+}
+
+// ResolveProjectIncludes links parsed C++ files of a checkout in root to the files they include.
+// File names must not have been made relative yet.
+func ResolveProjectIncludes(root string, projectModel ProjectModel, config RepoConfig) {
+	files := make(map[string]bool)
+	for _, file := range projectModel.Files {
+		files[file.FileName] = true
+	}
+
+	for index, file := range projectModel.Files {
+		if language, ok := config.LanguageOf(file.FileName); !ok || language != LanguageCpp {
+			continue
+		}
+
+		if len(file.Includes) == 0 {
+			includes, err := scanIncludes(file.FileName)
+			if err != nil {
+				util.TypeLogger.Warn("%s: Failed to scan includes: %s", packageName, err.Error())
+				continue
+			}
+			projectModel.Files[index].Includes = includes
 		}
 
+		projectModel.Files[index].IncludedFiles = resolveIncludes(
+			root,
+			file.FileName,
+			projectModel.Files[index].Includes,
+			config.IncludeRoots,
+			files,
+		)
 	}
+}
 
-	repo.SanitizeFilePaths(projectModel)
+// FetchAllForUser fetches the repositories userID owns or is a member of.
+func (repo RepoModel) FetchAllForUser(userID bson.ObjectId) (repoModels []bson.M, err error) {
+	util.TypeLogger.Debug("%s: Call to FetchAllForUser", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to FetchAllForUser", packageName)
 
-	repo.ParsedRepo = projectModel
-	repo.UpdateRepo()
+	reposModels, err := DB.FindAllURIForUser(userID)
 
-	response.StatusText = "Done"
-	response.Result = projectModel
+	if err != nil {
+		util.TypeLogger.Warn("%s: Failed to find repository", packageName)
+		return []bson.M{}, err
+	}
 
-	c <- response
+	return reposModels, nil
+}
 
-	return
+// HasMember checks if userID owns the repository or is one of its members.
+func (repo RepoModel) HasMember(userID bson.ObjectId) bool {
+	_, ok := repo.RoleOf(userID)
+	return ok
+}
+
+// ClaimUnowned makes owner the owner of the repository with the uri of repo added before there were users.
+// It returns an empty repository if there is none.
+func (repo RepoModel) ClaimUnowned(owner bson.ObjectId) (RepoModel, error) {
+	util.TypeLogger.Debug("%s: Call to ClaimUnowned", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to ClaimUnowned", packageName)
+
+	exstRepo, err := DB.FindUnownedRepoByURI(repo.URI)
+	if err != nil || !exstRepo.ID.Valid() {
+		return RepoModel{}, err
+	}
+
+	exstRepo.Owner = owner
+	if err := exstRepo.UpdateMembers(); err != nil {
+		return RepoModel{}, err
+	}
+
+	return exstRepo, nil
+}
+
+// UpdateMembers stores the owner and members of the repository.
+func (repo RepoModel) UpdateMembers() error {
+	util.TypeLogger.Debug("%s: Call to UpdateMembers", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to UpdateMembers", packageName)
+
+	if err := DB.UpdateMembers(&repo); err != nil {
+		util.TypeLogger.Error("%s: Failed to update database: %s", packageName, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the repository from db and its clone from storage.
+func (repo RepoModel) Delete(storage config.Storage) error {
+	util.TypeLogger.Debug("%s: Call to Delete", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to Delete", packageName)
+
+	if !repo.ID.Valid() {
+		return errors.New("Invalid id")
+	}
+
+	if err := DB.Remove(&repo); err != nil {
+		util.TypeLogger.Error("%s: Failed to remove from database: %s", packageName, err.Error())
+		return err
+	}
+
+	InvalidateSearchIndex(repo.ID.Hex())
+
+	if err := os.RemoveAll(repo.Root(storage)); err != nil {
+		util.TypeLogger.Error("%s: Failed to remove clone of repository: %s", packageName, err.Error())
+		return err
+	}
+
+	return nil
 }
 
 // FetchAll fetches all the repositories.
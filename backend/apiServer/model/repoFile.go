@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Errors returned when resolving a file path requested by a client.
+var (
+	ErrForbiddenPath = errors.New("Path is outside of repository")
+	ErrFileNotFound  = errors.New("File not found")
+)
+
+// ResolveRepoFile resolves filePath, given relative to repoPath as in parsed file names,
+// to a regular file inside the clone of repository repoID in repoPath.
+// Paths escaping the clone, also through symbolic links, or pointing into ".git" give ErrForbiddenPath.
+// Paths that do not exist or are not regular files give ErrFileNotFound.
+func ResolveRepoFile(repoPath string, repoID string, filePath string) (string, error) {
+	util.TypeLogger.Debug("%s: Call to ResolveRepoFile", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to ResolveRepoFile", packageName)
+
+	if len(repoID) == 0 || strings.ContainsAny(repoID, `/\.`) || strings.ContainsRune(filePath, 0) || filepath.IsAbs(filePath) {
+		return "", ErrForbiddenPath
+	}
+
+	repoRoot := filepath.Join(repoPath, repoID)
+	candidate := filepath.Join(repoPath, filepath.FromSlash(filePath))
+
+	if !isInside(repoRoot, candidate) {
+		util.TypeLogger.Warn("%s: Rejected path outside of repository %s: %s", packageName, repoID, filePath)
+		return "", ErrForbiddenPath
+	}
+
+	realRoot, err := filepath.EvalSymlinks(repoRoot)
+	if err != nil {
+		return "", ErrFileNotFound
+	}
+
+	realCandidate, err := filepath.EvalSymlinks(candidate)
+	if os.IsNotExist(err) {
+		return "", ErrFileNotFound
+	}
+	if err != nil {
+		util.TypeLogger.Warn("%s: Failed to resolve path %s: %s", packageName, filePath, err.Error())
+		return "", ErrForbiddenPath
+	}
+
+	if !isInside(realRoot, realCandidate) {
+		util.TypeLogger.Warn("%s: Rejected symbolic link leaving repository %s: %s", packageName, repoID, filePath)
+		return "", ErrForbiddenPath
+	}
+
+	rel, _ := filepath.Rel(realRoot, realCandidate)
+	for _, element := range strings.Split(filepath.ToSlash(rel), "/") {
+		if element == ".git" {
+			return "", ErrForbiddenPath
+		}
+	}
+
+	info, err := os.Stat(realCandidate)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", ErrFileNotFound
+	}
+
+	return realCandidate, nil
+}
+
+// isInside checks if the cleaned path target is strictly below dir.
+func isInside(dir string, target string) bool {
+	dir = filepath.Clean(dir)
+	return strings.HasPrefix(filepath.Clean(target), dir+string(filepath.Separator))
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveRepoFile(t *testing.T) {
+	root := setupWalkerRepo(t, map[string]string{
+		"repoA/src/main.cpp": "int main() {}\n",
+		"repoA/.git/config":  "[remote]\n",
+		"repoB/secret.cpp":   "secret\n",
+		"outside.txt":        "outside\n",
+	})
+	defer os.RemoveAll(root)
+
+	os.Symlink(filepath.Join(root, "outside.txt"), filepath.Join(root, "repoA", "escape.txt"))
+	os.Symlink(filepath.Join(root, "repoB"), filepath.Join(root, "repoA", "linkdir"))
+	os.Symlink(filepath.Join(root, "repoA", "src", "main.cpp"), filepath.Join(root, "repoA", "inside.cpp"))
+
+	tests := []struct {
+		name     string
+		repoID   string
+		filePath string
+		want     string
+		wantErr  error
+	}{
+		{name: "Valid_file", repoID: "repoA", filePath: "repoA/src/main.cpp", want: "repoA/src/main.cpp"},
+		{name: "Valid_dot_segments_inside", repoID: "repoA", filePath: "repoA/src/../src/./main.cpp", want: "repoA/src/main.cpp"},
+		{name: "Valid_link_inside", repoID: "repoA", filePath: "repoA/inside.cpp", want: "repoA/src/main.cpp"},
+		{name: "Invalid_parent_traversal", repoID: "repoA", filePath: "repoA/../../etc/passwd", wantErr: ErrForbiddenPath},
+		{name: "Invalid_other_repository", repoID: "repoA", filePath: "repoB/secret.cpp", wantErr: ErrForbiddenPath},
+		{name: "Invalid_other_repository_traversal", repoID: "repoA", filePath: "repoA/../repoB/secret.cpp", wantErr: ErrForbiddenPath},
+		{name: "Invalid_absolute", repoID: "repoA", filePath: "/etc/passwd", wantErr: ErrForbiddenPath},
+		{name: "Invalid_repository_root", repoID: "repoA", filePath: "repoA", wantErr: ErrForbiddenPath},
+		{name: "Invalid_traversing_repository_id", repoID: "..", filePath: "outside.txt", wantErr: ErrForbiddenPath},
+		{name: "Invalid_link_to_file_outside", repoID: "repoA", filePath: "repoA/escape.txt", wantErr: ErrForbiddenPath},
+		{name: "Invalid_link_to_directory_outside", repoID: "repoA", filePath: "repoA/linkdir/secret.cpp", wantErr: ErrForbiddenPath},
+		{name: "Invalid_git_directory", repoID: "repoA", filePath: "repoA/.git/config", wantErr: ErrForbiddenPath},
+		{name: "Invalid_missing_file", repoID: "repoA", filePath: "repoA/missing.cpp", wantErr: ErrFileNotFound},
+		{name: "Invalid_directory", repoID: "repoA", filePath: "repoA/src", wantErr: ErrFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveRepoFile(root, tt.repoID, tt.filePath)
+			if err != tt.wantErr {
+				t.Fatalf("ResolveRepoFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil {
+				realRoot, _ := filepath.EvalSymlinks(root)
+				if want := filepath.Join(realRoot, filepath.FromSlash(tt.want)); got != want {
+					t.Errorf("ResolveRepoFile() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
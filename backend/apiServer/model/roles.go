@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Roles of users in a repository, each allowing what the roles before it allow.
+const (
+	RoleViewer  = "viewer"  // Reads snippets, parsed data and analysis results
+	RoleAnalyst = "analyst" // Also re-parses and exports the repository
+	RoleAdmin   = "admin"   // Also deletes the repository and manages its members and configuration
+)
+
+// roleRanks orders the roles by what they allow.
+var roleRanks = map[string]int{
+	RoleViewer:  1,
+	RoleAnalyst: 2,
+	RoleAdmin:   3,
+}
+
+// ErrInvalidRole is returned for unknown roles.
+var ErrInvalidRole = errors.New("Role must be viewer, analyst or admin")
+
+// MemberModel is a user with a role in a repository.
+type MemberModel struct {
+	UserID bson.ObjectId `json:"-" bson:"user_id"`
+	Role   string        `json:"role" bson:"role"`
+}
+
+// ValidRole checks that role is one of the roles.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows tells if a user with role may do what needs required.
+func RoleAllows(role string, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// RoleOf returns the role of userID in the repository. The owner is always admin.
+func (repo RepoModel) RoleOf(userID bson.ObjectId) (role string, ok bool) {
+	if !userID.Valid() {
+		return "", false
+	}
+	if repo.Owner == userID {
+		return RoleAdmin, true
+	}
+
+	for _, member := range repo.Members {
+		if member.UserID == userID {
+			return member.Role, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestRoleOf(t *testing.T) {
+	owner, analyst, stranger := bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId()
+	repo := RepoModel{Owner: owner, Members: []MemberModel{{UserID: analyst, Role: RoleAnalyst}}}
+
+	tests := []struct {
+		name     string
+		userID   bson.ObjectId
+		wantRole string
+		wantOk   bool
+	}{
+		{name: "Owner", userID: owner, wantRole: RoleAdmin, wantOk: true},
+		{name: "Member", userID: analyst, wantRole: RoleAnalyst, wantOk: true},
+		{name: "Stranger", userID: stranger, wantOk: false},
+		{name: "No user", userID: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, ok := repo.RoleOf(tt.userID)
+			if role != tt.wantRole || ok != tt.wantOk {
+				t.Errorf("RoleOf() = %s, %v, want %s, %v", role, ok, tt.wantRole, tt.wantOk)
+			}
+			if repo.HasMember(tt.userID) != tt.wantOk {
+				t.Errorf("HasMember() = %v, want %v", !tt.wantOk, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{role: RoleViewer, required: RoleViewer, want: true},
+		{role: RoleViewer, required: RoleAnalyst, want: false},
+		{role: RoleAnalyst, required: RoleViewer, want: true},
+		{role: RoleAnalyst, required: RoleAdmin, want: false},
+		{role: RoleAdmin, required: RoleAnalyst, want: true},
+		{role: "owner", required: RoleViewer, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"_"+tt.required, func(t *testing.T) {
+			if got := RoleAllows(tt.role, tt.required); got != tt.want {
+				t.Errorf("RoleAllows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package model
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// Severities of rule violations, and SeverityOff to disable a rule.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityOff     = "off"
+)
+
+// Ids of the rules evaluated over parsed repositories.
+const (
+	RuleFunctionLines  = "function-lines"
+	RuleParameters     = "parameters"
+	RulePublicMembers  = "public-members"
+	RuleNamespaceDepth = "namespace-depth"
+)
+
+// severityRanks orders severities, higher is more severe.
+var severityRanks = map[string]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// RuleModel describes a rule and how it is configured for a repository.
+type RuleModel struct {
+	ID          string `json:"id"`          // One of the Rule ids
+	Description string `json:"description"` // What the rule checks
+	Severity    string `json:"severity"`    // Severity of violations, or SeverityOff
+	Threshold   int    `json:"threshold"`   // Limit from MetricThresholds the rule compares against
+}
+
+// ViolationModel represents a place in code breaking a rule.
+type ViolationModel struct {
+	Rule          string `json:"rule"`                 // Id of the broken rule
+	Severity      string `json:"severity"`             // Severity configured for the rule
+	Message       string `json:"message"`              // Human readable description of the violation
+	Kind          string `json:"kind"`                 // Kind of symbol breaking the rule
+	QualifiedName string `json:"qualified_name"`       // Name of the symbol breaking the rule
+	FileName      string `json:"file_name"`            // File the symbol is declared in
+	StartLine     int    `json:"start_line,omitempty"` // First line of the symbol when known
+	EndLine       int    `json:"end_line,omitempty"`   // Last line of the symbol when known
+	Value         int    `json:"value"`                // Measured value
+	Threshold     int    `json:"threshold"`            // Limit the value exceeds
+}
+
+// ViolationReport lists the rule violations of a repository.
+type ViolationReport struct {
+	Rules      []RuleModel      `json:"rules"`      // Rules evaluated and their configuration
+	Counts     map[string]int   `json:"counts"`     // Number of violations per severity
+	Violations []ViolationModel `json:"violations"` // Violations ordered by severity, file and line
+}
+
+// rule checks one metric of a parsed project against a threshold.
+type rule struct {
+	id          string
+	description string
+	threshold   func(thresholds MetricThresholds) int
+}
+
+// rules lists every rule the engine evaluates.
+var rules = []rule{
+	{
+		id:          RuleFunctionLines,
+		description: "Function is longer than the threshold in lines",
+		threshold:   func(thresholds MetricThresholds) int { return thresholds.FunctionLines },
+	},
+	{
+		id:          RuleParameters,
+		description: "Function has more parameters than the threshold",
+		threshold:   func(thresholds MetricThresholds) int { return thresholds.Parameters },
+	},
+	{
+		id:          RulePublicMembers,
+		description: "Class has more public members than the threshold",
+		threshold:   func(thresholds MetricThresholds) int { return thresholds.PublicMembers },
+	},
+	{
+		id:          RuleNamespaceDepth,
+		description: "Namespaces are nested deeper than the threshold",
+		threshold:   func(thresholds MetricThresholds) int { return thresholds.NamespaceDepth },
+	},
+}
+
+// findRule returns the rule with id.
+func findRule(id string) (rule, bool) {
+	for _, candidate := range rules {
+		if candidate.id == id {
+			return candidate, true
+		}
+	}
+
+	return rule{}, false
+}
+
+// SeverityAtLeast checks if severity is as severe as minimum or more.
+func SeverityAtLeast(severity string, minimum string) bool {
+	return severityRanks[severity] >= severityRanks[minimum]
+}
+
+// IsSeverity checks if severity is one of the Severity levels, not counting SeverityOff.
+func IsSeverity(severity string) bool {
+	_, ok := severityRanks[severity]
+	return ok
+}
+
+// CheckRules evaluates the rules of config over project.
+// Rules without a configured severity or with SeverityOff are skipped.
+func CheckRules(project ProjectModel, config RepoConfig) ViolationReport {
+	util.TypeLogger.Debug("%s: Call to CheckRules", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to CheckRules", packageName)
+
+	report := ViolationReport{Counts: make(map[string]int), Violations: []ViolationModel{}}
+
+	checker := ruleChecker{severities: make(map[string]string), thresholds: make(map[string]int)}
+	for _, candidate := range rules {
+		severity, ok := config.Rules[candidate.id]
+		if !ok {
+			severity = SeverityOff
+		}
+
+		report.Rules = append(report.Rules, RuleModel{
+			ID:          candidate.id,
+			Description: candidate.description,
+			Severity:    severity,
+			Threshold:   candidate.threshold(config.Thresholds),
+		})
+
+		if severity != SeverityOff {
+			checker.severities[candidate.id] = severity
+			checker.thresholds[candidate.id] = candidate.threshold(config.Thresholds)
+		}
+	}
+
+	for _, file := range project.Files {
+		checker.fileName = file.FileName
+		checker.functions("", file.Functions)
+		checker.namespaces("", 0, file.Namespaces)
+		checker.classes("", file.Classes)
+	}
+
+	for _, violation := range checker.violations {
+		report.Counts[violation.Severity]++
+	}
+
+	sort.SliceStable(checker.violations, func(i, j int) bool {
+		first, second := checker.violations[i], checker.violations[j]
+		if first.Severity != second.Severity {
+			return severityRanks[first.Severity] > severityRanks[second.Severity]
+		}
+		if first.FileName != second.FileName {
+			return first.FileName < second.FileName
+		}
+		return first.StartLine < second.StartLine
+	})
+
+	if checker.violations != nil {
+		report.Violations = checker.violations
+	}
+
+	return report
+}
+
+// ruleChecker walks a parsed file collecting violations of the enabled rules.
+type ruleChecker struct {
+	severities map[string]string // Severity by rule id of enabled rules
+	thresholds map[string]int    // Threshold by rule id of enabled rules
+	fileName   string
+	violations []ViolationModel
+}
+
+// check adds a violation of rule id when value exceeds its threshold.
+func (checker *ruleChecker) check(id string, value int, violation ViolationModel, unit string) {
+	severity, enabled := checker.severities[id]
+	threshold := checker.thresholds[id]
+	if !enabled || threshold <= 0 || value <= threshold {
+		return
+	}
+
+	violation.Rule = id
+	violation.Severity = severity
+	violation.FileName = checker.fileName
+	violation.Value = value
+	violation.Threshold = threshold
+	violation.Message = violation.QualifiedName + " has " + strconv.Itoa(value) + " " + unit + ", more than " + strconv.Itoa(threshold)
+
+	checker.violations = append(checker.violations, violation)
+}
+
+// functions checks the length and parameters of functions declared in scope.
+func (checker *ruleChecker) functions(scope string, functions []FunctionModel) {
+	for _, function := range functions {
+		qualifiedName := qualify(scope, function.Identifier())
+		if qualifier := function.Qualifier(); len(qualifier) > 0 {
+			qualifiedName = qualify(scope, qualify(qualifier, function.Identifier()))
+		}
+
+		violation := ViolationModel{
+			Kind:          SymbolFunction,
+			QualifiedName: qualifiedName,
+			StartLine:     function.StartLine,
+			EndLine:       function.EndLine,
+		}
+
+		if function.StartLine > 0 && function.EndLine >= function.StartLine {
+			checker.check(RuleFunctionLines, function.EndLine-function.StartLine+1, violation, "lines")
+		}
+		checker.check(RuleParameters, len(function.Parameters), violation, "parameters")
+	}
+}
+
+// namespaces checks the depth of namespaces declared in scope at depth and everything inside them.
+// Only the outermost namespace exceeding the depth is reported, with the deepest nesting below it.
+func (checker *ruleChecker) namespaces(scope string, depth int, namespaces []NamespaceModel) {
+	for _, namespace := range namespaces {
+		qualifiedName := qualify(scope, namespace.NamespaceName)
+		namespaceDepth := depth + namespaceElements(namespace.NamespaceName)
+
+		threshold := checker.thresholds[RuleNamespaceDepth]
+		if depth <= threshold && namespaceDepth > threshold {
+			checker.check(RuleNamespaceDepth, maxNamespaceDepth(namespaceDepth, namespace.Namespaces), ViolationModel{
+				Kind:          SymbolNamespace,
+				QualifiedName: qualifiedName,
+			}, "levels of namespaces")
+		}
+
+		checker.functions(qualifiedName, namespace.Functions)
+		checker.namespaces(qualifiedName, namespaceDepth, namespace.Namespaces)
+		checker.classes(qualifiedName, namespace.Classes)
+	}
+}
+
+// classes checks the public members of classes declared in scope and their members.
+func (checker *ruleChecker) classes(scope string, classes []ClassModel) {
+	for _, class := range classes {
+		qualifiedName := qualify(scope, class.Name)
+
+		publicMembers := 0
+		for _, accessSpecifier := range class.AccessSpecifierModels {
+			if accessSpecifier.Name == "public" {
+				publicMembers += len(accessSpecifier.Functions) + len(accessSpecifier.Variables) + len(accessSpecifier.Classes)
+			}
+		}
+
+		startLine, endLine := class.LineRange()
+		checker.check(RulePublicMembers, publicMembers, ViolationModel{
+			Kind:          SymbolClass,
+			QualifiedName: qualifiedName,
+			StartLine:     startLine,
+			EndLine:       endLine,
+		}, "public members")
+
+		for _, accessSpecifier := range class.AccessSpecifierModels {
+			checker.functions(qualifiedName, accessSpecifier.Functions)
+			checker.classes(qualifiedName, accessSpecifier.Classes)
+		}
+	}
+}
+
+// namespaceElements counts the levels a namespace name declares, like 3 for a Java package "com.example.app".
+// Anonymous namespaces are one level.
+func namespaceElements(name string) int {
+	if elements := len(strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == ':' })); elements > 0 {
+		return elements
+	}
+
+	return 1
+}
+
+// maxNamespaceDepth returns the deepest nesting of namespaces below a namespace at depth.
+func maxNamespaceDepth(depth int, namespaces []NamespaceModel) int {
+	deepest := depth
+	for _, namespace := range namespaces {
+		if nested := maxNamespaceDepth(depth+namespaceElements(namespace.NamespaceName), namespace.Namespaces); nested > deepest {
+			deepest = nested
+		}
+	}
+
+	return deepest
+}
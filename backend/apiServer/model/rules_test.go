@@ -0,0 +1,82 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckRules(t *testing.T) {
+	longFunction := FunctionModel{Name: "void update()", StartLine: 10, EndLine: 80}
+	manyParameters := FunctionModel{
+		Name:       "void configure(int a, int b, int c)",
+		Parameters: []ParameterModel{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+		StartLine:  90,
+		EndLine:    92,
+	}
+
+	project := ProjectModel{Files: []FileModel{{
+		FileName: "repo/world.cpp",
+		Namespaces: []NamespaceModel{{
+			NamespaceName: "game",
+			Namespaces: []NamespaceModel{{
+				NamespaceName: "ecs",
+				Namespaces:    []NamespaceModel{{NamespaceName: "detail"}},
+				Classes: []ClassModel{{
+					Name: "World",
+					AccessSpecifierModels: []AccessSpecifierModel{
+						{Name: "public", Functions: []FunctionModel{longFunction, manyParameters}, Variables: []VariableModel{{Name: "entities"}}},
+						{Name: "private", Variables: []VariableModel{{Name: "systems"}}},
+					},
+				}},
+			}},
+		}},
+	}}}
+
+	config := defaultRepoConfig.Merge(RepoConfig{
+		Thresholds: MetricThresholds{Parameters: 2, PublicMembers: 2, NamespaceDepth: 2},
+		Rules:      map[string]string{RuleParameters: SeverityError},
+	})
+
+	report := CheckRules(project, config)
+
+	got := make(map[string]ViolationModel)
+	for _, violation := range report.Violations {
+		got[violation.Rule] = violation
+	}
+
+	want := map[string]struct {
+		severity      string
+		qualifiedName string
+		value         int
+	}{
+		RuleFunctionLines:  {SeverityWarning, "game::ecs::World::update", 71},
+		RuleParameters:     {SeverityError, "game::ecs::World::configure", 3},
+		RulePublicMembers:  {SeverityInfo, "game::ecs::World", 3},
+		RuleNamespaceDepth: {SeverityWarning, "game::ecs::detail", 3},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("CheckRules() violations = %+v", report.Violations)
+	}
+	for id, expected := range want {
+		violation := got[id]
+		if violation.Severity != expected.severity || violation.QualifiedName != expected.qualifiedName || violation.Value != expected.value {
+			t.Errorf("CheckRules() %s = %+v, want %+v", id, violation, expected)
+		}
+	}
+
+	if report.Violations[0].Rule != RuleParameters {
+		t.Errorf("CheckRules() first violation = %s, want errors first", report.Violations[0].Rule)
+	}
+
+	wantCounts := map[string]int{SeverityError: 1, SeverityWarning: 2, SeverityInfo: 1}
+	if !reflect.DeepEqual(report.Counts, wantCounts) {
+		t.Errorf("CheckRules() counts = %v, want %v", report.Counts, wantCounts)
+	}
+
+	// Disabled rules are listed but not evaluated.
+	config.Rules = map[string]string{RuleParameters: SeverityOff}
+	if report := CheckRules(project, config); len(report.Violations) != 0 || len(report.Rules) != len(rules) {
+		t.Errorf("CheckRules() with rules off = %+v", report)
+	}
+}
@@ -0,0 +1,285 @@
+package model
+
+import (
+	"strings"
+)
+
+// Version and schema of the SARIF logs written.
+const (
+	SarifVersion = "2.1.0"
+	SarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifToolName is the name of the tool in SARIF logs.
+const sarifToolName = "codevis"
+
+// Ids of the SARIF rules for findings other than rule violations.
+const (
+	SarifRuleDeadCode     = "dead-code"
+	SarifRuleDuplicate    = "duplicate-code"
+	SarifRuleIncludeCycle = "include-cycle"
+)
+
+// deadCodeSeverities maps the confidence of dead code to the severity it is reported with.
+var deadCodeSeverities = map[string]string{
+	ConfidenceHigh:   SeverityWarning,
+	ConfidenceMedium: SeverityInfo,
+	ConfidenceLow:    SeverityInfo,
+}
+
+// sarifLevels maps severities to SARIF result levels.
+var sarifLevels = map[string]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "note",
+}
+
+// SarifLog is the root of a SARIF 2.1.0 log.
+type SarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+// SarifRun holds the results of one analysis run.
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+// SarifTool describes the tool that produced a run.
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+// SarifDriver describes the analysis and its rules.
+type SarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []SarifRule `json:"rules"`
+}
+
+// SarifRule describes a rule results refer to.
+type SarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     SarifMessage       `json:"shortDescription"`
+	DefaultConfiguration SarifConfiguration `json:"defaultConfiguration"`
+}
+
+// SarifConfiguration holds the level of a rule.
+type SarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SarifMessage is a plain text message.
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+// SarifResult is one finding.
+type SarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          SarifMessage    `json:"message"`
+	Locations        []SarifLocation `json:"locations"`
+	RelatedLocations []SarifLocation `json:"relatedLocations,omitempty"` // Other places involved, like the copies of duplicated code
+}
+
+// SarifLocation points at a region of a file.
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// SarifPhysicalLocation is a file and an optional region in it.
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	Region           *SarifRegion          `json:"region,omitempty"`
+}
+
+// SarifArtifactLocation is the path of a file relative to the root of the repository.
+type SarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SarifRegion is a range of lines.
+type SarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// SarifBuilder collects findings into a SARIF log.
+type SarifBuilder struct {
+	prefix  string
+	rules   []SarifRule
+	ruleIDs map[string]bool
+	results []SarifResult
+}
+
+// NewSarifBuilder creates a builder for findings in files named with prefix, like "<repoId>/",
+// which is removed so locations are relative to the root of the repository.
+func NewSarifBuilder(prefix string) *SarifBuilder {
+	return &SarifBuilder{prefix: prefix, ruleIDs: make(map[string]bool)}
+}
+
+// AddRule adds a rule results can refer to, once per id.
+func (builder *SarifBuilder) AddRule(id string, description string, severity string) {
+	if builder.ruleIDs[id] {
+		return
+	}
+	builder.ruleIDs[id] = true
+
+	builder.rules = append(builder.rules, SarifRule{
+		ID:                   id,
+		ShortDescription:     SarifMessage{Text: description},
+		DefaultConfiguration: SarifConfiguration{Level: sarifLevel(severity)},
+	})
+}
+
+// AddResult adds a finding of rule id in the lines of fileName, a line of zero means the whole file.
+func (builder *SarifBuilder) AddResult(id string, severity string, message string, fileName string, startLine int, endLine int) {
+	builder.results = append(builder.results, SarifResult{
+		RuleID:    id,
+		Level:     sarifLevel(severity),
+		Message:   SarifMessage{Text: message},
+		Locations: []SarifLocation{builder.location(fileName, startLine, endLine)},
+	})
+}
+
+// AddViolations adds the enabled rules of report and their violations.
+func (builder *SarifBuilder) AddViolations(report ViolationReport) {
+	for _, rule := range report.Rules {
+		if rule.Severity != SeverityOff {
+			builder.AddRule(rule.ID, rule.Description, rule.Severity)
+		}
+	}
+
+	for _, violation := range report.Violations {
+		builder.AddResult(violation.Rule, violation.Severity, violation.Message, violation.FileName, violation.StartLine, violation.EndLine)
+	}
+}
+
+// AddDeadCode adds the unreachable functions and classes of report.
+// Code dead with high confidence is a warning, the rest is informational.
+func (builder *SarifBuilder) AddDeadCode(report DeadCodeReport) {
+	builder.AddRule(SarifRuleDeadCode, "Code is not reachable from any entry point", SeverityWarning)
+
+	for _, dead := range report.Dead {
+		builder.AddResult(
+			SarifRuleDeadCode,
+			deadCodeSeverities[dead.Confidence],
+			dead.QualifiedName+" is never used: "+dead.Reason,
+			dead.FileName,
+			dead.StartLine,
+			dead.EndLine,
+		)
+	}
+}
+
+// AddClones adds a result for each group of duplicated functions of report, located at its first member
+// with the other members as related locations. Near duplicates are informational.
+func (builder *SarifBuilder) AddClones(report CloneReport) {
+	builder.AddRule(SarifRuleDuplicate, "Function duplicates the body of other functions", SeverityWarning)
+
+	for _, group := range report.Groups {
+		if len(group.Members) == 0 {
+			continue
+		}
+
+		severity := SeverityWarning
+		if group.Kind == CloneNear {
+			severity = SeverityInfo
+		}
+
+		first := group.Members[0]
+		names := make([]string, 0, len(group.Members)-1)
+		var related []SarifLocation
+		for _, member := range group.Members[1:] {
+			names = append(names, member.QualifiedName)
+			related = append(related, builder.location(member.FileName, member.StartLine, member.EndLine))
+		}
+
+		builder.results = append(builder.results, SarifResult{
+			RuleID:           SarifRuleDuplicate,
+			Level:            sarifLevel(severity),
+			Message:          SarifMessage{Text: first.QualifiedName + " is a " + group.Kind + " duplicate of " + strings.Join(names, ", ")},
+			Locations:        []SarifLocation{builder.location(first.FileName, first.StartLine, first.EndLine)},
+			RelatedLocations: related,
+		})
+	}
+}
+
+// AddIncludeCycles adds a result for each cycle, located at its first file.
+func (builder *SarifBuilder) AddIncludeCycles(cycles []IncludeCycleModel) {
+	builder.AddRule(SarifRuleIncludeCycle, "Files include each other in a cycle", SeverityWarning)
+
+	for _, cycle := range cycles {
+		if len(cycle.Files) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(cycle.Files)+1)
+		var related []SarifLocation
+		for index, file := range cycle.Files {
+			names = append(names, strings.TrimPrefix(file, builder.prefix))
+			if index > 0 {
+				related = append(related, builder.location(file, 0, 0))
+			}
+		}
+		names = append(names, names[0])
+
+		builder.results = append(builder.results, SarifResult{
+			RuleID:           SarifRuleIncludeCycle,
+			Level:            sarifLevel(SeverityWarning),
+			Message:          SarifMessage{Text: "Include cycle: " + strings.Join(names, " -> ")},
+			Locations:        []SarifLocation{builder.location(cycle.Files[0], 0, 0)},
+			RelatedLocations: related,
+		})
+	}
+}
+
+// Log returns the SARIF log with everything added so far.
+func (builder *SarifBuilder) Log() SarifLog {
+	run := SarifRun{
+		Tool:    SarifTool{Driver: SarifDriver{Name: sarifToolName, Rules: builder.rules}},
+		Results: builder.results,
+	}
+	if run.Tool.Driver.Rules == nil {
+		run.Tool.Driver.Rules = []SarifRule{}
+	}
+	if run.Results == nil {
+		run.Results = []SarifResult{}
+	}
+
+	return SarifLog{Schema: SarifSchema, Version: SarifVersion, Runs: []SarifRun{run}}
+}
+
+// location creates the location of lines in fileName relative to the repository root.
+func (builder *SarifBuilder) location(fileName string, startLine int, endLine int) SarifLocation {
+	location := SarifLocation{
+		PhysicalLocation: SarifPhysicalLocation{
+			ArtifactLocation: SarifArtifactLocation{
+				URI:       strings.TrimPrefix(fileName, builder.prefix),
+				URIBaseID: "SRCROOT",
+			},
+		},
+	}
+
+	if startLine > 0 {
+		region := &SarifRegion{StartLine: startLine}
+		if endLine >= startLine {
+			region.EndLine = endLine
+		}
+		location.PhysicalLocation.Region = region
+	}
+
+	return location
+}
+
+// sarifLevel maps a severity to a SARIF level, "none" if unknown.
+func sarifLevel(severity string) string {
+	if level, ok := sarifLevels[severity]; ok {
+		return level
+	}
+
+	return "none"
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSarifBuilder(t *testing.T) {
+	report := ViolationReport{
+		Rules: []RuleModel{
+			{ID: RuleParameters, Description: "Too many parameters", Severity: SeverityError},
+			{ID: RulePublicMembers, Description: "Too many public members", Severity: SeverityOff},
+		},
+		Violations: []ViolationModel{
+			{Rule: RuleParameters, Severity: SeverityError, Message: "configure has 6 parameters", FileName: "5c8f/src/world.cpp", StartLine: 12, EndLine: 20},
+		},
+	}
+
+	builder := NewSarifBuilder("5c8f/")
+	builder.AddViolations(report)
+	builder.AddResult("include-cycle", SeverityInfo, "Cycle", "5c8f/src/a.h", 0, 0)
+
+	log := builder.Log()
+
+	if log.Version != SarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("Log() = %+v", log)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != RuleParameters {
+		t.Errorf("Log() rules = %+v, want only %s", run.Tool.Driver.Rules, RuleParameters)
+	}
+
+	want := []SarifResult{
+		{
+			RuleID:  RuleParameters,
+			Level:   "error",
+			Message: SarifMessage{Text: "configure has 6 parameters"},
+			Locations: []SarifLocation{{PhysicalLocation: SarifPhysicalLocation{
+				ArtifactLocation: SarifArtifactLocation{URI: "src/world.cpp", URIBaseID: "SRCROOT"},
+				Region:           &SarifRegion{StartLine: 12, EndLine: 20},
+			}}},
+		},
+		{
+			RuleID:  "include-cycle",
+			Level:   "note",
+			Message: SarifMessage{Text: "Cycle"},
+			Locations: []SarifLocation{{PhysicalLocation: SarifPhysicalLocation{
+				ArtifactLocation: SarifArtifactLocation{URI: "src/a.h", URIBaseID: "SRCROOT"},
+			}}},
+		},
+	}
+
+	if !reflect.DeepEqual(run.Results, want) {
+		t.Errorf("Log() results = %+v, want %+v", run.Results, want)
+	}
+}
+
+func TestSarifBuilder_AddIncludeCycles(t *testing.T) {
+	builder := NewSarifBuilder("5c8f/")
+	builder.AddIncludeCycles([]IncludeCycleModel{{Files: []string{"5c8f/a.hpp", "5c8f/b.hpp"}}})
+
+	results := builder.Log().Runs[0].Results
+	if len(results) != 1 {
+		t.Fatalf("Log() results = %+v", results)
+	}
+
+	if message := results[0].Message.Text; message != "Include cycle: a.hpp -> b.hpp -> a.hpp" {
+		t.Errorf("Log() message = %s", message)
+	}
+	if related := results[0].RelatedLocations; len(related) != 1 || related[0].PhysicalLocation.ArtifactLocation.URI != "b.hpp" {
+		t.Errorf("Log() related locations = %+v", related)
+	}
+}
@@ -0,0 +1,313 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaID identifies the JSON Schema of the parse result.
+const SchemaID = "https://github.com/zohaib194/CodebaseVisualizer3D/schema/parse-result.schema.json"
+
+// ErrInvalidParseResult is returned when the output of the parser does not follow the schema.
+var ErrInvalidParseResult = errors.New("Parse result does not follow the schema")
+
+// schemaDescriptions documents the objects of the schema.
+var schemaDescriptions = map[string]string{
+	"FileWrapperModel":     "Output of the java parser for one file",
+	"ProjectModel":         "Parsed repository as stored and returned by the api server",
+	"FileModel":            "Declarations found in one file",
+	"NamespaceModel":       "Namespace, or Java package, and the declarations inside it",
+	"UsingNamespaceModel":  "Using directive of a namespace",
+	"ClassModel":           "Class, struct or interface and its members grouped by access specifier",
+	"AccessSpecifierModel": "Members of a class with the same access, like \"public\"",
+	"FunctionModel":        "Function or method with its lines, parameters and body",
+	"FunctionBodyModel":    "Calls and local variables of a function",
+	"CallModel":            "Function call, with the scopes it is called through",
+	"ScopeModel":           "Object or class a call goes through, and its type when known",
+	"ParameterModel":       "Parameter of a function",
+	"VariableModel":        "Variable declaration",
+}
+
+// serverDescription documents fields the api server sets after parsing.
+const serverDescription = "Set by the api server, never by the parser"
+
+// Prefixes of references to the definitions of the parse result schema and to the schemas of an OpenAPI document.
+const (
+	definitionsRef = "#/definitions/"
+	componentsRef  = "#/components/schemas/"
+)
+
+// timeType is described as a date-time string, the way encoding/json writes it.
+var timeType = reflect.TypeOf(time.Time{})
+
+// JSONSchema is the subset of JSON Schema draft-07 describing the parse result and the models of the api.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Comment              string                 `json:"$comment,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// ParseResultSchema describes the output of the java parser, with the stored ProjectModel among its definitions.
+// It is generated from the struct tags of the models: "json" names the properties, "schema" marks them as
+// "required" from the parser or as set by the "server" only.
+func ParseResultSchema() JSONSchema {
+	definitions := make(map[string]*JSONSchema)
+	root := schemaOf(reflect.TypeOf(FileWrapperModel{}), definitions, definitionsRef)
+	schemaOf(reflect.TypeOf(ProjectModel{}), definitions, definitionsRef)
+
+	return JSONSchema{
+		Schema:      "http://json-schema.org/draft-07/schema#",
+		ID:          SchemaID,
+		Comment:     "Schema version " + strconv.Itoa(SchemaVersion),
+		Title:       "CodebaseVisualizer3D parse result",
+		Ref:         root.Ref,
+		Definitions: definitions,
+	}
+}
+
+// ComponentSchemas describes the types of values as schemas of an OpenAPI document, keyed by type name
+// together with the structs they refer to. Properties are named and marked the same way as in ParseResultSchema.
+func ComponentSchemas(values ...interface{}) map[string]*JSONSchema {
+	schemas := make(map[string]*JSONSchema)
+	for _, value := range values {
+		schemaOf(reflect.TypeOf(value), schemas, componentsRef)
+	}
+
+	return schemas
+}
+
+// ValidateResponse checks value, decoded from a response with json.Decoder.UseNumber, against schema,
+// resolving references in schemas. Unlike parse results, responses may contain properties only the server sets.
+func ValidateResponse(schema *JSONSchema, value interface{}, schemas map[string]*JSONSchema) error {
+	return schema.validate(value, "", schemas, true)
+}
+
+// schemaOf describes values of t, adding the structs it refers to to definitions, referenced with refPrefix.
+func schemaOf(t reflect.Type, definitions map[string]*JSONSchema, refPrefix string) *JSONSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), definitions, refPrefix)
+
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: schemaOf(t.Elem(), definitions, refPrefix)}
+
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+
+	case reflect.Interface:
+		return &JSONSchema{}
+
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+
+	case reflect.Struct:
+		if t == timeType {
+			return &JSONSchema{Type: "string", Format: "date-time"}
+		}
+
+		ref := &JSONSchema{Ref: refPrefix + t.Name()}
+		if _, ok := definitions[t.Name()]; ok {
+			return ref
+		}
+
+		closed := false
+		object := &JSONSchema{
+			Type:                 "object",
+			Description:          schemaDescriptions[t.Name()],
+			Properties:           make(map[string]*JSONSchema),
+			AdditionalProperties: &closed,
+		}
+		definitions[t.Name()] = object
+
+		addProperties(object, t, definitions, refPrefix)
+		sort.Strings(object.Required)
+
+		return ref
+
+	default:
+		return &JSONSchema{Type: "string"}
+	}
+}
+
+// addProperties adds the fields of the struct t to object, with the fields of embedded structs inlined.
+func addProperties(object *JSONSchema, t reflect.Type, definitions map[string]*JSONSchema, refPrefix string) {
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && len(name) == 0 && field.Type.Kind() == reflect.Struct {
+			addProperties(object, field.Type, definitions, refPrefix)
+			continue
+		}
+		if len(field.PkgPath) > 0 || name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		property := schemaOf(field.Type, definitions, refPrefix)
+		switch field.Tag.Get("schema") {
+		case "required":
+			object.Required = append(object.Required, name)
+		case "server":
+			property.ReadOnly = true
+			property.Description = serverDescription
+		}
+		object.Properties[name] = property
+	}
+}
+
+// DecodeParseResult decodes the output of the java parser for one file, checking it against ParseResultSchema.
+// Unknown properties, properties of the wrong type, missing required properties and properties
+// only the server sets are rejected with ErrInvalidParseResult.
+func DecodeParseResult(data []byte) (FileModel, error) {
+	schema := ParseResultSchema()
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return FileModel{}, err
+	}
+
+	if err := schema.validate(value, "", schema.Definitions, false); err != nil {
+		return FileModel{}, fmt.Errorf("%s: %s", ErrInvalidParseResult.Error(), err.Error())
+	}
+
+	var wrapper FileWrapperModel
+	decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&wrapper); err != nil {
+		return FileModel{}, fmt.Errorf("%s: %s", ErrInvalidParseResult.Error(), err.Error())
+	}
+
+	return wrapper.File, nil
+}
+
+// validate checks value at path against schema, resolving references in definitions.
+// Properties only the server sets are rejected unless readOnly allows them.
+func (schema *JSONSchema) validate(value interface{}, path string, definitions map[string]*JSONSchema, readOnly bool) error {
+	if len(schema.Ref) > 0 {
+		definition, ok := definitions[schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]]
+		if !ok {
+			return fmt.Errorf("unknown reference %s", schema.Ref)
+		}
+		return definition.validate(value, path, definitions, readOnly)
+	}
+
+	if len(path) == 0 {
+		path = "$"
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", path)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s is missing %s", path, name)
+			}
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			switch {
+			case !ok && schema.AdditionalProperties != nil && !*schema.AdditionalProperties:
+				return fmt.Errorf("%s has unknown property %s", path, name)
+			case !ok:
+				continue
+			case property.ReadOnly && !readOnly:
+				return fmt.Errorf("%s.%s is set by the server only", path, name)
+			}
+
+			if err := property.validate(object[name], path+"."+name, definitions, readOnly); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an array", path)
+		}
+
+		for index, element := range array {
+			if err := schema.Items.validate(element, path+"["+strconv.Itoa(index)+"]", definitions, readOnly); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s is not a string", path)
+		}
+		if len(schema.Enum) > 0 && !containsString(schema.Enum, text) {
+			return fmt.Errorf("%s is not one of %s", path, strings.Join(schema.Enum, ", "))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s is not a boolean", path)
+		}
+
+	case "integer":
+		number, ok := value.(json.Number)
+		if _, err := number.Int64(); !ok || err != nil {
+			return fmt.Errorf("%s is not an integer", path)
+		}
+
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%s is not a number", path)
+		}
+	}
+
+	return nil
+}
+
+// containsString tells if values contains value.
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
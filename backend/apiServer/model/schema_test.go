@@ -0,0 +1,145 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "Write the generated schema to the documented schema file")
+
+// schemaFile is the documented copy of ParseResultSchema shared with the parser.
+var schemaFile = filepath.Join("..", "..", "schema", "parse-result.schema.json")
+
+func TestParseResultSchema(t *testing.T) {
+	generated, err := json.MarshalIndent(ParseResultSchema(), "", "  ")
+	if err != nil {
+		t.Fatalf("ParseResultSchema() could not be encoded: %v", err)
+	}
+	generated = append(generated, '\n')
+
+	if *updateSchema {
+		if err := ioutil.WriteFile(schemaFile, generated, 0644); err != nil {
+			t.Fatalf("Could not write %s: %v", schemaFile, err)
+		}
+	}
+
+	documented, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatalf("Could not read %s: %v", schemaFile, err)
+	}
+
+	if !bytes.Equal(generated, documented) {
+		t.Errorf("%s is out of date with the models, update it with \"go test ./model -run TestParseResultSchema -update\" and bump SchemaVersion with a migration if stored data changes", schemaFile)
+	}
+}
+
+func TestDecodeParseResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{
+			name:   "Parser output",
+			output: `{"file":{"functions":[{"return_type":"void","declrator_id":"test","name":"test()","start_line":1,"function_body":{"calls":[{"identifier":"run()","scopes":[{"identifier":"app","type":"App"}]}]},"end_line":3}],"using_namespaces":[{"line_number":1,"name":"std"}],"file_name":"main.cpp"}}`,
+		},
+		{
+			name:    "Unknown property",
+			output:  `{"file":{"file_name":"main.cpp","function":[]}}`,
+			wantErr: "$.file has unknown property function",
+		},
+		{
+			name:    "Missing required property",
+			output:  `{"file":{"file_name":"main.cpp","functions":[{"name":"test()","start_line":1}]}}`,
+			wantErr: "$.file.functions[0] is missing end_line",
+		},
+		{
+			name:    "Wrong type",
+			output:  `{"file":{"file_name":"main.cpp","functions":[{"name":"test()","start_line":"1","end_line":1}]}}`,
+			wantErr: "$.file.functions[0].start_line is not an integer",
+		},
+		{
+			name:    "Server property",
+			output:  `{"file":{"file_name":"main.cpp","parsed":true}}`,
+			wantErr: "$.file.parsed is set by the server only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := DecodeParseResult([]byte(tt.output))
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("DecodeParseResult() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DecodeParseResult() error = %v", err)
+			}
+			if file.FileName != "main.cpp" || len(file.Functions) != 1 || file.UsingNamespaces[0].LineNr != 1 {
+				t.Errorf("DecodeParseResult() = %+v", file)
+			}
+		})
+	}
+}
+
+func TestMigrateProject(t *testing.T) {
+	tests := []struct {
+		name         string
+		project      ProjectModel
+		wantMigrated bool
+		wantFile     string
+		wantErr      error
+	}{
+		{
+			name:         "Unversioned",
+			project:      ProjectModel{Files: []FileModel{{FileName: "./repos/5c8f/src/main.cpp"}}},
+			wantMigrated: true,
+			wantFile:     "5c8f/src/main.cpp",
+		},
+		{
+			name:     "Current",
+			project:  ProjectModel{SchemaVersion: SchemaVersion, Files: []FileModel{{FileName: "5c8f/src/main.cpp"}}},
+			wantFile: "5c8f/src/main.cpp",
+		},
+		{
+			name:    "Not parsed",
+			project: ProjectModel{},
+		},
+		{
+			name:    "Newer",
+			project: ProjectModel{SchemaVersion: SchemaVersion + 1},
+			wantErr: ErrSchemaTooNew,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, err := MigrateProject(&tt.project, "./repos")
+			if err != tt.wantErr {
+				t.Fatalf("MigrateProject() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if migrated != tt.wantMigrated {
+				t.Errorf("MigrateProject() migrated = %v, want %v", migrated, tt.wantMigrated)
+			}
+			if tt.project.SchemaVersion != SchemaVersion {
+				t.Errorf("MigrateProject() version = %d, want %d", tt.project.SchemaVersion, SchemaVersion)
+			}
+			if len(tt.wantFile) > 0 && tt.project.Files[0].FileName != tt.wantFile {
+				t.Errorf("MigrateProject() file = %s, want %s", tt.project.Files[0].FileName, tt.wantFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,556 @@
+package model
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Search modes supported by SearchIndex.Search.
+const (
+	SearchPrefix = "prefix"
+	SearchFuzzy  = "fuzzy"
+	SearchRegex  = "regex"
+)
+
+// SearchContent is the result kind of a line of file content matching a query.
+const SearchContent = "content"
+
+// Limits applied to searches and indexed content.
+const (
+	DefaultSearchLimit  = 50
+	MaxSearchLimit      = 500
+	maxSearchQuery      = 256
+	maxIndexedFileBytes = 1 << 20
+)
+
+// Limits of the search index caches, full indexes hold the content of every file of a repository.
+const (
+	maxCachedSearchIndexes = 8
+	maxCachedSymbolIndexes = 128
+)
+
+// kindWeights ranks result kinds against each other, symbols above file content.
+var kindWeights = map[string]float64{
+	SymbolFunction:  1.0,
+	SymbolClass:     1.0,
+	SymbolNamespace: 0.9,
+	SymbolVariable:  0.8,
+	SymbolParameter: 0.6,
+	SearchContent:   0.3,
+}
+
+// ErrInvalidSearch is returned for queries that are empty, too long or use an unknown mode.
+var ErrInvalidSearch = errors.New("Invalid search query")
+
+// SearchQuery describes what to search for.
+type SearchQuery struct {
+	Text  string          // Text, prefix or regular expression to search for
+	Mode  string          // One of the Search modes, prefix if empty
+	Kinds map[string]bool // Result kinds to include, all if empty
+	Limit int             // Maximum number of results, DefaultSearchLimit if zero
+}
+
+// SearchResultModel represents a symbol or line of content matching a query.
+type SearchResultModel struct {
+	Kind          string  `json:"kind"`                     // Symbol kind or SearchContent
+	Name          string  `json:"name"`                     // Name of the symbol, or the matching text of a line
+	QualifiedName string  `json:"qualified_name,omitempty"` // Name prefixed by enclosing namespaces and classes
+	FileName      string  `json:"file_name"`                // File the result is found in
+	StartLine     int     `json:"start_line,omitempty"`     // First line of the result when known
+	EndLine       int     `json:"end_line,omitempty"`       // Last line of the result when known
+	Score         float64 `json:"score"`                    // Relevance between 0 and 1, higher is better
+	Snippet       string  `json:"snippet,omitempty"`        // The matching line for content results
+}
+
+// indexedFile holds the lines of a file for content search.
+type indexedFile struct {
+	fileName string
+	lines    []string
+}
+
+// SearchIndex holds the symbols and file contents of a parsed repository.
+type SearchIndex struct {
+	symbols []SymbolModel
+	files   []indexedFile
+}
+
+// searchIndexCache keeps built indexes by repository id until the repository is parsed again,
+// dropping the least recently used index beyond max indexes.
+type searchIndexCache struct {
+	mutex   sync.Mutex
+	max     int
+	indexes map[string]*list.Element // Elements of recent by repository id
+	recent  *list.List               // Cached indexes from most to least recently used
+}
+
+// cachedSearchIndex is an element of searchIndexCache.recent.
+type cachedSearchIndex struct {
+	id    string
+	index *SearchIndex
+}
+
+// searchIndexes is the cache shared by all searches in one repository.
+var searchIndexes = newSearchIndexCache(maxCachedSearchIndexes)
+
+// symbolIndexes caches the indexes without file content searched across repositories.
+var symbolIndexes = newSearchIndexCache(maxCachedSymbolIndexes)
+
+// newSearchIndexCache creates a cache keeping at most max indexes.
+func newSearchIndexCache(max int) *searchIndexCache {
+	return &searchIndexCache{max: max, indexes: make(map[string]*list.Element), recent: list.New()}
+}
+
+// get returns the cached index of the repository with id, marking it as recently used.
+func (cache *searchIndexCache) get(id string) (*SearchIndex, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.indexes[id]
+	if !ok {
+		return nil, false
+	}
+
+	cache.recent.MoveToFront(element)
+	return element.Value.(*cachedSearchIndex).index, true
+}
+
+// put caches index as the index of the repository with id.
+func (cache *searchIndexCache) put(id string, index *SearchIndex) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.indexes[id]; ok {
+		element.Value.(*cachedSearchIndex).index = index
+		cache.recent.MoveToFront(element)
+		return
+	}
+
+	cache.indexes[id] = cache.recent.PushFront(&cachedSearchIndex{id: id, index: index})
+
+	// Drop the least recently used index to stay within bounds.
+	if cache.recent.Len() > cache.max {
+		oldest := cache.recent.Back()
+		cache.recent.Remove(oldest)
+		delete(cache.indexes, oldest.Value.(*cachedSearchIndex).id)
+	}
+}
+
+// remove drops the cached index of the repository with id.
+func (cache *searchIndexCache) remove(id string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.indexes[id]; ok {
+		cache.recent.Remove(element)
+		delete(cache.indexes, id)
+	}
+}
+
+// RepoSearchResultModel groups the results of a search across repositories by repository.
+type RepoSearchResultModel struct {
+	ID      string              `json:"id"`      // Id of the repository
+	URI     string              `json:"uri"`     // Where the repository was found
+	Results []SearchResultModel `json:"results"` // Matching symbols ordered by descending score
+}
+
+// SearchAllRepos searches the symbols of every repository userID has access to, without file content.
+// Repositories without matches are left out and groups are ordered by their best result.
+func SearchAllRepos(storage config.Storage, query SearchQuery, userID bson.ObjectId) ([]RepoSearchResultModel, error) {
+	util.TypeLogger.Debug("%s: Call to SearchAllRepos", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to SearchAllRepos", packageName)
+
+	// Only symbols are searched across repositories.
+	kinds := make(map[string]bool)
+	for _, kind := range []string{SymbolFunction, SymbolClass, SymbolNamespace, SymbolVariable, SymbolParameter} {
+		if len(query.Kinds) == 0 || query.Kinds[kind] {
+			kinds[kind] = true
+		}
+	}
+	if len(kinds) == 0 {
+		return []RepoSearchResultModel{}, nil
+	}
+	query.Kinds = kinds
+
+	if _, err := newSearchMatcher(query); err != nil || len(query.Text) == 0 || len(query.Text) > maxSearchQuery {
+		return nil, ErrInvalidSearch
+	}
+
+	repos, err := RepoModel{}.FetchAllForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []RepoSearchResultModel{}
+	for _, listed := range repos {
+		id, ok := listed["_id"].(bson.ObjectId)
+		if !ok {
+			continue
+		}
+		uri, _ := listed["uri"].(string)
+
+		index, err := symbolIndexByID(storage, id.Hex())
+		if err != nil {
+			util.TypeLogger.Warn("%s: Skipping repository %s in search: %s", packageName, id.Hex(), err.Error())
+			continue
+		}
+
+		results, err := index.Search(query)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(results) > 0 {
+			groups = append(groups, RepoSearchResultModel{ID: id.Hex(), URI: uri, Results: results})
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Results[0].Score > groups[j].Results[0].Score
+	})
+
+	return groups, nil
+}
+
+// symbolIndexByID returns an index of the symbols of a repository, reusing its full search index when cached.
+// The repository is only read from db when neither is cached, and its files are never read.
+func symbolIndexByID(storage config.Storage, id string) (*SearchIndex, error) {
+	if index, ok := searchIndexes.get(id); ok {
+		return index, nil
+	}
+	if index, ok := symbolIndexes.get(id); ok {
+		return index, nil
+	}
+
+	repo, err := RepoModel{}.GetRepoByID(storage, id)
+	if err != nil {
+		return nil, err
+	}
+
+	index := NewSymbolIndex(repo.ParsedRepo)
+	symbolIndexes.put(id, index)
+
+	return index, nil
+}
+
+// GetSearchIndex returns the search index of the repository cloned to storage, building it on first use.
+func (repo RepoModel) GetSearchIndex(storage config.Storage) *SearchIndex {
+	util.TypeLogger.Debug("%s: Call to GetSearchIndex", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to GetSearchIndex", packageName)
+
+	if index, ok := searchIndexes.get(repo.ID.Hex()); ok {
+		return index
+	}
+
+	index := NewSearchIndex(repo.ParsedRepo, storage.RepoPath)
+	searchIndexes.put(repo.ID.Hex(), index)
+
+	return index
+}
+
+// InvalidateSearchIndex drops the cached search indexes of the repository with id.
+func InvalidateSearchIndex(id string) {
+	searchIndexes.remove(id)
+	symbolIndexes.remove(id)
+}
+
+// NewSearchIndex indexes the symbols of project and the content of its files found below root.
+// Files that can not be read, are too large or look binary are only indexed by their symbols.
+func NewSearchIndex(project ProjectModel, root string) *SearchIndex {
+	index := NewSymbolIndex(project)
+
+	for _, file := range project.Files {
+		filename := filepath.Join(root, filepath.FromSlash(file.FileName))
+		info, err := os.Stat(filename)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxIndexedFileBytes {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filename)
+		if err != nil || bytes.IndexByte(content, 0) >= 0 {
+			continue
+		}
+
+		lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+		index.files = append(index.files, indexedFile{fileName: file.FileName, lines: lines})
+	}
+
+	return index
+}
+
+// NewSymbolIndex indexes the symbols of project without reading its files, so it never finds content.
+func NewSymbolIndex(project ProjectModel) *SearchIndex {
+	index := &SearchIndex{}
+
+	for _, file := range project.Files {
+		index.symbols = append(index.symbols, file.Symbols()...)
+	}
+
+	return index
+}
+
+// Search finds symbols and lines of content matching query, ordered by descending score.
+func (index *SearchIndex) Search(query SearchQuery) ([]SearchResultModel, error) {
+	if len(query.Text) == 0 || len(query.Text) > maxSearchQuery {
+		return nil, ErrInvalidSearch
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultSearchLimit
+	}
+	if query.Limit > MaxSearchLimit {
+		query.Limit = MaxSearchLimit
+	}
+
+	matcher, err := newSearchMatcher(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResultModel
+
+	for _, symbol := range index.symbols {
+		if len(query.Kinds) > 0 && !query.Kinds[symbol.Kind] {
+			continue
+		}
+
+		score := matcher.matchName(symbol.Name)
+		if qualifiedScore := 0.9 * matcher.matchName(symbol.QualifiedName); qualifiedScore > score {
+			score = qualifiedScore
+		}
+		if score <= 0 {
+			continue
+		}
+
+		results = append(results, SearchResultModel{
+			Kind:          symbol.Kind,
+			Name:          symbol.Name,
+			QualifiedName: symbol.QualifiedName,
+			FileName:      symbol.FileName,
+			StartLine:     symbol.StartLine,
+			EndLine:       symbol.EndLine,
+			Score:         score * kindWeights[symbol.Kind],
+		})
+	}
+
+	// Content results share one score and are ordered by position, so only the first Limit can be returned.
+	if len(query.Kinds) == 0 || query.Kinds[SearchContent] {
+		contentResults := 0
+		for _, file := range index.files {
+			for lineIndex, line := range file.lines {
+				if contentResults >= query.Limit {
+					break
+				}
+
+				match, ok := matcher.matchContent(line)
+				if !ok {
+					continue
+				}
+
+				results = append(results, SearchResultModel{
+					Kind:      SearchContent,
+					Name:      match,
+					FileName:  file.fileName,
+					StartLine: lineIndex + 1,
+					EndLine:   lineIndex + 1,
+					Score:     kindWeights[SearchContent],
+					Snippet:   strings.TrimSpace(line),
+				})
+				contentResults++
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].FileName != results[j].FileName {
+			return results[i].FileName < results[j].FileName
+		}
+		return results[i].StartLine < results[j].StartLine
+	})
+
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// searchMatcher scores names and finds matches in content for one query.
+type searchMatcher struct {
+	mode       string
+	text       string
+	lowerText  string
+	expression *regexp.Regexp
+}
+
+// newSearchMatcher validates the mode of query and compiles regular expressions.
+func newSearchMatcher(query SearchQuery) (matcher searchMatcher, err error) {
+	matcher = searchMatcher{mode: query.Mode, text: query.Text, lowerText: strings.ToLower(query.Text)}
+
+	switch query.Mode {
+	case "":
+		matcher.mode = SearchPrefix
+	case SearchPrefix, SearchFuzzy:
+	case SearchRegex:
+		matcher.expression, err = regexp.Compile(query.Text)
+		if err != nil {
+			return matcher, ErrInvalidSearch
+		}
+	default:
+		return matcher, ErrInvalidSearch
+	}
+
+	return matcher, nil
+}
+
+// matchName scores how well name matches the query, 0 if it does not match.
+func (matcher searchMatcher) matchName(name string) float64 {
+	if len(name) == 0 {
+		return 0
+	}
+
+	lowerName := strings.ToLower(name)
+
+	switch matcher.mode {
+	case SearchRegex:
+		location := matcher.expression.FindStringIndex(name)
+		if location == nil {
+			return 0
+		}
+		if location[0] == 0 && location[1] == len(name) {
+			return 1
+		}
+		return 0.5 + 0.4*float64(location[1]-location[0])/float64(len(name))
+
+	case SearchFuzzy:
+		if lowerName == matcher.lowerText {
+			return 1
+		}
+		if score := subsequenceScore(matcher.lowerText, lowerName); score > 0 {
+			return 0.4 + 0.5*score
+		}
+		if distance := levenshtein(matcher.lowerText, lowerName); distance <= maxTypos(matcher.lowerText) {
+			return 0.4 * (1 - float64(distance)/float64(len(lowerName)+1))
+		}
+		return 0
+
+	default:
+		switch {
+		case name == matcher.text:
+			return 1
+		case lowerName == matcher.lowerText:
+			return 0.95
+		case strings.HasPrefix(name, matcher.text):
+			return 0.7 + 0.2*float64(len(matcher.text))/float64(len(name))
+		case strings.HasPrefix(lowerName, matcher.lowerText):
+			return 0.6 + 0.2*float64(len(matcher.text))/float64(len(name))
+		}
+		return 0
+	}
+}
+
+// matchContent finds the query in a line of content, returning the matched text.
+// Prefix and fuzzy queries match content as case insensitive substrings.
+func (matcher searchMatcher) matchContent(line string) (string, bool) {
+	if matcher.mode == SearchRegex {
+		match := matcher.expression.FindString(line)
+		return match, matcher.expression.MatchString(line)
+	}
+
+	lowerLine := strings.ToLower(line)
+	index := strings.Index(lowerLine, matcher.lowerText)
+	if index < 0 {
+		return "", false
+	}
+
+	// Lower casing may change the length of some characters, the offset is then not valid in line.
+	if len(lowerLine) != len(line) {
+		return matcher.text, true
+	}
+
+	return line[index : index+len(matcher.text)], true
+}
+
+// subsequenceScore checks that every character of query appears in name in order.
+// Consecutive characters and a match at the start of name score higher, the result is between 0 and 1.
+func subsequenceScore(query string, name string) float64 {
+	if len(query) == 0 || len(query) > len(name) {
+		return 0
+	}
+
+	score := 0.0
+	position := 0
+	previous := -2
+	for _, character := range query {
+		found := strings.IndexRune(name[position:], character)
+		if found < 0 {
+			return 0
+		}
+		found += position
+
+		switch {
+		case found == previous+1:
+			score += 1
+		case found == 0:
+			score += 1
+		default:
+			score += 0.5
+		}
+
+		previous = found
+		position = found + len(string(character))
+	}
+
+	return score / float64(len(name))
+}
+
+// maxTypos is the edit distance allowed by fuzzy search for query.
+func maxTypos(query string) int {
+	if len(query) < 4 {
+		return 0
+	}
+	if len(query) < 8 {
+		return 1
+	}
+
+	return 2
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a string, b string) int {
+	first := []rune(a)
+	second := []rune(b)
+
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(second)]
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"os"
+	"testing"
+)
+
+// testSearchProject is a small project with one C++ file, written to disk by newTestSearchIndex.
+func testSearchProject() ProjectModel {
+	return ProjectModel{Files: []FileModel{{
+		Parsed:   true,
+		FileName: "repo/render.cpp",
+		Namespaces: []NamespaceModel{{
+			NamespaceName: "gfx",
+			Functions: []FunctionModel{{
+				Name:       "void drawMesh(Mesh mesh)",
+				Parameters: []ParameterModel{{Name: "mesh", Type: "Mesh"}},
+				StartLine:  2,
+				EndLine:    4,
+			}},
+			Classes: []ClassModel{{Name: "MeshRenderer"}},
+		}},
+	}}}
+}
+
+// newTestSearchIndex indexes testSearchProject with its file on disk.
+func newTestSearchIndex(t *testing.T) (*SearchIndex, func()) {
+	root := setupWalkerRepo(t, map[string]string{
+		"repo/render.cpp": "namespace gfx {\nvoid drawMesh(Mesh mesh) {\n  renderer.submit(mesh);\n}\n}\n",
+	})
+
+	return NewSearchIndex(testSearchProject(), root), func() { os.RemoveAll(root) }
+}
+
+func TestSearchIndex_Search(t *testing.T) {
+	index, cleanup := newTestSearchIndex(t)
+	defer cleanup()
+
+	tests := []struct {
+		name      string
+		query     SearchQuery
+		wantFirst SearchResultModel
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "Valid_prefix_ranks_function_first",
+			query:     SearchQuery{Text: "draw"},
+			wantFirst: SearchResultModel{Kind: SymbolFunction, Name: "drawMesh", QualifiedName: "gfx::drawMesh", FileName: "repo/render.cpp", StartLine: 2, EndLine: 4},
+			wantCount: 2,
+		},
+		{
+			name:      "Valid_prefix_on_qualified_name",
+			query:     SearchQuery{Text: "gfx::Mesh", Kinds: map[string]bool{SymbolClass: true}},
+			wantFirst: SearchResultModel{Kind: SymbolClass, Name: "MeshRenderer", QualifiedName: "gfx::MeshRenderer", FileName: "repo/render.cpp"},
+			wantCount: 1,
+		},
+		{
+			name:      "Valid_fuzzy_subsequence",
+			query:     SearchQuery{Text: "drwmsh", Mode: SearchFuzzy, Kinds: map[string]bool{SymbolFunction: true}},
+			wantFirst: SearchResultModel{Kind: SymbolFunction, Name: "drawMesh", QualifiedName: "gfx::drawMesh", FileName: "repo/render.cpp", StartLine: 2, EndLine: 4},
+			wantCount: 1,
+		},
+		{
+			name:      "Valid_fuzzy_typo",
+			query:     SearchQuery{Text: "darwMesh", Mode: SearchFuzzy, Kinds: map[string]bool{SymbolFunction: true}},
+			wantFirst: SearchResultModel{Kind: SymbolFunction, Name: "drawMesh", QualifiedName: "gfx::drawMesh", FileName: "repo/render.cpp", StartLine: 2, EndLine: 4},
+			wantCount: 1,
+		},
+		{
+			name:      "Valid_regex_content",
+			query:     SearchQuery{Text: `renderer\.\w+`, Mode: SearchRegex, Kinds: map[string]bool{SearchContent: true}},
+			wantFirst: SearchResultModel{Kind: SearchContent, Name: "renderer.submit", FileName: "repo/render.cpp", StartLine: 3, EndLine: 3, Snippet: "renderer.submit(mesh);"},
+			wantCount: 1,
+		},
+		{
+			name:    "Invalid_regex",
+			query:   SearchQuery{Text: "(", Mode: SearchRegex},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid_mode",
+			query:   SearchQuery{Text: "draw", Mode: "exact"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := index.Search(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(results) != tt.wantCount {
+				t.Fatalf("Search() returned %d results, want %d: %+v", len(results), tt.wantCount, results)
+			}
+
+			first := results[0]
+			first.Score = 0
+			if first != tt.wantFirst {
+				t.Errorf("Search() first = %+v, want %+v", first, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestNewSymbolIndex(t *testing.T) {
+	_, cleanup := newTestSearchIndex(t)
+	defer cleanup()
+
+	index := NewSymbolIndex(testSearchProject())
+
+	results, err := index.Search(SearchQuery{Text: "renderer", Mode: SearchRegex})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Search() found %+v, want no content in a symbol index", results)
+	}
+
+	results, err = index.Search(SearchQuery{Text: "draw"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "drawMesh" {
+		t.Errorf("Search() = %+v, want only drawMesh", results)
+	}
+}
+
+func TestSearchIndexCache(t *testing.T) {
+	cache := newSearchIndexCache(2)
+	first, second, third := &SearchIndex{}, &SearchIndex{}, &SearchIndex{}
+
+	cache.put("first", first)
+	cache.put("second", second)
+	if index, ok := cache.get("first"); !ok || index != first {
+		t.Fatalf("get(first) = %v, %t, want the first index", index, ok)
+	}
+
+	// Second is the least recently used once first was read.
+	cache.put("third", third)
+	if _, ok := cache.get("second"); ok {
+		t.Errorf("get(second) found an index, want it dropped")
+	}
+	if index, ok := cache.get("first"); !ok || index != first {
+		t.Errorf("get(first) = %v, %t, want the first index kept", index, ok)
+	}
+	if index, ok := cache.get("third"); !ok || index != third {
+		t.Errorf("get(third) = %v, %t, want the third index", index, ok)
+	}
+
+	cache.remove("third")
+	if _, ok := cache.get("third"); ok || cache.recent.Len() != 1 {
+		t.Errorf("remove(third) kept %d indexes, want only first", cache.recent.Len())
+	}
+}
+
+func Test_levenshtein(t *testing.T) {
+	if got := levenshtein("kitten", "sitting"); got != 3 {
+		t.Errorf("levenshtein() = %d, want 3", got)
+	}
+}
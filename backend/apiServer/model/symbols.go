@@ -0,0 +1,285 @@
+package model
+
+import (
+	"strings"
+)
+
+// Kinds of symbols found in a parsed file.
+const (
+	SymbolFunction  = "function"
+	SymbolClass     = "class"
+	SymbolNamespace = "namespace"
+	SymbolVariable  = "variable"
+	SymbolParameter = "parameter"
+)
+
+// SymbolModel represents a named entity declared in a parsed file.
+type SymbolModel struct {
+	Kind          string `json:"kind"`                 // One of the Symbol kinds
+	Name          string `json:"name"`                 // Identifier as written in code
+	QualifiedName string `json:"qualified_name"`       // Name prefixed by enclosing namespaces and classes
+	FileName      string `json:"file_name"`            // File the symbol is declared in
+	StartLine     int    `json:"start_line,omitempty"` // First line of the symbol, or of the function a local belongs to
+	EndLine       int    `json:"end_line,omitempty"`   // Last line of the symbol, or of the function a local belongs to
+	Local         bool   `json:"local,omitempty"`      // Parameter or variable only visible inside its function
+}
+
+// Identifier returns the bare name of the function, without return type, scope and parameters.
+func (function FunctionModel) Identifier() string {
+	if len(function.DeclID) > 0 {
+		return lastScopeElement(function.DeclID)
+	}
+
+	return lastScopeElement(identifierBeforeParenthesis(function.Name))
+}
+
+// Qualifier returns the scope the function is defined in as written in its name, e.g. "Class" for "void Class::run()".
+func (function FunctionModel) Qualifier() string {
+	name := identifierBeforeParenthesis(function.Name)
+	if index := strings.LastIndex(name, "::"); index >= 0 {
+		return name[:index]
+	}
+
+	return ""
+}
+
+// FunctionName returns the bare name of the called function.
+func (call CallModel) FunctionName() string {
+	return lastScopeElement(identifierBeforeParenthesis(call.Identifier))
+}
+
+// ScopeType returns the type of the object the function is called on, without qualifiers and template arguments.
+// It is empty for calls that are not made on an object or whose type is unknown.
+func (call CallModel) ScopeType() string {
+	if len(call.Scope) == 0 {
+		return ""
+	}
+
+	typeName := identifierBeforeParenthesis(strings.Replace(call.Scope[len(call.Scope)-1].Type, "const ", "", -1))
+	if template := strings.Index(typeName, "<"); template >= 0 {
+		typeName = typeName[:template]
+	}
+
+	return typeName
+}
+
+// identifierBeforeParenthesis returns the last word before the first parenthesis in a declaration.
+func identifierBeforeParenthesis(declaration string) string {
+	if index := strings.Index(declaration, "("); index >= 0 {
+		declaration = declaration[:index]
+	}
+
+	fields := strings.FieldsFunc(declaration, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '*' || r == '&'
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return fields[len(fields)-1]
+}
+
+// lastScopeElement strips any namespace, class or package qualification from name.
+func lastScopeElement(name string) string {
+	if index := strings.LastIndex(name, "::"); index >= 0 {
+		name = name[index+2:]
+	}
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		name = name[index+1:]
+	}
+
+	return name
+}
+
+// qualify joins scope and name with the C++ scope operator.
+func qualify(scope string, name string) string {
+	if len(scope) == 0 {
+		return name
+	}
+
+	return scope + "::" + name
+}
+
+// Symbols lists every function, class, namespace, variable and parameter declared in the file.
+func (file FileModel) Symbols() (symbols []SymbolModel) {
+	collector := symbolCollector{fileName: file.FileName}
+
+	collector.functions("", file.Functions)
+	collector.namespaces("", file.Namespaces)
+	collector.classes("", file.Classes)
+	collector.variables("", file.Variables)
+
+	return collector.symbols
+}
+
+// symbolCollector gathers symbols while walking a file model.
+type symbolCollector struct {
+	fileName string
+	symbols  []SymbolModel
+}
+
+// add appends a symbol declared in the file.
+func (collector *symbolCollector) add(symbol SymbolModel) {
+	symbol.FileName = collector.fileName
+	collector.symbols = append(collector.symbols, symbol)
+}
+
+// functions adds functions declared in scope with their parameters and local variables.
+func (collector *symbolCollector) functions(scope string, functions []FunctionModel) {
+	for _, function := range functions {
+		name := function.Identifier()
+		if len(name) == 0 {
+			continue
+		}
+
+		qualifiedName := qualify(scope, name)
+		if qualifier := function.Qualifier(); len(qualifier) > 0 {
+			qualifiedName = qualify(scope, qualify(qualifier, name))
+		}
+
+		collector.add(SymbolModel{
+			Kind:          SymbolFunction,
+			Name:          name,
+			QualifiedName: qualifiedName,
+			StartLine:     function.StartLine,
+			EndLine:       function.EndLine,
+		})
+
+		for _, parameter := range function.Parameters {
+			collector.add(SymbolModel{
+				Kind:          SymbolParameter,
+				Name:          parameter.Name,
+				QualifiedName: qualify(qualifiedName, parameter.Name),
+				StartLine:     function.StartLine,
+				EndLine:       function.EndLine,
+				Local:         true,
+			})
+		}
+
+		for _, variable := range function.FunctionBody.Variables {
+			collector.add(SymbolModel{
+				Kind:          SymbolVariable,
+				Name:          variable.Name,
+				QualifiedName: qualify(qualifiedName, variable.Name),
+				StartLine:     function.StartLine,
+				EndLine:       function.EndLine,
+				Local:         true,
+			})
+		}
+	}
+}
+
+// namespaces adds namespaces declared in scope and everything declared inside them.
+func (collector *symbolCollector) namespaces(scope string, namespaces []NamespaceModel) {
+	for _, namespace := range namespaces {
+		qualifiedName := qualify(scope, namespace.NamespaceName)
+		collector.add(SymbolModel{Kind: SymbolNamespace, Name: namespace.NamespaceName, QualifiedName: qualifiedName})
+
+		collector.functions(qualifiedName, namespace.Functions)
+		collector.namespaces(qualifiedName, namespace.Namespaces)
+		collector.classes(qualifiedName, namespace.Classes)
+		collector.variables(qualifiedName, namespace.Variables)
+	}
+}
+
+// classes adds classes declared in scope and their members.
+func (collector *symbolCollector) classes(scope string, classes []ClassModel) {
+	for _, class := range classes {
+		qualifiedName := qualify(scope, class.Name)
+		startLine, endLine := class.LineRange()
+		collector.add(SymbolModel{
+			Kind:          SymbolClass,
+			Name:          class.Name,
+			QualifiedName: qualifiedName,
+			StartLine:     startLine,
+			EndLine:       endLine,
+		})
+
+		for _, accessSpecifier := range class.AccessSpecifierModels {
+			collector.functions(qualifiedName, accessSpecifier.Functions)
+			collector.classes(qualifiedName, accessSpecifier.Classes)
+			collector.variables(qualifiedName, accessSpecifier.Variables)
+		}
+	}
+}
+
+// variables adds variables declared in scope.
+func (collector *symbolCollector) variables(scope string, variables []VariableModel) {
+	for _, variable := range variables {
+		collector.add(SymbolModel{Kind: SymbolVariable, Name: variable.Name, QualifiedName: qualify(scope, variable.Name)})
+	}
+}
+
+// LineRange approximates the lines of the class from the functions defined in it, zero if it has none.
+func (class ClassModel) LineRange() (startLine int, endLine int) {
+	for _, accessSpecifier := range class.AccessSpecifierModels {
+		for _, function := range accessSpecifier.Functions {
+			if function.StartLine > 0 && (startLine == 0 || function.StartLine < startLine) {
+				startLine = function.StartLine
+			}
+			if function.EndLine > endLine {
+				endLine = function.EndLine
+			}
+		}
+		for _, nested := range accessSpecifier.Classes {
+			nestedStart, nestedEnd := nested.LineRange()
+			if nestedStart > 0 && (startLine == 0 || nestedStart < startLine) {
+				startLine = nestedStart
+			}
+			if nestedEnd > endLine {
+				endLine = nestedEnd
+			}
+		}
+	}
+
+	return startLine, endLine
+}
+
+// resolveSymbol picks the symbol named name that is visible at line, preferring locals of the enclosing function.
+func resolveSymbol(symbols []SymbolModel, name string, line int) *SymbolModel {
+	var best *SymbolModel
+	bestRank := 0
+
+	for index := range symbols {
+		symbol := &symbols[index]
+		if symbol.Name != name {
+			continue
+		}
+
+		contains := symbol.StartLine > 0 && symbol.StartLine <= line && line <= symbol.EndLine
+
+		rank := 1
+		switch {
+		case symbol.Local && !contains:
+			continue
+		case symbol.Local:
+			rank = 4
+		case contains:
+			rank = 3
+		case symbol.Kind != SymbolNamespace:
+			rank = 2
+		}
+
+		if rank > bestRank {
+			best, bestRank = symbol, rank
+		}
+	}
+
+	return best
+}
+
+// AnnotateTokens sets the reference of identifier tokens to the symbol from file they refer to.
+func AnnotateTokens(tokens []TokenModel, file FileModel) {
+	symbols := file.Symbols()
+
+	for index, token := range tokens {
+		if token.Kind != TokenIdentifier {
+			continue
+		}
+
+		if symbol := resolveSymbol(symbols, token.Text, token.Line); symbol != nil {
+			ref := *symbol
+			tokens[index].Ref = &ref
+		}
+	}
+}
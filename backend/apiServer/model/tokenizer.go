@@ -0,0 +1,264 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kinds of tokens produced by Tokenize.
+const (
+	TokenKeyword      = "keyword"
+	TokenIdentifier   = "identifier"
+	TokenString       = "string"
+	TokenChar         = "char"
+	TokenNumber       = "number"
+	TokenComment      = "comment"
+	TokenPreprocessor = "preprocessor"
+	TokenAnnotation   = "annotation"
+	TokenOperator     = "operator"
+	TokenWhitespace   = "whitespace"
+)
+
+// TokenModel represents a lexical token of source code on a single line.
+type TokenModel struct {
+	Line   int          `json:"line"`          // Line number starting at 1
+	Column int          `json:"column"`        // Column of first character starting at 1
+	Text   string       `json:"text"`          // Source text of the token
+	Kind   string       `json:"kind"`          // One of the Token kinds
+	Ref    *SymbolModel `json:"ref,omitempty"` // Parsed symbol an identifier refers to
+}
+
+// keywords lists reserved words per language.
+var keywords = map[string]map[string]bool{
+	LanguageCpp: wordSet(`alignas alignof and and_eq asm auto bitand bitor bool break case catch char char16_t
+		char32_t class compl const constexpr const_cast continue decltype default delete do double dynamic_cast
+		else enum explicit export extern false final float for friend goto if inline int long mutable namespace
+		new noexcept not not_eq nullptr operator or or_eq override private protected public register
+		reinterpret_cast return short signed sizeof static static_assert static_cast struct switch template this
+		thread_local throw true try typedef typeid typename union unsigned using virtual void volatile wchar_t
+		while xor xor_eq`),
+	LanguageJava: wordSet(`abstract assert boolean break byte case catch char class const continue default do
+		double else enum extends false final finally float for goto if implements import instanceof int
+		interface long native new null package private protected public return short static strictfp super
+		switch synchronized this throw throws transient true try var void volatile while`),
+}
+
+// multiCharOperators are operators kept as one token, longest first.
+var multiCharOperators = []string{"...", "::", "->", "<<=", ">>=", "==", "!=", "<=", ">=", "&&", "||", "++", "--", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^="}
+
+// wordSet splits whitespace separated words into a set.
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+
+	return set
+}
+
+// tokenizer holds the state while lexing source code.
+type tokenizer struct {
+	source   []rune
+	position int
+	line     int
+	column   int
+	language string
+	tokens   []TokenModel
+}
+
+// Tokenize splits source into tokens for language, one of the Language constants.
+// Unknown languages are lexed without keywords. Tokens spanning lines, like block
+// comments, are split so every token belongs to exactly one line.
+func Tokenize(source string, language string) []TokenModel {
+	lexer := tokenizer{source: []rune(source), line: 1, column: 1, language: language}
+
+	atLineStart := true
+	for lexer.position < len(lexer.source) {
+		current := lexer.peek(0)
+
+		switch {
+		case current == '\n':
+			lexer.advance(1)
+			atLineStart = true
+			continue
+
+		case current == ' ' || current == '\t' || current == '\r' || current == '\f' || current == '\v':
+			lexer.emitWhile(TokenWhitespace, func(r rune) bool { return r == ' ' || r == '\t' || r == '\r' || r == '\f' || r == '\v' })
+			continue
+
+		case current == '#' && atLineStart && language != LanguageJava:
+			lexer.emitPreprocessor()
+
+		case current == '/' && lexer.peek(1) == '/':
+			lexer.emitWhile(TokenComment, func(r rune) bool { return r != '\n' })
+
+		case current == '/' && lexer.peek(1) == '*':
+			lexer.emitUntil(TokenComment, "*/")
+
+		case current == '"':
+			lexer.emitQuoted(TokenString, '"')
+
+		case current == '\'':
+			lexer.emitQuoted(TokenChar, '\'')
+
+		case unicode.IsDigit(current) || (current == '.' && unicode.IsDigit(lexer.peek(1))):
+			lexer.emitWhile(TokenNumber, func(r rune) bool {
+				return unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || r == '_' || r == '\''
+			})
+
+		case current == '@' && language == LanguageJava && isIdentifierStart(lexer.peek(1)):
+			lexer.emitWhile(TokenAnnotation, func(r rune) bool { return isIdentifierPart(r) || r == '.' })
+
+		case isIdentifierStart(current):
+			lexer.emitIdentifier()
+
+		default:
+			lexer.emitOperator()
+		}
+
+		atLineStart = false
+	}
+
+	return lexer.tokens
+}
+
+// isIdentifierStart checks if r can start an identifier.
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+// isIdentifierPart checks if r can continue an identifier.
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r)
+}
+
+// peek returns the rune offset runes ahead, or 0 past the end.
+func (lexer *tokenizer) peek(offset int) rune {
+	if lexer.position+offset >= len(lexer.source) {
+		return 0
+	}
+
+	return lexer.source[lexer.position+offset]
+}
+
+// advance moves count runes forward keeping track of line and column.
+func (lexer *tokenizer) advance(count int) {
+	for i := 0; i < count && lexer.position < len(lexer.source); i++ {
+		if lexer.source[lexer.position] == '\n' {
+			lexer.line++
+			lexer.column = 1
+		} else {
+			lexer.column++
+		}
+		lexer.position++
+	}
+}
+
+// emit adds source from start up to the current position as tokens of kind, split on line breaks.
+func (lexer *tokenizer) emit(kind string, start int, line int, column int) {
+	for _, text := range strings.SplitAfter(string(lexer.source[start:lexer.position]), "\n") {
+		text = strings.TrimSuffix(text, "\n")
+		if len(text) > 0 {
+			lexer.tokens = append(lexer.tokens, TokenModel{Line: line, Column: column, Text: text, Kind: kind})
+		}
+		line++
+		column = 1
+	}
+}
+
+// emitWhile consumes runes while accept is true and emits them as one token.
+func (lexer *tokenizer) emitWhile(kind string, accept func(rune) bool) {
+	start, line, column := lexer.position, lexer.line, lexer.column
+	lexer.advance(1)
+	for lexer.position < len(lexer.source) && accept(lexer.peek(0)) {
+		lexer.advance(1)
+	}
+	lexer.emit(kind, start, line, column)
+}
+
+// emitUntil consumes runes through the terminator, or to the end, and emits them.
+func (lexer *tokenizer) emitUntil(kind string, terminator string) {
+	start, line, column := lexer.position, lexer.line, lexer.column
+	lexer.advance(len(terminator))
+	for lexer.position < len(lexer.source) && !strings.HasPrefix(string(lexer.source[lexer.position:minInt(lexer.position+len(terminator), len(lexer.source))]), terminator) {
+		lexer.advance(1)
+	}
+	lexer.advance(len(terminator))
+	lexer.emit(kind, start, line, column)
+}
+
+// emitQuoted consumes a quoted literal honoring escapes. Unterminated literals end at the line break.
+func (lexer *tokenizer) emitQuoted(kind string, quote rune) {
+	start, line, column := lexer.position, lexer.line, lexer.column
+	lexer.advance(1)
+	for lexer.position < len(lexer.source) {
+		current := lexer.peek(0)
+		if current == '\\' {
+			lexer.advance(2)
+			continue
+		}
+		if current == '\n' {
+			break
+		}
+		lexer.advance(1)
+		if current == quote {
+			break
+		}
+	}
+	lexer.emit(kind, start, line, column)
+}
+
+// emitPreprocessor consumes a preprocessor directive including escaped line breaks.
+func (lexer *tokenizer) emitPreprocessor() {
+	start, line, column := lexer.position, lexer.line, lexer.column
+	for lexer.position < len(lexer.source) {
+		current := lexer.peek(0)
+		if current == '\\' && lexer.peek(1) == '\n' {
+			lexer.advance(2)
+			continue
+		}
+		if current == '\n' || (current == '/' && (lexer.peek(1) == '/' || lexer.peek(1) == '*')) {
+			break
+		}
+		lexer.advance(1)
+	}
+	lexer.emit(TokenPreprocessor, start, line, column)
+}
+
+// emitIdentifier consumes an identifier and emits it as keyword or identifier.
+func (lexer *tokenizer) emitIdentifier() {
+	start, line, column := lexer.position, lexer.line, lexer.column
+	for lexer.position < len(lexer.source) && isIdentifierPart(lexer.peek(0)) {
+		lexer.advance(1)
+	}
+
+	kind := TokenIdentifier
+	if keywords[lexer.language][string(lexer.source[start:lexer.position])] {
+		kind = TokenKeyword
+	}
+	lexer.emit(kind, start, line, column)
+}
+
+// emitOperator consumes the longest known operator or a single rune.
+func (lexer *tokenizer) emitOperator() {
+	start, line, column := lexer.position, lexer.line, lexer.column
+	length := 1
+	for _, operator := range multiCharOperators {
+		end := minInt(lexer.position+len(operator), len(lexer.source))
+		if string(lexer.source[lexer.position:end]) == operator {
+			length = len(operator)
+			break
+		}
+	}
+	lexer.advance(length)
+	lexer.emit(TokenOperator, start, line, column)
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withoutWhitespace drops whitespace tokens to keep expectations short.
+func withoutWhitespace(tokens []TokenModel) (filtered []TokenModel) {
+	for _, token := range tokens {
+		if token.Kind != TokenWhitespace {
+			filtered = append(filtered, token)
+		}
+	}
+
+	return filtered
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		language string
+		want     []TokenModel
+	}{
+		{
+			name:     "Cpp_preprocessor_and_comment",
+			source:   "#include <vector> // list\nint x = 0x1F;",
+			language: LanguageCpp,
+			want: []TokenModel{
+				{Line: 1, Column: 1, Text: "#include <vector> ", Kind: TokenPreprocessor},
+				{Line: 1, Column: 19, Text: "// list", Kind: TokenComment},
+				{Line: 2, Column: 1, Text: "int", Kind: TokenKeyword},
+				{Line: 2, Column: 5, Text: "x", Kind: TokenIdentifier},
+				{Line: 2, Column: 7, Text: "=", Kind: TokenOperator},
+				{Line: 2, Column: 9, Text: "0x1F", Kind: TokenNumber},
+				{Line: 2, Column: 13, Text: ";", Kind: TokenOperator},
+			},
+		},
+		{
+			name:     "Block_comment_split_per_line",
+			source:   "a /* one\ntwo */ b",
+			language: LanguageCpp,
+			want: []TokenModel{
+				{Line: 1, Column: 1, Text: "a", Kind: TokenIdentifier},
+				{Line: 1, Column: 3, Text: "/* one", Kind: TokenComment},
+				{Line: 2, Column: 1, Text: "two */", Kind: TokenComment},
+				{Line: 2, Column: 8, Text: "b", Kind: TokenIdentifier},
+			},
+		},
+		{
+			name:     "Strings_with_escapes",
+			source:   `s = "a\"b"; c = '\'';`,
+			language: LanguageCpp,
+			want: []TokenModel{
+				{Line: 1, Column: 1, Text: "s", Kind: TokenIdentifier},
+				{Line: 1, Column: 3, Text: "=", Kind: TokenOperator},
+				{Line: 1, Column: 5, Text: `"a\"b"`, Kind: TokenString},
+				{Line: 1, Column: 11, Text: ";", Kind: TokenOperator},
+				{Line: 1, Column: 13, Text: "c", Kind: TokenIdentifier},
+				{Line: 1, Column: 15, Text: "=", Kind: TokenOperator},
+				{Line: 1, Column: 17, Text: `'\''`, Kind: TokenChar},
+				{Line: 1, Column: 21, Text: ";", Kind: TokenOperator},
+			},
+		},
+		{
+			name:     "Java_annotation_and_scope",
+			source:   "@Override\nvoid run() { Foo::bar(); }",
+			language: LanguageJava,
+			want: []TokenModel{
+				{Line: 1, Column: 1, Text: "@Override", Kind: TokenAnnotation},
+				{Line: 2, Column: 1, Text: "void", Kind: TokenKeyword},
+				{Line: 2, Column: 6, Text: "run", Kind: TokenIdentifier},
+				{Line: 2, Column: 9, Text: "(", Kind: TokenOperator},
+				{Line: 2, Column: 10, Text: ")", Kind: TokenOperator},
+				{Line: 2, Column: 12, Text: "{", Kind: TokenOperator},
+				{Line: 2, Column: 14, Text: "Foo", Kind: TokenIdentifier},
+				{Line: 2, Column: 17, Text: "::", Kind: TokenOperator},
+				{Line: 2, Column: 19, Text: "bar", Kind: TokenIdentifier},
+				{Line: 2, Column: 22, Text: "(", Kind: TokenOperator},
+				{Line: 2, Column: 23, Text: ")", Kind: TokenOperator},
+				{Line: 2, Column: 24, Text: ";", Kind: TokenOperator},
+				{Line: 2, Column: 26, Text: "}", Kind: TokenOperator},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withoutWhitespace(Tokenize(tt.source, tt.language)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateTokens(t *testing.T) {
+	source := "int count = 0;\nclass Counter {\nint add(int count) {\nreturn count + step;\n}\n};"
+	file := FileModel{
+		FileName:  "repo/counter.cpp",
+		Variables: []VariableModel{{Name: "count", Type: "int"}},
+		Classes: []ClassModel{{
+			Name: "Counter",
+			AccessSpecifierModels: []AccessSpecifierModel{{
+				Name: "private",
+				Functions: []FunctionModel{{
+					Name:       "int add(int count)",
+					Parameters: []ParameterModel{{Name: "count", Type: "int"}},
+					StartLine:  3,
+					EndLine:    5,
+				}},
+			}},
+		}},
+	}
+
+	tokens := Tokenize(source, LanguageCpp)
+	AnnotateTokens(tokens, file)
+
+	refs := make(map[int]*SymbolModel)
+	for _, token := range tokens {
+		if token.Text == "count" || token.Text == "add" || token.Text == "Counter" || token.Text == "step" {
+			refs[token.Line*100+token.Column] = token.Ref
+		}
+	}
+
+	if ref := refs[1*100+5]; ref == nil || ref.Local || ref.Kind != SymbolVariable {
+		t.Errorf("Global count reference = %+v, want file variable", ref)
+	}
+	if ref := refs[4*100+8]; ref == nil || !ref.Local || ref.QualifiedName != "Counter::add::count" {
+		t.Errorf("Parameter count reference = %+v, want Counter::add::count", ref)
+	}
+	if ref := refs[3*100+5]; ref == nil || ref.Kind != SymbolFunction || ref.QualifiedName != "Counter::add" {
+		t.Errorf("Function add reference = %+v, want Counter::add", ref)
+	}
+	if ref := refs[2*100+7]; ref == nil || ref.Kind != SymbolClass {
+		t.Errorf("Class Counter reference = %+v, want class", ref)
+	}
+	if ref := refs[4*100+16]; ref != nil {
+		t.Errorf("Undeclared step reference = %+v, want none", ref)
+	}
+}
+
+func TestHighlightHTML(t *testing.T) {
+	tokens := Tokenize("if (a < b)\n  x = \"<tag>\";", LanguageCpp)
+	tokens[3].Ref = &SymbolModel{Kind: SymbolVariable, QualifiedName: "a", FileName: "repo/a.cpp"}
+
+	want := `<span class="tok-keyword">if</span> <span class="tok-operator">(</span>` +
+		`<span class="tok-identifier" data-kind="variable" data-name="a" data-file="repo/a.cpp">a</span> ` +
+		`<span class="tok-operator">&lt;</span> <span class="tok-identifier">b</span><span class="tok-operator">)</span>` +
+		"\n  " + `<span class="tok-identifier">x</span> <span class="tok-operator">=</span> ` +
+		`<span class="tok-string">&#34;&lt;tag&gt;&#34;</span><span class="tok-operator">;</span>`
+
+	if got := HighlightHTML(tokens, 1, 2); got != want {
+		t.Errorf("HighlightHTML() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,363 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Kinds of tokens users authenticate with.
+const (
+	TokenAPI     = "api"     // Long lived token for scripts, sent as "Authorization: Bearer <token>"
+	TokenSession = "session" // Token of a login, sent as cookie
+)
+
+// SessionDuration is how long a login lasts.
+const SessionDuration = 7 * 24 * time.Hour
+
+// Password hashing parameters.
+const (
+	passwordIterations = 100000
+	passwordSaltSize   = 16
+	passwordKeySize    = 32
+	passwordScheme     = "pbkdf2-sha256"
+)
+
+// tokenPrefix starts every token secret, to recognize them in configuration and logs.
+const tokenPrefix = "cv_"
+
+// Errors of user accounts and tokens.
+var (
+	ErrInvalidUserName    = errors.New("User name must be 3 to 64 letters, digits, '.', '_' or '-'")
+	ErrInvalidPassword    = errors.New("Password must be at least 8 characters")
+	ErrUserExists         = errors.New("User already exists")
+	ErrInvalidCredentials = errors.New("Invalid credentials")
+	ErrTokenNotFound      = errors.New("Token not found")
+)
+
+// userNamePattern matches valid user names.
+var userNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{3,64}$`)
+
+// UserModel represents an account of the api server.
+type UserModel struct {
+	ID           bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	Name         string        `json:"name" bson:"name"`                         // Unique name the user logs in with
+	PasswordHash string        `json:"-" bson:"password_hash"`                   // Salted hash of the password, empty for users of a provider
+	Created      time.Time     `json:"created" bson:"created"`                   // When the account was created
+	Issuer       string        `json:"issuer,omitempty" bson:"issuer,omitempty"` // OpenID Connect provider the user logs in with
+	Subject      string        `json:"-" bson:"subject,omitempty"`               // Id of the user at the provider
+	Email        string        `json:"email,omitempty" bson:"email,omitempty"`   // Email given by the provider
+}
+
+// AccessTokenModel represents a token a user authenticates with. Only a hash of the secret is stored.
+type AccessTokenModel struct {
+	ID      bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	UserID  bson.ObjectId `json:"-" bson:"user_id"`
+	Kind    string        `json:"kind" bson:"kind"`                           // TokenAPI or TokenSession
+	Name    string        `json:"name" bson:"name"`                           // Name given by the user to recognize the token
+	Hash    string        `json:"-" bson:"hash"`                              // Hash of the secret
+	Created time.Time     `json:"created" bson:"created"`                     // When the token was created
+	Expires time.Time     `json:"expires,omitempty" bson:"expires,omitempty"` // When the token stops working, zero for never
+}
+
+// NewUser creates an account with name and password.
+func NewUser(name string, password string) (UserModel, error) {
+	util.TypeLogger.Debug("%s: Call to NewUser", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to NewUser", packageName)
+
+	if !userNamePattern.MatchString(name) {
+		return UserModel{}, ErrInvalidUserName
+	}
+	if len(password) < 8 {
+		return UserModel{}, ErrInvalidPassword
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return UserModel{}, err
+	}
+
+	user := UserModel{Name: name, PasswordHash: hash, Created: time.Now().UTC()}
+	if err := DB.AddUser(&user); err != nil {
+		return UserModel{}, err
+	}
+
+	return user, nil
+}
+
+// AuthenticateUser returns the user with name if password matches.
+func AuthenticateUser(name string, password string) (UserModel, error) {
+	util.TypeLogger.Debug("%s: Call to AuthenticateUser", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to AuthenticateUser", packageName)
+
+	user, err := DB.FindUserByName(name)
+	if err != nil {
+		return UserModel{}, err
+	}
+
+	if !user.ID.Valid() || !checkPassword(user.PasswordHash, password) {
+		return UserModel{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// maxNameAttempts is how many names are tried for a new user of a provider before giving up.
+const maxNameAttempts = 10
+
+// UserForSubject returns the user logging in as subject at the OpenID Connect provider issuer,
+// creating an account named after name, or the email, the first time.
+func UserForSubject(issuer string, subject string, name string, email string) (UserModel, error) {
+	util.TypeLogger.Debug("%s: Call to UserForSubject", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to UserForSubject", packageName)
+
+	user, err := DB.FindUserBySubject(issuer, subject)
+	if err != nil {
+		return UserModel{}, err
+	}
+	if user.ID.Valid() {
+		if len(email) > 0 && user.Email != email {
+			user.Email = email
+			if err := DB.UpdateUserEmail(&user); err != nil {
+				return UserModel{}, err
+			}
+		}
+		return user, nil
+	}
+
+	base := userNameFrom(name, email)
+	user = UserModel{Issuer: issuer, Subject: subject, Email: email, Created: time.Now().UTC()}
+
+	// Names of local users and other providers may be taken, so a number is added until one is free
+	for attempt := 1; attempt <= maxNameAttempts; attempt++ {
+		user.Name = base
+		if attempt > 1 {
+			user.Name = base + "-" + strconv.Itoa(attempt)
+		}
+
+		err = DB.AddUser(&user)
+		if err != ErrUserExists {
+			break
+		}
+	}
+	if err != nil {
+		return UserModel{}, err
+	}
+
+	util.TypeLogger.Info("%s: Created user %s for provider %s", packageName, user.Name, issuer)
+
+	return user, nil
+}
+
+// userNameFrom makes a valid user name of the preferred name of a user, or else the local part of the email.
+func userNameFrom(name string, email string) string {
+	if len(name) == 0 {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+	if len(name) == 0 {
+		name = "user"
+	}
+
+	valid := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	if len(valid) > 60 {
+		valid = valid[:60]
+	}
+	for len(valid) < 3 {
+		valid += "_"
+	}
+
+	return valid
+}
+
+// GetUserByID finds the user with id.
+func GetUserByID(id bson.ObjectId) (UserModel, error) {
+	return DB.FindUserByID(id)
+}
+
+// GetUserByName finds the user with name, returning ErrInvalidCredentials if there is none.
+func GetUserByName(name string) (UserModel, error) {
+	user, err := DB.FindUserByName(name)
+	if err == nil && !user.ID.Valid() {
+		err = ErrInvalidCredentials
+	}
+
+	return user, err
+}
+
+// NewToken creates a token of kind for user, lasting ttl or forever if ttl is zero.
+// The secret is only returned here, the database keeps its hash.
+func (user UserModel) NewToken(kind string, name string, ttl time.Duration) (secret string, token AccessTokenModel, err error) {
+	util.TypeLogger.Debug("%s: Call to NewToken", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to NewToken", packageName)
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", AccessTokenModel{}, err
+	}
+	secret = tokenPrefix + hex.EncodeToString(random)
+
+	token = AccessTokenModel{
+		UserID:  user.ID,
+		Kind:    kind,
+		Name:    name,
+		Hash:    hashToken(secret),
+		Created: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		token.Expires = token.Created.Add(ttl)
+	}
+
+	if err := DB.AddToken(&token); err != nil {
+		return "", AccessTokenModel{}, err
+	}
+
+	return secret, token, nil
+}
+
+// Tokens lists the api tokens of user.
+func (user UserModel) Tokens() ([]AccessTokenModel, error) {
+	return DB.FindTokensByUser(user.ID, TokenAPI)
+}
+
+// RevokeToken deletes the token of user with id.
+func (user UserModel) RevokeToken(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return ErrTokenNotFound
+	}
+
+	return DB.DeleteToken(bson.ObjectIdHex(id), user.ID)
+}
+
+// UserByToken returns the user a token secret belongs to, and the token.
+// Unknown and expired tokens give ErrInvalidCredentials.
+func UserByToken(secret string) (UserModel, AccessTokenModel, error) {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return UserModel{}, AccessTokenModel{}, ErrInvalidCredentials
+	}
+
+	token, err := DB.FindTokenByHash(hashToken(secret))
+	if err != nil {
+		return UserModel{}, AccessTokenModel{}, err
+	}
+	if !token.ID.Valid() || (!token.Expires.IsZero() && time.Now().After(token.Expires)) {
+		return UserModel{}, AccessTokenModel{}, ErrInvalidCredentials
+	}
+
+	user, err := DB.FindUserByID(token.UserID)
+	if err != nil {
+		return UserModel{}, AccessTokenModel{}, err
+	}
+	if !user.ID.Valid() {
+		return UserModel{}, AccessTokenModel{}, ErrInvalidCredentials
+	}
+
+	return user, token, nil
+}
+
+// RevokeSecret deletes the token with secret, like at logout.
+func RevokeSecret(secret string) error {
+	token, err := DB.FindTokenByHash(hashToken(secret))
+	if err != nil || !token.ID.Valid() {
+		return err
+	}
+
+	return DB.DeleteToken(token.ID, token.UserID)
+}
+
+// hashToken hashes a token secret. Secrets are random, so a plain hash is enough.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashPassword hashes password with a random salt as "pbkdf2-sha256$<iterations>$<salt>$<key>".
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := pbkdf2([]byte(password), salt, passwordIterations, passwordKeySize)
+
+	return strings.Join([]string{
+		passwordScheme,
+		strconv.Itoa(passwordIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// checkPassword checks password against a hash from hashPassword.
+func checkPassword(hash string, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(pbkdf2([]byte(password), salt, iterations, len(key)), key) == 1
+}
+
+// pbkdf2 derives a key of keySize bytes from password and salt with PBKDF2 and HMAC-SHA256, RFC 8018.
+func pbkdf2(password []byte, salt []byte, iterations int, keySize int) []byte {
+	prf := hmac.New(sha256.New, password)
+	blocks := (keySize + prf.Size() - 1) / prf.Size()
+
+	key := make([]byte, 0, blocks*prf.Size())
+	counter := make([]byte, 4)
+
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+
+		for iteration := 1; iteration < iterations; iteration++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for index := range t {
+				t[index] ^= u[index]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keySize]
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPbkdf2(t *testing.T) {
+	// Test vectors of PBKDF2-HMAC-SHA256 from RFC 7914 section 11.
+	tests := []struct {
+		name       string
+		password   string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{
+			name:       "One iteration",
+			password:   "passwd",
+			salt:       "salt",
+			iterations: 1,
+			want:       "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783",
+		},
+		{
+			name:       "Many iterations",
+			password:   "Password",
+			salt:       "NaCl",
+			iterations: 80000,
+			want:       "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hex.EncodeToString(pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iterations, len(tt.want)/2))
+			if got != tt.want {
+				t.Errorf("pbkdf2() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("hashPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{name: "Correct password", hash: hash, password: "correct horse", want: true},
+		{name: "Wrong password", hash: hash, password: "correct horse!", want: false},
+		{name: "Unknown scheme", hash: "md5$1$c2FsdA$c2FsdA", password: "correct horse", want: false},
+		{name: "Empty hash", hash: "", password: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("checkPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserNameFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		preferred string
+		email     string
+		want      string
+	}{
+		{name: "Preferred name", preferred: "alice", email: "alice.smith@example.com", want: "alice"},
+		{name: "Email", email: "alice.smith@example.com", want: "alice.smith"},
+		{name: "Invalid characters", preferred: "Alice Smith", want: "Alice_Smith"},
+		{name: "Short", preferred: "al", want: "al_"},
+		{name: "Nothing", want: "user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := userNameFrom(tt.preferred, tt.email)
+			if got != tt.want {
+				t.Errorf("userNameFrom() = %s, want %s", got, tt.want)
+			}
+			if !userNamePattern.MatchString(got) {
+				t.Errorf("userNameFrom() = %s is not a valid user name", got)
+			}
+		})
+	}
+}
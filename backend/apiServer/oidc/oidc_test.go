@@ -0,0 +1,398 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "codevis"
+	testClientSecret = "s3cret"
+	testRedirectURL  = "http://localhost:8080/auth/oidc/callback"
+)
+
+// testIdP is a local stand-in for an OpenID Connect provider.
+type testIdP struct {
+	*httptest.Server
+
+	mutex  sync.Mutex
+	keyID  string
+	key    *rsa.PrivateKey
+	codes  map[string]testGrant
+	claims map[string]interface{} // Overrides claims of issued id tokens
+}
+
+// testGrant is an authorization code issued by testIdP.
+type testGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Could not generate key: %v", err)
+	}
+
+	idp := &testIdP{keyID: "key-1", key: key, codes: map[string]testGrant{}, claims: map[string]interface{}{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                   idp.URL,
+			AuthorizationEndpoint:    idp.URL + "/authorize",
+			TokenEndpoint:            idp.URL + "/token",
+			JWKSURI:                  idp.URL + "/jwks",
+			CodeChallengeMethods:     []string{"S256"},
+			IDTokenSigningAlgs:       []string{"RS256"},
+			TokenEndpointAuthMethods: []string{"client_secret_basic"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jsonWebKey{{
+				KeyType: "RSA",
+				KeyID:   idp.keyID,
+				Use:     "sig",
+				N:       base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+
+	return idp
+}
+
+// authorize logs the user in without asking and redirects back with a code.
+func (idp *testIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != testClientID || query.Get("redirect_uri") != testRedirectURL ||
+		query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" ||
+		!strings.Contains(query.Get("scope"), "openid") {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	code, _ := randomString()
+
+	idp.mutex.Lock()
+	idp.codes[code] = testGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.mutex.Unlock()
+
+	http.Redirect(w, r, testRedirectURL+"?"+url.Values{"code": {code}, "state": {query.Get("state")}}.Encode(), http.StatusFound)
+}
+
+// token redeems a code, checking the client and the PKCE verifier.
+func (idp *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	idp.mutex.Lock()
+	grant, ok := idp.codes[r.PostFormValue("code")]
+	delete(idp.codes, r.PostFormValue("code"))
+	idp.mutex.Unlock()
+
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != testRedirectURL ||
+		CodeChallenge(r.PostFormValue("code_verifier")) != grant.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "unused",
+		"token_type":   "Bearer",
+		"id_token":     idp.sign(map[string]interface{}{"nonce": grant.nonce}),
+	})
+}
+
+// sign issues an id token for alice with claims, overridden by idp.claims.
+func (idp *testIdP) sign(claims map[string]interface{}) string {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	payload := map[string]interface{}{
+		"iss":                idp.URL,
+		"sub":                "248289761001",
+		"aud":                testClientID,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"email":              "alice@example.com",
+		"preferred_username": "alice",
+	}
+	for name, value := range claims {
+		payload[name] = value
+	}
+	for name, value := range idp.claims {
+		payload[name] = value
+	}
+
+	return signToken(map[string]string{"alg": "RS256", "kid": idp.keyID}, payload, func(digest []byte) []byte {
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest)
+		return signature
+	})
+}
+
+// signToken encodes a token with the signature of sign over the SHA-256 digest of header and payload.
+func signToken(header interface{}, payload interface{}, sign func(digest []byte) []byte) string {
+	encode := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(digest[:]))
+}
+
+// discover sets up a provider for idp.
+func discover(t *testing.T, idp *testIdP) *Provider {
+	provider, err := Discover(context.Background(), Config{
+		Issuer:       idp.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"email", "profile"},
+	}, idp.Client())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	return provider
+}
+
+// login follows the authorization code flow at idp for request, returning the code and state of the callback.
+func login(t *testing.T, idp *testIdP, provider *Provider, request AuthRequest) (code string, state string) {
+	client := idp.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	resp, err := client.Get(provider.AuthCodeURL(request))
+	if err != nil {
+		t.Fatalf("Authorization request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	callback, err := resp.Location()
+	if err != nil {
+		t.Fatalf("Authorization request was not redirected: %s", resp.Status)
+	}
+
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func TestLogin(t *testing.T) {
+	idp := newTestIdP(t)
+	provider := discover(t, idp)
+
+	request, err := NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest() error = %v", err)
+	}
+
+	code, state := login(t, idp, provider, request)
+	if state != request.State {
+		t.Fatalf("Callback state = %s, want %s", state, request.State)
+	}
+
+	claims, err := provider.Exchange(context.Background(), code, request)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if claims.Subject != "248289761001" || claims.Email != "alice@example.com" || claims.PreferredUsername != "alice" {
+		t.Errorf("Exchange() = %+v", claims)
+	}
+
+	// Codes can only be redeemed once
+	if _, err := provider.Exchange(context.Background(), code, request); err == nil {
+		t.Errorf("Exchange() accepted a used code")
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		tamper  func(request *AuthRequest)
+		wantErr string
+	}{
+		{
+			name:    "Wrong PKCE verifier",
+			tamper:  func(request *AuthRequest) { request.Verifier += "x" },
+			wantErr: "invalid_grant",
+		},
+		{
+			name:    "Wrong nonce",
+			tamper:  func(request *AuthRequest) { request.Nonce += "x" },
+			wantErr: "nonce does not match",
+		},
+		{
+			name:    "Other audience",
+			claims:  map[string]interface{}{"aud": "other"},
+			wantErr: "not issued for this client",
+		},
+		{
+			name:    "Several audiences without authorized party",
+			claims:  map[string]interface{}{"aud": []string{testClientID, "other"}},
+			wantErr: "not authorized for this client",
+		},
+		{
+			name:    "Other issuer",
+			claims:  map[string]interface{}{"iss": "https://evil.example.com"},
+			wantErr: "issued by",
+		},
+		{
+			name:    "Expired",
+			claims:  map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()},
+			wantErr: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			idp.claims = tt.claims
+			provider := discover(t, idp)
+
+			request, _ := NewAuthRequest()
+			code, _ := login(t, idp, provider, request)
+			if tt.tamper != nil {
+				tt.tamper(&request)
+			}
+
+			_, err := provider.Exchange(context.Background(), code, request)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Exchange() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	idp := newTestIdP(t)
+	provider := discover(t, idp)
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	valid := idp.sign(map[string]interface{}{"nonce": "n"})
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{
+			name:  "Valid",
+			token: valid,
+		},
+		{
+			name:    "Unsigned",
+			token:   base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".",
+			wantErr: "unsupported algorithm",
+		},
+		{
+			name:    "Symmetric algorithm",
+			token:   base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"key-1"}`)) + "." + parts[1] + "." + parts[2],
+			wantErr: "unsupported algorithm",
+		},
+		{
+			name:    "Modified claims",
+			token:   parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2],
+			wantErr: "bad signature",
+		},
+		{
+			name: "Unknown key",
+			token: signToken(map[string]string{"alg": "ES256", "kid": "key-2"}, map[string]string{"sub": "x"}, func(digest []byte) []byte {
+				r, s, _ := ecdsa.Sign(rand.Reader, ecKey, digest)
+				return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+			}),
+			wantErr: "unknown key",
+		},
+		{
+			name:    "Malformed",
+			token:   "not.a-token",
+			wantErr: "malformed token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Verify(context.Background(), tt.token, "n")
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Verify() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := newTestIdP(t)
+	provider := discover(t, idp)
+
+	if _, err := provider.Verify(context.Background(), idp.sign(map[string]interface{}{"nonce": "n"}), "n"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp.mutex.Lock()
+	idp.keyID, idp.key = "key-2", key
+	idp.mutex.Unlock()
+
+	// The new key is only fetched once the refresh interval has passed
+	rotated := idp.sign(map[string]interface{}{"nonce": "n"})
+	if _, err := provider.Verify(context.Background(), rotated, "n"); err == nil {
+		t.Fatalf("Verify() accepted a token before fetching its key")
+	}
+
+	provider.keysFetched = time.Time{}
+	if _, err := provider.Verify(context.Background(), rotated, "n"); err != nil {
+		t.Errorf("Verify() error after rotation = %v", err)
+	}
+}
+
+func TestDiscoverIssuerMismatch(t *testing.T) {
+	idp := newTestIdP(t)
+
+	// Metadata is found, but names the issuer without the trailing slash
+	_, err := Discover(context.Background(), Config{Issuer: idp.URL + "/", ClientID: testClientID}, idp.Client())
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Discover() accepted metadata of another issuer")
+	}
+}
@@ -0,0 +1,253 @@
+// Package oidc logs users in with an OpenID Connect provider using the authorization code flow with PKCE
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+var packageName = "oidc"
+
+// discoveryPath is where providers publish their metadata, relative to the issuer.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// maxResponseSize limits the size of responses read from the provider.
+const maxResponseSize = 1 << 20
+
+// Errors of the login flow.
+var (
+	ErrDiscovery = errors.New("Invalid provider metadata")
+	ErrExchange  = errors.New("Failed to exchange authorization code")
+)
+
+// Config identifies the api server as a client of a provider.
+type Config struct {
+	Issuer       string   // Url of the provider, metadata is discovered from it
+	ClientID     string   // Id of the api server at the provider
+	ClientSecret string   // Secret of the api server at the provider, empty for public clients
+	RedirectURL  string   // Callback the provider sends users back to, as registered at the provider
+	Scopes       []string // Scopes besides "openid" to request
+}
+
+// Metadata is the part of the provider metadata used by the login flow.
+type Metadata struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	JWKSURI                  string   `json:"jwks_uri"`
+	CodeChallengeMethods     []string `json:"code_challenge_methods_supported"`
+	IDTokenSigningAlgs       []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Provider is an OpenID Connect provider discovered from its issuer.
+type Provider struct {
+	config   Config
+	metadata Metadata
+	client   *http.Client
+
+	mutex       sync.Mutex
+	keys        map[string]interface{} // Public keys of the provider by key id
+	keysFetched time.Time              // When the keys were last fetched
+}
+
+// AuthRequest holds the values of one login that must be kept until the provider redirects back.
+type AuthRequest struct {
+	State    string `json:"state"`    // Ties the callback to the login, against cross site request forgery
+	Nonce    string `json:"nonce"`    // Ties the id token to the login, against replay
+	Verifier string `json:"verifier"` // PKCE code verifier, its challenge is sent with the login
+}
+
+// Discover fetches the metadata of the provider at config.Issuer.
+func Discover(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	util.TypeLogger.Debug("%s: Call to Discover", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to Discover", packageName)
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	provider := &Provider{config: config, client: client, keys: map[string]interface{}{}}
+
+	if err := provider.getJSON(ctx, strings.TrimSuffix(config.Issuer, "/")+discoveryPath, &provider.metadata); err != nil {
+		return nil, err
+	}
+
+	metadata := provider.metadata
+	if metadata.Issuer != config.Issuer {
+		return nil, fmt.Errorf("%v: issuer %s does not match %s", ErrDiscovery, metadata.Issuer, config.Issuer)
+	}
+	if len(metadata.AuthorizationEndpoint) == 0 || len(metadata.TokenEndpoint) == 0 || len(metadata.JWKSURI) == 0 {
+		return nil, fmt.Errorf("%v: missing endpoints", ErrDiscovery)
+	}
+	if len(metadata.CodeChallengeMethods) > 0 && !contains(metadata.CodeChallengeMethods, "S256") {
+		return nil, fmt.Errorf("%v: PKCE with S256 is not supported", ErrDiscovery)
+	}
+
+	util.TypeLogger.Info("%s: Discovered provider %s", packageName, metadata.Issuer)
+
+	return provider, nil
+}
+
+// Issuer returns the verified issuer of the provider.
+func (provider *Provider) Issuer() string {
+	return provider.metadata.Issuer
+}
+
+// NewAuthRequest creates the random values of a login.
+func NewAuthRequest() (request AuthRequest, err error) {
+	if request.State, err = randomString(); err != nil {
+		return AuthRequest{}, err
+	}
+	if request.Nonce, err = randomString(); err != nil {
+		return AuthRequest{}, err
+	}
+	if request.Verifier, err = randomString(); err != nil {
+		return AuthRequest{}, err
+	}
+
+	return request, nil
+}
+
+// AuthCodeURL returns where to send the user to log in at the provider.
+func (provider *Provider) AuthCodeURL(request AuthRequest) string {
+	values := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.config.ClientID},
+		"redirect_uri":          {provider.config.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, provider.config.Scopes...), " ")},
+		"state":                 {request.State},
+		"nonce":                 {request.Nonce},
+		"code_challenge":        {CodeChallenge(request.Verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(provider.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return provider.metadata.AuthorizationEndpoint + separator + values.Encode()
+}
+
+// Exchange redeems the authorization code of the callback of request, returning the verified claims of the id token.
+func (provider *Provider) Exchange(ctx context.Context, code string, request AuthRequest) (Claims, error) {
+	util.TypeLogger.Debug("%s: Call to Exchange", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to Exchange", packageName)
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {provider.config.RedirectURL},
+		"code_verifier": {request.Verifier},
+		"client_id":     {provider.config.ClientID},
+	}
+
+	// Confidential clients authenticate with basic auth unless the provider only accepts the secret in the form
+	useBasic := len(provider.config.ClientSecret) > 0
+	if useBasic && len(provider.metadata.TokenEndpointAuthMethods) > 0 && !contains(provider.metadata.TokenEndpointAuthMethods, "client_secret_basic") {
+		form.Set("client_secret", provider.config.ClientSecret)
+		useBasic = false
+	}
+
+	req, err := http.NewRequest("POST", provider.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(provider.config.ClientID), url.QueryEscape(provider.config.ClientSecret))
+	}
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return Claims{}, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return Claims{}, fmt.Errorf("%v: %s", ErrExchange, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK || len(body.Error) > 0 {
+		return Claims{}, fmt.Errorf("%v: %s %s", ErrExchange, body.Error, body.ErrorDescription)
+	}
+	if len(body.IDToken) == 0 {
+		return Claims{}, fmt.Errorf("%v: no id token", ErrExchange)
+	}
+
+	return provider.Verify(ctx, body.IDToken, request.Nonce)
+}
+
+// CodeChallenge returns the S256 PKCE challenge of verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// getJSON decodes the json response of a GET request to endpoint into v.
+func (provider *Provider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := provider.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseSize))
+		return fmt.Errorf("%v: %s responded %s", ErrDiscovery, endpoint, resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("%v: %s", ErrDiscovery, err.Error())
+	}
+
+	return nil
+}
+
+// randomString returns 32 random bytes encoded for urls.
+func randomString() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// contains tells if list has value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
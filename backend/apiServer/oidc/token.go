@@ -0,0 +1,283 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// clockSkew is how far the clocks of the provider and the api server may differ.
+const clockSkew = time.Minute
+
+// keyRefreshInterval is how often unknown key ids may cause the key set to be fetched again.
+const keyRefreshInterval = time.Minute
+
+// ErrInvalidToken is returned for id tokens that fail verification.
+var ErrInvalidToken = errors.New("Invalid id token")
+
+// Claims are the claims of a verified id token used by the api server.
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	Expiry            int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+	Name              string   `json:"name"`
+}
+
+// audience is the "aud" claim, which is either a string or a list of strings.
+type audience []string
+
+// UnmarshalJSON reads a single audience or a list of them.
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*aud = list
+
+	return nil
+}
+
+// tokenHeader is the header of a JSON Web Token.
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// jsonWebKey is a public key in a JSON Web Key Set.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// Verify checks the signature and claims of an id token, which must carry nonce, and returns its claims.
+func (provider *Provider) Verify(ctx context.Context, rawToken string, nonce string) (Claims, error) {
+	util.TypeLogger.Debug("%s: Call to Verify", packageName)
+	defer util.TypeLogger.Debug("%s: Ended call to Verify", packageName)
+
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%v: malformed token", ErrInvalidToken)
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("%v: malformed header", ErrInvalidToken)
+	}
+	if header.Algorithm != "RS256" && header.Algorithm != "ES256" {
+		return Claims{}, fmt.Errorf("%v: unsupported algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	key, err := provider.key(ctx, header.KeyID)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%v: malformed signature", ErrInvalidToken)
+	}
+	if !verifySignature(header.Algorithm, key, parts[0]+"."+parts[1], signature) {
+		return Claims{}, fmt.Errorf("%v: bad signature", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("%v: malformed claims", ErrInvalidToken)
+	}
+
+	if err := provider.checkClaims(claims, nonce, time.Now()); err != nil {
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
+
+// checkClaims validates the claims of an id token at time now, as in OpenID Connect Core section 3.1.3.7.
+func (provider *Provider) checkClaims(claims Claims, nonce string, now time.Time) error {
+	if claims.Issuer != provider.metadata.Issuer {
+		return fmt.Errorf("%v: issued by %s", ErrInvalidToken, claims.Issuer)
+	}
+	if len(claims.Subject) == 0 {
+		return fmt.Errorf("%v: no subject", ErrInvalidToken)
+	}
+	if !contains(claims.Audience, provider.config.ClientID) {
+		return fmt.Errorf("%v: not issued for this client", ErrInvalidToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != provider.config.ClientID {
+		return fmt.Errorf("%v: not authorized for this client", ErrInvalidToken)
+	}
+	if now.Add(-clockSkew).Unix() >= claims.Expiry {
+		return fmt.Errorf("%v: expired", ErrInvalidToken)
+	}
+	if now.Add(clockSkew).Unix() < claims.IssuedAt {
+		return fmt.Errorf("%v: issued in the future", ErrInvalidToken)
+	}
+	if claims.Nonce != nonce {
+		return fmt.Errorf("%v: nonce does not match", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+// key returns the public key of the provider with id, fetching the key set again for unknown ids
+// since providers rotate their keys.
+func (provider *Provider) key(ctx context.Context, id string) (interface{}, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	if key, ok := provider.keys[id]; ok {
+		return key, nil
+	}
+	if time.Since(provider.keysFetched) < keyRefreshInterval {
+		return nil, fmt.Errorf("%v: unknown key %q", ErrInvalidToken, id)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := provider.getJSON(ctx, provider.metadata.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			util.TypeLogger.Warn("%s: Skipping key %s of provider: %s", packageName, jwk.KeyID, err.Error())
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	provider.keys = keys
+	provider.keysFetched = time.Now()
+
+	// Tokens without a key id are accepted when the provider has a single key
+	if len(id) == 0 && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+
+	key, ok := keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%v: unknown key %q", ErrInvalidToken, id)
+	}
+
+	return key, nil
+}
+
+// publicKey decodes an RSA or P-256 key.
+func (jwk jsonWebKey) publicKey() (interface{}, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Curve)
+		}
+		x, err := decodeInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", jwk.KeyType)
+}
+
+// verifySignature checks signature of signed with key using algorithm.
+func verifySignature(algorithm string, key interface{}, signed string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(signed))
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return algorithm == "RS256" && rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+
+	case *ecdsa.PublicKey:
+		if algorithm != "ES256" || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(key, digest[:], r, s)
+	}
+
+	return false
+}
+
+// decodeSegment decodes a base64url encoded json segment of a token into v.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// decodeInt decodes a base64url encoded big endian integer.
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid integer")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
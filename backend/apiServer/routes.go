@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/controller"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/util"
+)
+
+// newRouter routes the api to controllers set up by cfg, with logins through the OpenID Connect provider
+// of login when it is not nil. Every route is described in controller.OpenAPIDocument.
+func newRouter(cfg config.Config, login *controller.OIDCController) *mux.Router {
+	router := mux.NewRouter()
+
+	// Limits of expensive work
+	limited := controller.RateLimit(util.NewRateLimiter(cfg.Limits.RateLimit, cfg.Limits.RateBurst))
+
+	// Controllers
+	storage := cfg.Storage
+	repoController := controller.RepoController{
+		Storage:    storage,
+		Origins:    cfg.Server.CORSOrigins,
+		CloneSlots: util.NewSlots(cfg.Limits.MaxClones, cfg.Limits.MaxQueue),
+		ParseSlots: util.NewSlots(cfg.Limits.MaxParses, cfg.Limits.MaxQueue),
+		AdminUsers: cfg.Auth.AdminUsers,
+	}
+	analysisController := controller.AnalysisController{Storage: storage}
+	codeSnippetController := controller.CodeSnippetController{Storage: storage}
+	exportController := controller.ExportController{Storage: storage}
+	membersController := controller.MembersController{Storage: storage}
+	navigationController := controller.NavigationController{Storage: storage}
+	repoConfigController := controller.RepoConfigController{Storage: storage}
+	searchController := controller.SearchController{Storage: storage}
+
+	// API routings
+	util.TypeLogger.Info("%s: Setting up api routes", packageName)
+	router.Handle("/auth/register", limited(http.HandlerFunc(controller.UserController{}.Register))).Methods("POST")
+	router.Handle("/auth/login", limited(http.HandlerFunc(controller.UserController{}.Login))).Methods("POST")
+	if login != nil {
+		router.HandleFunc("/auth/oidc/login", login.Login).Methods("GET")
+		router.HandleFunc("/auth/oidc/callback", login.Callback).Methods("GET")
+	}
+	router.Handle("/auth/logout", controller.RequireUser(http.HandlerFunc(controller.UserController{}.Logout))).Methods("POST")
+	router.Handle("/auth/me", controller.RequireUser(http.HandlerFunc(controller.UserController{}.GetMe))).Methods("GET")
+	router.Handle("/auth/tokens", controller.RequireUser(http.HandlerFunc(controller.UserController{}.HandleTokens))).Methods("GET", "POST")
+	router.Handle("/auth/tokens/{tokenId}", controller.RequireUser(http.HandlerFunc(controller.UserController{}.RevokeToken))).Methods("DELETE")
+	router.Handle("/search", controller.RequireUser(http.HandlerFunc(searchController.SearchAll))).Methods("GET")
+	router.HandleFunc("/schema", controller.SchemaController{}.GetSchema).Methods("GET")
+	router.HandleFunc("/openapi.json", controller.OpenAPIController{}.GetOpenAPI).Methods("GET")
+	router.Handle("/audit", controller.RequireUser(controller.RequireAdmin(cfg.Auth.AdminUsers)(http.HandlerFunc(controller.AuditController{}.GetAudit)))).Methods("GET")
+
+	// Repository routes require a user with a role in the repository
+	viewer := controller.RequireRole(storage, model.RoleViewer)
+	analyst := controller.RequireRole(storage, model.RoleAnalyst)
+	admin := controller.RequireRole(storage, model.RoleAdmin)
+
+	repoRouter := router.PathPrefix("/repo").Subrouter()
+	repoRouter.Use(controller.RequireUser)
+	repoRouter.Handle("/add", limited(http.HandlerFunc(repoController.NewRepoFromURI))).Methods("GET")
+	repoRouter.HandleFunc("/list", repoController.GetAllRepos).Methods("GET")
+	repoRouter.Handle("/{repoId}", admin(http.HandlerFunc(repoController.DeleteRepo))).Methods("DELETE")
+	repoRouter.Handle("/{repoId}/initial/", viewer(limited(http.HandlerFunc(repoController.ParseInitial)))).Methods("GET")
+	repoRouter.Handle("/{repoId}/reparse/", analyst(limited(http.HandlerFunc(repoController.Reparse)))).Methods("GET")
+	repoRouter.Handle("/{repoId}/config", viewer(http.HandlerFunc(repoConfigController.HandleConfig))).Methods("GET")
+	repoRouter.Handle("/{repoId}/config", admin(http.HandlerFunc(repoConfigController.HandleConfig))).Methods("PUT", "DELETE")
+	repoRouter.Handle("/{repoId}/members", viewer(http.HandlerFunc(membersController.HandleMembers))).Methods("GET")
+	repoRouter.Handle("/{repoId}/members", admin(http.HandlerFunc(membersController.HandleMembers))).Methods("PUT")
+	repoRouter.Handle("/{repoId}/file/read/", viewer(http.HandlerFunc(codeSnippetController.GetImplementation))).Methods("GET")
+	repoRouter.Handle("/{repoId}/search", viewer(http.HandlerFunc(searchController.SearchRepo))).Methods("GET")
+	repoRouter.Handle("/{repoId}/definition", viewer(http.HandlerFunc(navigationController.GetDefinition))).Methods("GET")
+	repoRouter.Handle("/{repoId}/references", viewer(http.HandlerFunc(navigationController.GetReferences))).Methods("GET")
+	repoRouter.Handle("/{repoId}/deadcode", viewer(http.HandlerFunc(analysisController.GetDeadCode))).Methods("GET")
+	repoRouter.Handle("/{repoId}/clones", viewer(limited(http.HandlerFunc(analysisController.GetClones)))).Methods("GET")
+	repoRouter.Handle("/{repoId}/violations", viewer(http.HandlerFunc(analysisController.GetViolations))).Methods("GET")
+	repoRouter.Handle("/{repoId}/sarif", analyst(limited(http.HandlerFunc(analysisController.GetSarif)))).Methods("GET")
+	repoRouter.Handle("/{repoId}/export", analyst(limited(http.HandlerFunc(exportController.GetExport)))).Methods("GET")
+
+	// Versioned REST api, responding to errors with json
+	apiRouter := router.PathPrefix(controller.APIPrefix).Subrouter()
+	apiRouter.NotFoundHandler = http.HandlerFunc(controller.NotFound)
+	apiRouter.MethodNotAllowedHandler = http.HandlerFunc(controller.MethodNotAllowed)
+	apiRouter.Use(controller.JSONErrors, controller.RequireUser)
+	apiRouter.HandleFunc("/repos", repoController.GetAllRepos).Methods("GET")
+	apiRouter.Handle("/repos", limited(http.HandlerFunc(repoController.AddRepo))).Methods("POST")
+	apiRouter.Handle("/repos/{repoId}/project", viewer(http.HandlerFunc(repoController.GetProject))).Methods("GET")
+	apiRouter.Handle("/repos/{repoId}/parse", analyst(limited(http.HandlerFunc(repoController.ParseRepo)))).Methods("POST")
+	apiRouter.HandleFunc("/jobs/{jobId}", controller.JobController{}.GetJob).Methods("GET")
+
+	return router
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/config"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/controller"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/model"
+	"github.com/zohaib194/CodebaseVisualizer3D/backend/apiServer/oidc"
+)
+
+// testRouter routes the api with logins through a provider, so every documented route exists.
+func testRouter() *mux.Router {
+	return newRouter(config.Default(), &controller.OIDCController{Provider: &oidc.Provider{}})
+}
+
+func TestNewRouter_documented(t *testing.T) {
+	routed := make(map[string]bool)
+	err := testRouter().Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if route.GetHandler() == nil { // Prefix of a subrouter
+			return nil
+		}
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			t.Errorf("%s is routed without methods", path)
+			return nil
+		}
+
+		for _, method := range methods {
+			routed[method+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk routes: %v", err)
+	}
+
+	documented := make(map[string]bool)
+	for path, operations := range controller.OpenAPIDocument().Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range routed {
+		if !documented[route] {
+			t.Errorf("%s is routed but not documented", route)
+		}
+	}
+	for route := range documented {
+		if !routed[route] {
+			t.Errorf("%s is documented but not routed", route)
+		}
+	}
+}
+
+func TestNewRouter_responses(t *testing.T) {
+	router := testRouter()
+	document := controller.OpenAPIDocument()
+	parameters := strings.NewReplacer("{repoId}", "5c62d1904122c760dafe9341", "{tokenId}", "5c8a0f6b4122c7135145a1a5", "{jobId}", "5c8a0f6b4122c7135145a1a5")
+
+	paths := make([]string, 0, len(document.Paths))
+	for path := range document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Requests without credentials or body never reach the database
+	for _, path := range paths {
+		for method, operation := range document.Paths[path] {
+			t.Run(operation.OperationID, func(t *testing.T) {
+				r := httptest.NewRequest(strings.ToUpper(method), parameters.Replace(path), nil)
+				w := httptest.NewRecorder()
+
+				router.ServeHTTP(w, r)
+
+				response, ok := operation.Responses[strconv.Itoa(w.Code)]
+				if !ok {
+					t.Fatalf("status %d is not documented: %s", w.Code, w.Body.String())
+				}
+				if len(operation.Security) > 0 && w.Code != http.StatusUnauthorized {
+					t.Errorf("status = %d without credentials, want %d", w.Code, http.StatusUnauthorized)
+				}
+
+				for contentType, media := range response.Content {
+					if !strings.HasSuffix(contentType, "json") {
+						continue
+					}
+					if got := w.Header().Get("Content-Type"); got != contentType {
+						t.Errorf("content type = %q, want %q", got, contentType)
+					}
+
+					var body interface{}
+					decoder := json.NewDecoder(w.Body)
+					decoder.UseNumber()
+					if err := decoder.Decode(&body); err != nil {
+						t.Fatalf("Failed to decode body: %v", err)
+					}
+					if err := model.ValidateResponse(media.Schema, body, document.Components.Schemas); err != nil {
+						t.Errorf("body does not follow the documented schema: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	document := controller.OpenAPIDocument()
+	schemas := document.Components.Schemas
+
+	// Every reference resolves to a schema of the document
+	var check func(where string, schema *model.JSONSchema)
+	check = func(where string, schema *model.JSONSchema) {
+		if schema == nil {
+			return
+		}
+		if len(schema.Ref) > 0 {
+			if _, ok := schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]; !ok {
+				t.Errorf("%s refers to unknown schema %s", where, schema.Ref)
+			}
+		}
+		for name, property := range schema.Properties {
+			check(where+"."+name, property)
+		}
+		check(where+"[]", schema.Items)
+	}
+
+	for name, schema := range schemas {
+		check(name, schema)
+	}
+	for path, operations := range document.Paths {
+		for method, operation := range operations {
+			where := method + " " + path
+
+			// Every parameter of the path is described
+			inPath := 0
+			for _, parameter := range operation.Parameters {
+				check(where+" "+parameter.Name, parameter.Schema)
+				if parameter.In != "path" {
+					continue
+				}
+				inPath++
+				if !strings.Contains(path, "{"+parameter.Name+"}") || !parameter.Required {
+					t.Errorf("%s has path parameter %s not in its path or not required", where, parameter.Name)
+				}
+			}
+			if inPath != strings.Count(path, "{") {
+				t.Errorf("%s describes %d path parameters, want %d", where, inPath, strings.Count(path, "{"))
+			}
+
+			if operation.RequestBody != nil {
+				for contentType, media := range operation.RequestBody.Content {
+					check(where+" "+contentType, media.Schema)
+				}
+			}
+			for status, response := range operation.Responses {
+				for contentType, media := range response.Content {
+					check(where+" "+status+" "+contentType, media.Schema)
+				}
+			}
+		}
+	}
+}
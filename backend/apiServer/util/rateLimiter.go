@@ -0,0 +1,80 @@
+package util
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is how many buckets are kept before full ones are dropped.
+const maxIdleBuckets = 10000
+
+// RateLimiter is a token bucket per client. Every request takes a token, and tokens come back at a fixed rate
+// up to the size of the bucket, so clients may burst up to the size and then continue at the rate.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	rate    float64 // Tokens added per second
+	burst   float64 // Size of a bucket
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// tokenBucket holds the tokens of one client.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per minute after a burst of burst requests.
+func NewRateLimiter(perMinute int, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token of the client with key. If there is none, it returns false and how long until there is.
+func (limiter *RateLimiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		if len(limiter.buckets) >= maxIdleBuckets {
+			limiter.prune(now)
+		}
+		bucket = &tokenBucket{tokens: limiter.burst, updated: now}
+		limiter.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(limiter.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*limiter.rate)
+	bucket.updated = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	if limiter.rate <= 0 {
+		return false, time.Hour
+	}
+
+	return false, time.Duration(math.Ceil((1-bucket.tokens)/limiter.rate)) * time.Second
+}
+
+// prune drops the buckets that have filled up again, as they are the same as new ones.
+func (limiter *RateLimiter) prune(now time.Time) {
+	for key, bucket := range limiter.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*limiter.rate >= limiter.burst {
+			delete(limiter.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2019, 3, 20, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(60, 3)
+	limiter.now = func() time.Time { return now }
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		want      bool
+		wantRetry time.Duration
+	}{
+		{name: "Burst 1", key: "alice", want: true},
+		{name: "Burst 2", key: "alice", want: true},
+		{name: "Burst 3", key: "alice", want: true},
+		{name: "Empty", key: "alice", want: false, wantRetry: time.Second},
+		{name: "Other client", key: "bob", want: true},
+		{name: "Refilled", advance: time.Second, key: "alice", want: true},
+		{name: "Empty again", key: "alice", want: false, wantRetry: time.Second},
+		{name: "Capped at burst", advance: time.Hour, key: "alice", want: true},
+		{name: "Capped at burst 2", key: "alice", want: true},
+		{name: "Capped at burst 3", key: "alice", want: true},
+		{name: "Capped at burst 4", key: "alice", want: false, wantRetry: time.Second},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+
+		allowed, retryAfter := limiter.Allow(step.key)
+		if allowed != step.want || retryAfter != step.wantRetry {
+			t.Errorf("%s: Allow() = %v, %v, want %v, %v", step.name, allowed, retryAfter, step.want, step.wantRetry)
+		}
+	}
+}
+
+func TestRateLimiterPrune(t *testing.T) {
+	now := time.Date(2019, 3, 20, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(60, 1)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("alice")
+	now = now.Add(time.Minute)
+	limiter.prune(now)
+
+	if len(limiter.buckets) != 0 {
+		t.Errorf("prune() kept %d refilled buckets", len(limiter.buckets))
+	}
+}
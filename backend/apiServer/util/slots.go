@@ -0,0 +1,83 @@
+package util
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrQueueFull is returned when a task can neither run nor wait for its turn.
+var ErrQueueFull = errors.New("Too many tasks waiting")
+
+// Slots limits how many tasks run at once. Tasks over the limit wait in a queue of limited length
+// and run in the order they came.
+type Slots struct {
+	mutex    sync.Mutex
+	free     int
+	queue    []chan struct{}
+	maxQueue int
+}
+
+// NewSlots creates slots for capacity tasks at once, with up to maxQueue tasks waiting.
+func NewSlots(capacity int, maxQueue int) *Slots {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &Slots{free: capacity, maxQueue: maxQueue}
+}
+
+// Acquire takes a slot, or a place in the queue. The returned channel is closed once the slot is taken,
+// and position is the place in the queue, 0 if the slot was taken right away.
+// Every acquired slot must be given back with Release, or Cancel if the task gives up waiting.
+func (slots *Slots) Acquire() (ready chan struct{}, position int, err error) {
+	slots.mutex.Lock()
+	defer slots.mutex.Unlock()
+
+	ready = make(chan struct{})
+
+	if slots.free > 0 && len(slots.queue) == 0 {
+		slots.free--
+		close(ready)
+		return ready, 0, nil
+	}
+
+	if len(slots.queue) >= slots.maxQueue {
+		return nil, 0, ErrQueueFull
+	}
+
+	slots.queue = append(slots.queue, ready)
+
+	return ready, len(slots.queue), nil
+}
+
+// Release gives back a slot, handing it to the first task in the queue.
+func (slots *Slots) Release() {
+	slots.mutex.Lock()
+	defer slots.mutex.Unlock()
+
+	if len(slots.queue) > 0 {
+		close(slots.queue[0])
+		slots.queue = slots.queue[1:]
+		return
+	}
+
+	slots.free++
+}
+
+// Cancel leaves the queue, giving back the slot if it was handed over meanwhile.
+func (slots *Slots) Cancel(ready chan struct{}) {
+	slots.mutex.Lock()
+
+	for index, waiting := range slots.queue {
+		if waiting == ready {
+			slots.queue = append(slots.queue[:index], slots.queue[index+1:]...)
+			slots.mutex.Unlock()
+			return
+		}
+	}
+
+	slots.mutex.Unlock()
+
+	// Not queued anymore, so the slot was taken
+	slots.Release()
+}
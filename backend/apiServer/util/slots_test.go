@@ -0,0 +1,51 @@
+package util
+
+import (
+	"testing"
+)
+
+// isClosed tells if ready is closed without waiting.
+func isClosed(ready chan struct{}) bool {
+	select {
+	case <-ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSlots(t *testing.T) {
+	slots := NewSlots(1, 2)
+
+	first, position, err := slots.Acquire()
+	if err != nil || position != 0 || !isClosed(first) {
+		t.Fatalf("Acquire() = %v, %d, %v, want a slot right away", isClosed(first), position, err)
+	}
+
+	second, position, err := slots.Acquire()
+	if err != nil || position != 1 || isClosed(second) {
+		t.Fatalf("Acquire() = %v, %d, %v, want first in queue", isClosed(second), position, err)
+	}
+
+	third, position, err := slots.Acquire()
+	if err != nil || position != 2 || isClosed(third) {
+		t.Fatalf("Acquire() = %v, %d, %v, want second in queue", isClosed(third), position, err)
+	}
+
+	if _, _, err := slots.Acquire(); err != ErrQueueFull {
+		t.Fatalf("Acquire() error = %v, want %v", err, ErrQueueFull)
+	}
+
+	// The second task gives up, so the slot goes to the third
+	slots.Cancel(second)
+	slots.Release()
+	if isClosed(second) || !isClosed(third) {
+		t.Fatalf("Release() handed the slot to the wrong task")
+	}
+
+	// Canceling after the slot was handed over gives it back
+	slots.Cancel(third)
+	if _, position, _ := slots.Acquire(); position != 0 {
+		t.Errorf("Acquire() position = %d after all slots were given back, want 0", position)
+	}
+}
@@ -3,6 +3,7 @@ package util
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path"
@@ -144,6 +145,11 @@ func SetLogFile(filepath string) bool {
 	return true
 }
 
+// SetLogOutput sends printout to w, like os.Stderr for tools writing results to stdout
+func SetLogOutput(w io.Writer) {
+	TypeLogger.logger.SetOutput(w)
+}
+
 // getCallerPosition returns the sourcecode file and lineNr where logging was requested
 func getCallerPosition() string {
 	_, file, line, ok := runtime.Caller(2)